@@ -8,7 +8,6 @@ import (
 	"github.com/paysuper/paysuper-checkout/cmd"
 	"github.com/paysuper/paysuper-checkout/internal/daemon"
 	"github.com/paysuper/paysuper-checkout/pkg/http"
-	"github.com/paysuper/paysuper-checkout/pkg/micro"
 	"github.com/spf13/cobra"
 	"sync"
 )
@@ -21,8 +20,7 @@ var (
 		SilenceErrors: true,
 		Run: func(_ *cobra.Command, _ []string) {
 			var (
-				sHttp     *http.HTTP
-				sMicro    *micro.Micro
+				d         *daemon.Daemon
 				c         func()
 				e         error
 				ctxAll    context.Context
@@ -36,27 +34,20 @@ var (
 			cmd.Slave.Executor(func(ctx context.Context) error {
 				initial, _ := entrypoint.CtxExtractInitial(ctx)
 				ctxAll, ctxCancel = context.WithCancel(ctx)
-				sHttp, c, e = daemon.BuildHTTP(ctxAll, initial, cmd.Observer)
-				if e != nil {
-					return e
-				}
-				sMicro, c, e = daemon.BuildMicro(ctxAll, initial, cmd.Observer)
-				if e != nil {
-					return e
-				}
-				return nil
+				d, c, e = daemon.BuildDaemon(ctxAll, initial, cmd.Observer)
+				return e
 			}, func(ctx context.Context) error {
 				var wg sync.WaitGroup
 				wg.Add(2)
 				go func() {
-					if err := sHttp.ListenAndServe(); err != nil {
+					if err := d.HTTP.ListenAndServe(); err != nil {
 						e = err
 						ctxCancel()
 					}
 					wg.Done()
 				}()
 				go func() {
-					if err := sMicro.ListenAndServe(); err != nil {
+					if err := d.Micro.ListenAndServe(); err != nil {
 						e = err
 						ctxCancel()
 					}
@@ -13,8 +13,10 @@ require (
 	github.com/labstack/echo/v4 v4.1.11
 	github.com/micro/go-micro v1.8.0
 	github.com/micro/go-plugins v1.2.0
+	github.com/oschwald/maxminddb-golang v1.4.0
 	github.com/paysuper/paysuper-billing-server v1.1.1-0.20200116074239-296df9d8065d
 	github.com/pkg/errors v0.8.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cobra v0.0.5
 	github.com/stretchr/testify v1.4.0
 	github.com/ttacon/libphonenumber v1.0.1
@@ -0,0 +1,9 @@
+package billingext
+
+// SetOrderAttributionRequest
+type SetOrderAttributionRequest struct {
+	OrderId    string            `json:"order_id"`
+	Parameters map[string]string `json:"parameters"`
+	Referrer   string            `json:"referrer"`
+	LandedAt   int64             `json:"landed_at"`
+}
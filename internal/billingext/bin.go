@@ -0,0 +1,27 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// BinDataRequest
+type BinDataRequest struct {
+	Bin string `json:"bin"`
+}
+
+// BinData describes bank card by its first digits
+type BinData struct {
+	Bin      string `json:"bin"`
+	Brand    string `json:"brand"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Bank     string `json:"bank"`
+	Country  string `json:"country"`
+}
+
+// BinDataResponse
+type BinDataResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *BinData                   `json:"item,omitempty"`
+}
@@ -0,0 +1,42 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// BusinessBillingAddressRequest contains billing address of the business customer buying with the vat id
+type BusinessBillingAddressRequest struct {
+	OrderId       string `json:"order_id"`
+	Country       string `json:"country"`
+	Zip           string `json:"zip"`
+	Company       string `json:"company"`
+	VatId         string `json:"vat_id"`
+	VatIdVerified bool   `json:"vat_id_verified"`
+	VatIdName     string `json:"vat_id_name"`
+	VatIdAddress  string `json:"vat_id_address"`
+	Cookie        string `json:"cookie"`
+	Ip            string `json:"ip"`
+}
+
+// TaxBreakdown contains order amounts recalculated for the billing address
+type TaxBreakdown struct {
+	Amount        float64 `json:"amount"`
+	Tax           float64 `json:"tax"`
+	TaxRate       float64 `json:"tax_rate"`
+	TotalAmount   float64 `json:"total_amount"`
+	Currency      string  `json:"currency"`
+	ReverseCharge bool    `json:"reverse_charge"`
+}
+
+// BusinessBillingAddressResult
+type BusinessBillingAddressResult struct {
+	Tax    *TaxBreakdown `json:"tax"`
+	Cookie string        `json:"cookie"`
+}
+
+// BusinessBillingAddressResponse
+type BusinessBillingAddressResponse struct {
+	Status  int32                         `json:"status"`
+	Message *grpc.ResponseErrorMessage    `json:"message,omitempty"`
+	Item    *BusinessBillingAddressResult `json:"item,omitempty"`
+}
@@ -0,0 +1,18 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+const (
+	Prefix = "internal.billingext"
+
+	// ContentType is used for all extension calls, messages are plain JSON structures
+	ContentType = "application/json"
+)
+
+// EmptyResponse
+type EmptyResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+}
@@ -0,0 +1,54 @@
+package billingext
+
+// DeviceScreen
+type DeviceScreen struct {
+	Width       int     `json:"width" validate:"min=0,max=16384"`
+	Height      int     `json:"height" validate:"min=0,max=16384"`
+	AvailWidth  int     `json:"avail_width" validate:"min=0,max=16384"`
+	AvailHeight int     `json:"avail_height" validate:"min=0,max=16384"`
+	ColorDepth  int     `json:"color_depth" validate:"min=0,max=64"`
+	PixelRatio  float64 `json:"pixel_ratio" validate:"min=0,max=16"`
+}
+
+// DeviceFingerprint contains browser properties collected by the payment form, hashes are calculated on the client side
+type DeviceFingerprint struct {
+	VisitorId           string        `json:"visitor_id" validate:"omitempty,max=64,alphanum"`
+	Screen              *DeviceScreen `json:"screen"`
+	Timezone            string        `json:"timezone" validate:"omitempty,max=64,printascii"`
+	TimezoneOffset      int           `json:"timezone_offset" validate:"min=-840,max=840"`
+	Languages           []string      `json:"languages" validate:"max=10,dive,max=35,printascii"`
+	Platform            string        `json:"platform" validate:"omitempty,max=64,printascii"`
+	HardwareConcurrency int           `json:"hardware_concurrency" validate:"min=0,max=1024"`
+	DeviceMemory        float64       `json:"device_memory" validate:"min=0,max=1024"`
+	TouchPoints         int           `json:"touch_points" validate:"min=0,max=256"`
+	PluginsCount        int           `json:"plugins_count" validate:"min=0,max=1024"`
+	CanvasHash          string        `json:"canvas_hash" validate:"omitempty,max=128,hexadecimal"`
+	WebglHash           string        `json:"webgl_hash" validate:"omitempty,max=128,hexadecimal"`
+	AudioHash           string        `json:"audio_hash" validate:"omitempty,max=128,hexadecimal"`
+	FontsHash           string        `json:"fonts_hash" validate:"omitempty,max=128,hexadecimal"`
+	WebglVendor         string        `json:"webgl_vendor" validate:"omitempty,max=255,printascii"`
+	WebglRenderer       string        `json:"webgl_renderer" validate:"omitempty,max=255,printascii"`
+	CookiesEnabled      bool          `json:"cookies_enabled"`
+	LocalStorage        bool          `json:"local_storage"`
+	DoNotTrack          bool          `json:"do_not_track"`
+	Webdriver           bool          `json:"webdriver"`
+}
+
+// DeviceServerSignals contains request properties observed by checkout
+type DeviceServerSignals struct {
+	Ip             string            `json:"ip"`
+	IpCountry      string            `json:"ip_country"`
+	ForwardedHops  int               `json:"forwarded_hops"`
+	Protocol       string            `json:"protocol"`
+	TlsVersion     string            `json:"tls_version"`
+	TlsCipherSuite string            `json:"tls_cipher_suite"`
+	Headers        map[string]string `json:"headers"`
+}
+
+// SetOrderDeviceDataRequest attaches device risk data to the order, billing uses it when the payment is created
+type SetOrderDeviceDataRequest struct {
+	OrderId     string               `json:"order_id"`
+	Device      *DeviceFingerprint   `json:"device"`
+	Server      *DeviceServerSignals `json:"server"`
+	CollectedAt int64                `json:"collected_at"`
+}
@@ -0,0 +1,11 @@
+package billingext
+
+// SetOrderIpCountryRequest contains country of the customer ip address resolved by checkout GeoIP,
+// mismatch with the billing address country is a fraud signal like VPN or proxy usage
+type SetOrderIpCountryRequest struct {
+	OrderId         string `json:"order_id"`
+	Ip              string `json:"ip"`
+	IpCountry       string `json:"ip_country"`
+	BillingCountry  string `json:"billing_country"`
+	CountryMismatch bool   `json:"country_mismatch"`
+}
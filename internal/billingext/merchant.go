@@ -0,0 +1,26 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// GetMerchantApiKeyRequest
+type GetMerchantApiKeyRequest struct {
+	KeyId string `json:"key_id"`
+}
+
+// MerchantApiKey is an api key of the merchant backend, secret is used to check api key and request signatures
+type MerchantApiKey struct {
+	KeyId      string `json:"key_id"`
+	MerchantId string `json:"merchant_id"`
+	ProjectId  string `json:"project_id"`
+	Secret     string `json:"secret"`
+	RateLimit  int32  `json:"rate_limit"`
+}
+
+// GetMerchantApiKeyResponse
+type GetMerchantApiKeyResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *MerchantApiKey            `json:"item,omitempty"`
+}
@@ -0,0 +1,52 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// OrderAmounts contains order amounts recalculated by billing server after changes of the order
+type OrderAmounts struct {
+	HasVat              bool                 `json:"has_vat"`
+	Vat                 float64              `json:"vat"`
+	Amount              float64              `json:"amount"`
+	TotalAmount         float64              `json:"total_amount"`
+	Currency            string               `json:"currency"`
+	ChargeCurrency      string               `json:"charge_currency"`
+	ChargeAmount        float64              `json:"charge_amount"`
+	VatInChargeCurrency float64              `json:"vat_in_charge_currency"`
+	Items               []*billing.OrderItem `json:"items"`
+}
+
+// GetOrderStatusRequest
+type GetOrderStatusRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+// OrderStatus
+type OrderStatus struct {
+	OrderId         string `json:"order_id"`
+	MerchantOrderId string `json:"merchant_order_id,omitempty"`
+	Status          string `json:"status"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
+// GetOrderStatusResponse
+type GetOrderStatusResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *OrderStatus               `json:"item,omitempty"`
+}
+
+// GetMerchantOrderStatusRequest, order is searched by the order id of the merchant inside the project
+type GetMerchantOrderStatusRequest struct {
+	ProjectId       string `json:"project_id"`
+	MerchantOrderId string `json:"merchant_order_id"`
+}
+
+// CancelOrderRequest, only unpaid orders of the project can be canceled
+type CancelOrderRequest struct {
+	OrderId   string `json:"order_id"`
+	ProjectId string `json:"project_id"`
+	Reason    string `json:"reason,omitempty"`
+}
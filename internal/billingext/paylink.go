@@ -0,0 +1,57 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// PaylinkRequest
+type PaylinkRequest struct {
+	Id string `json:"id"`
+}
+
+// PaylinkPreview contains public paylink data used to render link previews
+type PaylinkPreview struct {
+	Id          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ProjectName string  `json:"project_name"`
+	ImageUrl    string  `json:"image_url"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+}
+
+// PaylinkPreviewResponse
+type PaylinkPreviewResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *PaylinkPreview            `json:"item,omitempty"`
+}
+
+// PaylinkShortCodeRequest
+type PaylinkShortCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// PaylinkShortCode
+type PaylinkShortCode struct {
+	Code      string `json:"code"`
+	PaylinkId string `json:"paylink_id"`
+}
+
+// PaylinkShortCodeResponse
+type PaylinkShortCodeResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *PaylinkShortCode          `json:"item,omitempty"`
+}
+
+// PaylinkVisits
+type PaylinkVisits struct {
+	PaylinkId string `json:"paylink_id"`
+	Count     int    `json:"count"`
+}
+
+// IncrPaylinkVisitsBatchRequest
+type IncrPaylinkVisitsBatchRequest struct {
+	Visits []*PaylinkVisits `json:"visits"`
+}
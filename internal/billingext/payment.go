@@ -0,0 +1,62 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+const (
+	PaymentReturnStatusProcessed = "processed"
+	PaymentReturnStatusPending   = "pending"
+	PaymentReturnStatusFailed    = "failed"
+	PaymentReturnStatusCanceled  = "canceled"
+)
+
+// PaymentReturnRequest contains data sent by payment provider with customer returned from 3-D Secure or
+// alternative payment method page
+type PaymentReturnRequest struct {
+	OrderId        string            `json:"order_id"`
+	Method         string            `json:"method"`
+	Payload        map[string]string `json:"payload"`
+	Cookie         string            `json:"cookie"`
+	Ip             string            `json:"ip"`
+	UserAgent      string            `json:"user_agent"`
+	AcceptLanguage string            `json:"accept_language"`
+}
+
+// PaymentReturnResult
+type PaymentReturnResult struct {
+	// Status is a payment status, one of PaymentReturnStatus* constants
+	Status string `json:"status"`
+}
+
+// PaymentReturnResponse
+type PaymentReturnResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *PaymentReturnResult       `json:"item,omitempty"`
+}
+
+const (
+	WalletApplePay  = "apple_pay"
+	WalletGooglePay = "google_pay"
+)
+
+// WalletPaymentRequest contains encrypted payment token received by the payment form from the wallet
+type WalletPaymentRequest struct {
+	OrderId        string `json:"order_id"`
+	Wallet         string `json:"wallet"`
+	Token          string `json:"token"`
+	Email          string `json:"email"`
+	Cookie         string `json:"cookie"`
+	Ip             string `json:"ip"`
+	UserAgent      string `json:"user_agent"`
+	AcceptLanguage string `json:"accept_language"`
+}
+
+// WalletPaymentResponse
+type WalletPaymentResponse struct {
+	Status       int32                      `json:"status"`
+	Message      *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	RedirectUrl  string                     `json:"redirect_url"`
+	NeedRedirect bool                       `json:"need_redirect"`
+}
@@ -0,0 +1,24 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// GetProjectAllowedOriginsRequest, project is resolved by the order if project id is empty
+type GetProjectAllowedOriginsRequest struct {
+	ProjectId string `json:"project_id,omitempty"`
+	OrderId   string `json:"order_id,omitempty"`
+}
+
+// ProjectAllowedOrigins describes origins where the project payment form can be embedded
+type ProjectAllowedOrigins struct {
+	ProjectId string   `json:"project_id"`
+	Origins   []string `json:"origins"`
+}
+
+// GetProjectAllowedOriginsResponse
+type GetProjectAllowedOriginsResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *ProjectAllowedOrigins     `json:"item,omitempty"`
+}
@@ -0,0 +1,42 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+const (
+	// PromoCodeErrorOrderNotFound is a code of the error returned if the order of the promo code request is unknown,
+	// unknown promo codes are reported with other codes
+	PromoCodeErrorOrderNotFound = "pc000001"
+)
+
+// ApplyPromoCodeRequest
+type ApplyPromoCodeRequest struct {
+	OrderId        string `json:"order_id"`
+	Code           string `json:"code"`
+	Cookie         string `json:"cookie"`
+	Ip             string `json:"ip"`
+	AcceptLanguage string `json:"accept_language"`
+	UserAgent      string `json:"user_agent"`
+}
+
+// RemovePromoCodeRequest
+type RemovePromoCodeRequest struct {
+	OrderId string `json:"order_id"`
+	Cookie  string `json:"cookie"`
+	Ip      string `json:"ip"`
+}
+
+// PromoCodeResponseItem
+type PromoCodeResponseItem struct {
+	OrderAmounts
+	PromoCode string  `json:"promo_code"`
+	Discount  float64 `json:"discount"`
+}
+
+// PromoCodeResponse
+type PromoCodeResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *PromoCodeResponseItem     `json:"item,omitempty"`
+}
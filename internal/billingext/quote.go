@@ -0,0 +1,40 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+// OrderPriceQuoteRequest
+type OrderPriceQuoteRequest struct {
+	Order   *billing.OrderCreateRequest `json:"order"`
+	Ip      string                      `json:"ip"`
+	Country string                      `json:"country"`
+}
+
+// OrderPriceQuote
+type OrderPriceQuote struct {
+	Id          string               `json:"id"`
+	ProjectId   string               `json:"project_id"`
+	Country     string               `json:"country"`
+	Amount      float64              `json:"amount"`
+	Tax         float64              `json:"tax"`
+	TaxRate     float64              `json:"tax_rate"`
+	TotalAmount float64              `json:"total_amount"`
+	Currency    string               `json:"currency"`
+	Items       []*billing.OrderItem `json:"items"`
+	ExpiresAt   int64                `json:"expires_at"`
+}
+
+// OrderPriceQuoteResponse
+type OrderPriceQuoteResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *OrderPriceQuote           `json:"item,omitempty"`
+}
+
+// LockOrderPriceRequest
+type LockOrderPriceRequest struct {
+	OrderId string `json:"order_id"`
+	QuoteId string `json:"quote_id"`
+}
@@ -0,0 +1,45 @@
+package billingext
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+)
+
+const (
+	CustomerRefundRequestStatusCreated  = "created"
+	CustomerRefundRequestStatusAccepted = "accepted"
+	CustomerRefundRequestStatusRejected = "rejected"
+	CustomerRefundRequestStatusRefunded = "refunded"
+)
+
+// CreateCustomerRefundRequest
+type CreateCustomerRefundRequest struct {
+	OrderId   string `json:"order_id"`
+	ReceiptId string `json:"receipt_id"`
+	Reason    string `json:"reason"`
+	Comment   string `json:"comment"`
+	Ip        string `json:"ip"`
+	Cookie    string `json:"cookie"`
+}
+
+// GetCustomerRefundRequest
+type GetCustomerRefundRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+// CustomerRefundRequest
+type CustomerRefundRequest struct {
+	Id        string `json:"id"`
+	OrderId   string `json:"order_id"`
+	Reason    string `json:"reason"`
+	Comment   string `json:"comment"`
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// CustomerRefundRequestResponse
+type CustomerRefundRequestResponse struct {
+	Status  int32                      `json:"status"`
+	Message *grpc.ResponseErrorMessage `json:"message,omitempty"`
+	Item    *CustomerRefundRequest     `json:"item,omitempty"`
+}
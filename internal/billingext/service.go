@@ -0,0 +1,254 @@
+package billingext
+
+import (
+	"context"
+	"github.com/micro/go-micro/client"
+)
+
+// Service describes billing server methods used by checkout which are not a part of the generated billing client
+type Service interface {
+	CreateCustomerRefundRequest(ctx context.Context, in *CreateCustomerRefundRequest, opts ...client.CallOption) (*CustomerRefundRequestResponse, error)
+	GetCustomerRefundRequest(ctx context.Context, in *GetCustomerRefundRequest, opts ...client.CallOption) (*CustomerRefundRequestResponse, error)
+	ApplyPromoCode(ctx context.Context, in *ApplyPromoCodeRequest, opts ...client.CallOption) (*PromoCodeResponse, error)
+	RemovePromoCode(ctx context.Context, in *RemovePromoCodeRequest, opts ...client.CallOption) (*PromoCodeResponse, error)
+	GetOrderPriceQuote(ctx context.Context, in *OrderPriceQuoteRequest, opts ...client.CallOption) (*OrderPriceQuoteResponse, error)
+	LockOrderPrice(ctx context.Context, in *LockOrderPriceRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	GetPaylinkPreview(ctx context.Context, in *PaylinkRequest, opts ...client.CallOption) (*PaylinkPreviewResponse, error)
+	GetPaylinkShortCode(ctx context.Context, in *PaylinkRequest, opts ...client.CallOption) (*PaylinkShortCodeResponse, error)
+	GetPaylinkByShortCode(ctx context.Context, in *PaylinkShortCodeRequest, opts ...client.CallOption) (*PaylinkShortCodeResponse, error)
+	SetOrderAttribution(ctx context.Context, in *SetOrderAttributionRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	IncrPaylinkVisitsBatch(ctx context.Context, in *IncrPaylinkVisitsBatchRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	ProcessPaymentReturn(ctx context.Context, in *PaymentReturnRequest, opts ...client.CallOption) (*PaymentReturnResponse, error)
+	PaymentCreateByWallet(ctx context.Context, in *WalletPaymentRequest, opts ...client.CallOption) (*WalletPaymentResponse, error)
+	GetBinData(ctx context.Context, in *BinDataRequest, opts ...client.CallOption) (*BinDataResponse, error)
+	ProcessBusinessBillingAddress(ctx context.Context, in *BusinessBillingAddressRequest, opts ...client.CallOption) (*BusinessBillingAddressResponse, error)
+	SetOrderIpCountry(ctx context.Context, in *SetOrderIpCountryRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	SetOrderDeviceData(ctx context.Context, in *SetOrderDeviceDataRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	GetOrderStatus(ctx context.Context, in *GetOrderStatusRequest, opts ...client.CallOption) (*GetOrderStatusResponse, error)
+	GetProjectAllowedOrigins(ctx context.Context, in *GetProjectAllowedOriginsRequest, opts ...client.CallOption) (*GetProjectAllowedOriginsResponse, error)
+	GetMerchantApiKey(ctx context.Context, in *GetMerchantApiKeyRequest, opts ...client.CallOption) (*GetMerchantApiKeyResponse, error)
+	GetMerchantOrderStatus(ctx context.Context, in *GetMerchantOrderStatusRequest, opts ...client.CallOption) (*GetOrderStatusResponse, error)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...client.CallOption) (*EmptyResponse, error)
+}
+
+type service struct {
+	c    client.Client
+	name string
+}
+
+// NewService
+func NewService(name string, c client.Client) Service {
+	if c == nil {
+		c = client.NewClient()
+	}
+	if len(name) == 0 {
+		name = "billing"
+	}
+	return &service{
+		c:    c,
+		name: name,
+	}
+}
+
+func (c *service) call(ctx context.Context, method string, in, out interface{}, opts ...client.CallOption) error {
+	req := c.c.NewRequest(c.name, "BillingService."+method, in, client.WithContentType(ContentType))
+	return c.c.Call(ctx, req, out, opts...)
+}
+
+// CreateCustomerRefundRequest
+func (c *service) CreateCustomerRefundRequest(ctx context.Context, in *CreateCustomerRefundRequest, opts ...client.CallOption) (*CustomerRefundRequestResponse, error) {
+	out := new(CustomerRefundRequestResponse)
+	if err := c.call(ctx, "CreateCustomerRefundRequest", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCustomerRefundRequest
+func (c *service) GetCustomerRefundRequest(ctx context.Context, in *GetCustomerRefundRequest, opts ...client.CallOption) (*CustomerRefundRequestResponse, error) {
+	out := new(CustomerRefundRequestResponse)
+	if err := c.call(ctx, "GetCustomerRefundRequest", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ApplyPromoCode
+func (c *service) ApplyPromoCode(ctx context.Context, in *ApplyPromoCodeRequest, opts ...client.CallOption) (*PromoCodeResponse, error) {
+	out := new(PromoCodeResponse)
+	if err := c.call(ctx, "ApplyPromoCode", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RemovePromoCode
+func (c *service) RemovePromoCode(ctx context.Context, in *RemovePromoCodeRequest, opts ...client.CallOption) (*PromoCodeResponse, error) {
+	out := new(PromoCodeResponse)
+	if err := c.call(ctx, "RemovePromoCode", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOrderPriceQuote
+func (c *service) GetOrderPriceQuote(ctx context.Context, in *OrderPriceQuoteRequest, opts ...client.CallOption) (*OrderPriceQuoteResponse, error) {
+	out := new(OrderPriceQuoteResponse)
+	if err := c.call(ctx, "GetOrderPriceQuote", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LockOrderPrice
+func (c *service) LockOrderPrice(ctx context.Context, in *LockOrderPriceRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	if err := c.call(ctx, "LockOrderPrice", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPaylinkPreview
+func (c *service) GetPaylinkPreview(ctx context.Context, in *PaylinkRequest, opts ...client.CallOption) (*PaylinkPreviewResponse, error) {
+	out := new(PaylinkPreviewResponse)
+	if err := c.call(ctx, "GetPaylinkPreview", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPaylinkShortCode
+func (c *service) GetPaylinkShortCode(ctx context.Context, in *PaylinkRequest, opts ...client.CallOption) (*PaylinkShortCodeResponse, error) {
+	out := new(PaylinkShortCodeResponse)
+	if err := c.call(ctx, "GetPaylinkShortCode", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPaylinkByShortCode
+func (c *service) GetPaylinkByShortCode(ctx context.Context, in *PaylinkShortCodeRequest, opts ...client.CallOption) (*PaylinkShortCodeResponse, error) {
+	out := new(PaylinkShortCodeResponse)
+	if err := c.call(ctx, "GetPaylinkByShortCode", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetOrderAttribution
+func (c *service) SetOrderAttribution(ctx context.Context, in *SetOrderAttributionRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	if err := c.call(ctx, "SetOrderAttribution", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IncrPaylinkVisitsBatch
+func (c *service) IncrPaylinkVisitsBatch(ctx context.Context, in *IncrPaylinkVisitsBatchRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	if err := c.call(ctx, "IncrPaylinkVisitsBatch", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProcessPaymentReturn
+func (c *service) ProcessPaymentReturn(ctx context.Context, in *PaymentReturnRequest, opts ...client.CallOption) (*PaymentReturnResponse, error) {
+	out := new(PaymentReturnResponse)
+	if err := c.call(ctx, "ProcessPaymentReturn", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PaymentCreateByWallet
+func (c *service) PaymentCreateByWallet(ctx context.Context, in *WalletPaymentRequest, opts ...client.CallOption) (*WalletPaymentResponse, error) {
+	out := new(WalletPaymentResponse)
+	if err := c.call(ctx, "PaymentCreateByWallet", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetBinData
+func (c *service) GetBinData(ctx context.Context, in *BinDataRequest, opts ...client.CallOption) (*BinDataResponse, error) {
+	out := new(BinDataResponse)
+	if err := c.call(ctx, "GetBinData", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProcessBusinessBillingAddress
+func (c *service) ProcessBusinessBillingAddress(ctx context.Context, in *BusinessBillingAddressRequest, opts ...client.CallOption) (*BusinessBillingAddressResponse, error) {
+	out := new(BusinessBillingAddressResponse)
+	if err := c.call(ctx, "ProcessBusinessBillingAddress", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetOrderIpCountry
+func (c *service) SetOrderIpCountry(ctx context.Context, in *SetOrderIpCountryRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	if err := c.call(ctx, "SetOrderIpCountry", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetOrderDeviceData
+func (c *service) SetOrderDeviceData(ctx context.Context, in *SetOrderDeviceDataRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	if err := c.call(ctx, "SetOrderDeviceData", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOrderStatus
+func (c *service) GetOrderStatus(ctx context.Context, in *GetOrderStatusRequest, opts ...client.CallOption) (*GetOrderStatusResponse, error) {
+	out := new(GetOrderStatusResponse)
+	if err := c.call(ctx, "GetOrderStatus", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetProjectAllowedOrigins
+func (c *service) GetProjectAllowedOrigins(ctx context.Context, in *GetProjectAllowedOriginsRequest, opts ...client.CallOption) (*GetProjectAllowedOriginsResponse, error) {
+	out := new(GetProjectAllowedOriginsResponse)
+	if err := c.call(ctx, "GetProjectAllowedOrigins", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMerchantApiKey
+func (c *service) GetMerchantApiKey(ctx context.Context, in *GetMerchantApiKeyRequest, opts ...client.CallOption) (*GetMerchantApiKeyResponse, error) {
+	out := new(GetMerchantApiKeyResponse)
+	if err := c.call(ctx, "GetMerchantApiKey", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMerchantOrderStatus
+func (c *service) GetMerchantOrderStatus(ctx context.Context, in *GetMerchantOrderStatusRequest, opts ...client.CallOption) (*GetOrderStatusResponse, error) {
+	out := new(GetOrderStatusResponse)
+	if err := c.call(ctx, "GetMerchantOrderStatus", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CancelOrder
+func (c *service) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	if err := c.call(ctx, "CancelOrder", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,12 @@
+package daemon
+
+import (
+	"github.com/paysuper/paysuper-checkout/pkg/http"
+	"github.com/paysuper/paysuper-checkout/pkg/micro"
+)
+
+// Daemon contains http and micro servers of the checkout, servers share micro service used by the handlers
+type Daemon struct {
+	HTTP  *http.HTTP
+	Micro *micro.Micro
+}
@@ -15,8 +15,8 @@ import (
 	"github.com/paysuper/paysuper-checkout/pkg/micro"
 )
 
-// BuildHTTP
-func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Observer) (*http.HTTP, func(), error) {
+// BuildDaemon
+func BuildDaemon(ctx context.Context, initial config.Initial, observer invoker.Observer) (*Daemon, func(), error) {
 	panic(
 		wire.Build(
 			provider.Set,
@@ -27,16 +27,7 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 			validators.WireSet,
 			dispatcher.WireSet,
 			handlers.ProviderHandlers,
+			wire.Struct(new(Daemon), "*"),
 		),
 	)
 }
-
-// BuildMicro
-func BuildMicro(ctx context.Context, initial config.Initial, observer invoker.Observer) (*micro.Micro, func(), error) {
-	panic(
-		wire.Build(
-			micro.WireSet,
-			provider.Set,
-			wire.Struct(new(provider.AwareSet), "*")),
-	)
-}
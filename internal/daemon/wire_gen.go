@@ -22,7 +22,7 @@ import (
 
 // Injectors from injector.go:
 
-func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Observer) (*http.HTTP, func(), error) {
+func BuildDaemon(ctx context.Context, initial config.Initial, observer invoker.Observer) (*Daemon, func(), error) {
 	configurator, cleanup, err := config.Provider(initial, observer)
 	if err != nil {
 		return nil, nil, err
@@ -128,7 +128,7 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	commonConfig, cleanup12, err := dispatcher.ProviderGlobalCfg(configurator)
+	dispatcherConfig, cleanup12, err := dispatcher.ProviderCfg(configurator)
 	if err != nil {
 		cleanup11()
 		cleanup10()
@@ -143,7 +143,7 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	commonHandlers, cleanup13, err := handlers.ProviderHandlers(initial, services, validate, awareSet, commonConfig)
+	globalConfig, cleanup13, err := dispatcher.ProviderGlobalCfg(configurator, dispatcherConfig, awareSet)
 	if err != nil {
 		cleanup12()
 		cleanup11()
@@ -159,11 +159,7 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	appSet := dispatcher.AppSet{
-		Handlers: commonHandlers,
-		Services: services,
-	}
-	dispatcherConfig, cleanup14, err := dispatcher.ProviderCfg(configurator)
+	commonHandlers, cleanup14, err := handlers.ProviderHandlers(initial, services, validate, awareSet, globalConfig)
 	if err != nil {
 		cleanup13()
 		cleanup12()
@@ -180,7 +176,11 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	dispatcherDispatcher, cleanup15, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, commonConfig, microMicro)
+	appSet := dispatcher.AppSet{
+		Handlers: commonHandlers,
+		Services: services,
+	}
+	dispatcherDispatcher, cleanup15, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, globalConfig, microMicro)
 	if err != nil {
 		cleanup14()
 		cleanup13()
@@ -237,7 +237,11 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	return httpHTTP, func() {
+	daemon := &Daemon{
+		HTTP:  httpHTTP,
+		Micro: microMicro,
+	}
+	return daemon, func() {
 		cleanup17()
 		cleanup16()
 		cleanup15()
@@ -257,94 +261,3 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 	}, nil
 }
-
-func BuildMicro(ctx context.Context, initial config.Initial, observer invoker.Observer) (*micro.Micro, func(), error) {
-	configurator, cleanup, err := config.Provider(initial, observer)
-	if err != nil {
-		return nil, nil, err
-	}
-	loggerConfig, cleanup2, err := logger.ProviderCfg(configurator)
-	if err != nil {
-		cleanup()
-		return nil, nil, err
-	}
-	zap, cleanup3, err := logger.Provider(ctx, loggerConfig)
-	if err != nil {
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	metricConfig, cleanup4, err := metric.ProviderCfg(configurator)
-	if err != nil {
-		cleanup3()
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	scope, cleanup5, err := metric.Provider(ctx, zap, metricConfig)
-	if err != nil {
-		cleanup4()
-		cleanup3()
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	tracingConfig, cleanup6, err := tracing.ProviderCfg(configurator)
-	if err != nil {
-		cleanup5()
-		cleanup4()
-		cleanup3()
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	tracer, cleanup7, err := tracing.Provider(ctx, tracingConfig, zap)
-	if err != nil {
-		cleanup6()
-		cleanup5()
-		cleanup4()
-		cleanup3()
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	awareSet := provider.AwareSet{
-		Logger: zap,
-		Metric: scope,
-		Tracer: tracer,
-	}
-	microConfig, cleanup8, err := micro.Cfg(configurator)
-	if err != nil {
-		cleanup7()
-		cleanup6()
-		cleanup5()
-		cleanup4()
-		cleanup3()
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	microMicro, cleanup9, err := micro.Provider(ctx, awareSet, microConfig)
-	if err != nil {
-		cleanup8()
-		cleanup7()
-		cleanup6()
-		cleanup5()
-		cleanup4()
-		cleanup3()
-		cleanup2()
-		cleanup()
-		return nil, nil, err
-	}
-	return microMicro, func() {
-		cleanup9()
-		cleanup8()
-		cleanup7()
-		cleanup6()
-		cleanup5()
-		cleanup4()
-		cleanup3()
-		cleanup2()
-		cleanup()
-	}, nil
-}
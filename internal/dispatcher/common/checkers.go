@@ -45,8 +45,8 @@ func GetValidationError(err error) (rspErr grpc.ResponseErrorMessage) {
 	if ok {
 		rspErr = val
 	} else {
-		if vErr.Tag() == RequestParameterZipUsa {
-			rspErr = ErrorMessageIncorrectZip
+		if val, ok = ValidationTagErrors[vErr.Tag()]; ok {
+			rspErr = val
 		} else {
 			rspErr = ErrorValidationFailed
 		}
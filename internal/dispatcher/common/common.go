@@ -1,11 +1,16 @@
 package common
 
 import (
+	"context"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/labstack/echo/v4"
+	"github.com/micro/go-micro/broker"
 	"github.com/paysuper/paysuper-billing-server/pkg"
 	billingService "github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/pkg/merchantauth"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
 	"gopkg.in/go-playground/validator.v9"
 	"net/http"
 )
@@ -15,6 +20,8 @@ const (
 	UnmarshalKey             = "dispatcher"
 	UnmarshalGlobalConfigKey = "dispatcher.global"
 	NoAuthGroupPath          = "/api/v1"
+	ApiV2GroupPath           = "/api/v2"
+	MerchantGroupPath        = "/api/v2/merchant"
 )
 
 // ExtractRawBodyContext
@@ -38,14 +45,88 @@ func SetRawBodyContext(ctx echo.Context, rawBody []byte) {
 	ctx.Set("rawBody", rawBody)
 }
 
+// ExtractIpCountryContext returns country resolved by GeoIP, empty string is returned if GeoIP is disabled or country is unknown
+func ExtractIpCountryContext(ctx echo.Context) string {
+	if country, ok := ctx.Get("ipCountry").(string); ok {
+		return country
+	}
+	return ""
+}
+
+// SetIpCountryContext
+func SetIpCountryContext(ctx echo.Context, country string) {
+	ctx.Set("ipCountry", country)
+}
+
+// ExtractMaintenanceContext returns true if request is processed in maintenance mode
+func ExtractMaintenanceContext(ctx echo.Context) bool {
+	maintenance, _ := ctx.Get("maintenance").(bool)
+	return maintenance
+}
+
+// SetMaintenanceContext
+func SetMaintenanceContext(ctx echo.Context) {
+	ctx.Set("maintenance", true)
+}
+
+// ExtractMerchantKeyContext returns api key which authenticated request of the merchant group
+func ExtractMerchantKeyContext(ctx echo.Context) *merchantauth.Key {
+	if key, ok := ctx.Get("merchantKey").(*merchantauth.Key); ok {
+		return key
+	}
+	return nil
+}
+
+// SetMerchantKeyContext
+func SetMerchantKeyContext(ctx echo.Context, key *merchantauth.Key) {
+	ctx.Set("merchantKey", key)
+}
+
 // SetBinder
 func SetBinder(ctx echo.Context, binder echo.Binder) {
 	ctx.Set("binder", binder)
 }
 
-// Groups
+// Groups contains groups of the API versions, merchant group authenticated by api keys
+// and root group for routes outside of the API
 type Groups struct {
-	Common *echo.Group
+	V1       *echo.Group
+	V2       *echo.Group
+	Merchant *echo.Group
+	Root     *echo.Group
+
+	bodyLimits map[string]*BodyLimit
+}
+
+// BodyLimit is a max size of the route request body which overrides the global limit,
+// requests with bigger body are rejected with the error before the body is read
+type BodyLimit struct {
+	Size  func() int
+	Error billingService.ResponseErrorMessage
+}
+
+// Shared mounts routes which are the same in all API versions
+func (g *Groups) Shared(route func(group *echo.Group)) {
+	route(g.V1)
+	route(g.V2)
+}
+
+// LimitBody sets max size of the request body of the route
+func (g *Groups) LimitBody(route *echo.Route, limit *BodyLimit) {
+	if g.bodyLimits == nil {
+		g.bodyLimits = make(map[string]*BodyLimit)
+	}
+
+	g.bodyLimits[route.Method+" "+route.Path] = limit
+}
+
+// GetBodyLimit returns max size of the request body of the route or nil if the route has no own limit
+func (g *Groups) GetBodyLimit(method, path string) *BodyLimit {
+	if g == nil {
+		return nil
+	}
+
+	return g.bodyLimits[method+" "+path]
 }
 
 // Handler
@@ -53,6 +134,16 @@ type Handler interface {
 	Route(groups *Groups)
 }
 
+// Reloader is implemented by handlers which reload their resources when the service configuration is reloaded
+type Reloader interface {
+	Reload(ctx context.Context)
+}
+
+// CacheInvalidator is implemented by handlers which keep in-memory caches, names of purged caches are returned
+type CacheInvalidator interface {
+	InvalidateCaches(ctx context.Context) []string
+}
+
 // Validate
 type Validator interface {
 	Use(validator *validator.Validate)
@@ -60,7 +151,9 @@ type Validator interface {
 
 // Services
 type Services struct {
-	Billing billingService.BillingService
+	Billing    billingService.BillingService
+	BillingExt billingext.Service
+	Broker     broker.Broker
 }
 
 // Handlers
@@ -68,9 +161,10 @@ type Handlers []Handler
 
 // HandlerSet
 type HandlerSet struct {
-	Services Services
-	Validate *validator.Validate
-	AwareSet provider.AwareSet
+	Services    Services
+	Validate    *validator.Validate
+	AwareSet    provider.AwareSet
+	OrderStates *orderstate.Store
 }
 
 // BindAndValidate
@@ -2,10 +2,215 @@ package common
 
 type Config struct {
 	CookieDomain string `envconfig:"COOKIE_DOMAIN" required:"true"`
-	AllowOrigin  string `envconfig:"ALLOW_ORIGIN" default:"*"`
+
+	// AllowOrigin is a comma separated list of origins allowed for every project, it's empty by default
+	// because "*" allows any origin and makes project origins pointless
+	AllowOrigin string `envconfig:"ALLOW_ORIGIN"`
+
+	// ApiV1SunsetDate is a date like 2021-06-30 when API v1 is removed, v1 responses are marked as deprecated if it's set
+	ApiV1SunsetDate string `envconfig:"API_V1_SUNSET_DATE"`
+
+	// CorsProjectOrigins enables cross-origin requests from origins allowed by the project settings in billing,
+	// AllowOrigin is checked first and works as a global fallback
+	CorsProjectOrigins bool `envconfig:"CORS_PROJECT_ORIGINS" default:"true"`
+
+	// CorsProjectOriginsCacheSize and CorsProjectOriginsCacheTtlMinutes limit cache of the project origins
+	CorsProjectOriginsCacheSize       int   `envconfig:"CORS_PROJECT_ORIGINS_CACHE_SIZE" default:"10000"`
+	CorsProjectOriginsCacheTtlMinutes int64 `envconfig:"CORS_PROJECT_ORIGINS_CACHE_TTL_MINUTES" default:"10"`
+
+	// RequestBodyMaxSize is a max size of the request body in bytes, routes can set lower limit
+	RequestBodyMaxSize int `envconfig:"REQUEST_BODY_MAX_SIZE" default:"1048576"`
 
 	// OrderInlineFormUrlMask url like a https://checkout.tst.pay.super.com/pay/order/
 	OrderInlineFormUrlMask string `envconfig:"ORDER_INLINE_FORM_URL_MASK" required:"true"`
 
+	// PaymentFormJsLibraryUrl url of the payment form JS library loaded by the embedded paylink page
+	PaymentFormJsLibraryUrl string `envconfig:"PAYMENT_FORM_JS_LIBRARY_URL"`
+
 	CustomerTokenCookiesLifetimeHours int64 `envconfig:"CUSTOMER_TOKEN_COOKIES_LIFETIME" default:"720"`
+
+	// PaymentReturnRedirect enables redirect of the customer returned from payment provider back to the payment form,
+	// result page is rendered otherwise
+	PaymentReturnRedirect bool `envconfig:"PAYMENT_RETURN_REDIRECT" default:"true"`
+
+	// PaymentDataAllowedFields is a comma separated list of fields accepted in the payment creation request
+	PaymentDataAllowedFields string `envconfig:"PAYMENT_DATA_ALLOWED_FIELDS" default:"order_id,payment_method_id,email,pan,cvv,month,year,card_holder,ewallet,address,store_data,recurring_id,stored_card_id,country,city,zip"`
+
+	// PaylinkCrawlerUserAgents is a comma separated list of user agent substrings of the link preview crawlers,
+	// paylink preview page is rendered for them instead of the order creation
+	PaylinkCrawlerUserAgents string `envconfig:"PAYLINK_CRAWLER_USER_AGENTS" default:"facebookexternalhit,Facebot,Twitterbot,LinkedInBot,Slackbot,TelegramBot,WhatsApp,Discordbot,vkShare,SkypeUriPreview,Pinterest,redditbot,Applebot,Googlebot,bingbot,YandexBot,Embedly"`
+
+	// PaylinkVisitBotUserAgents is a comma separated list of user agent substrings of bots which visits aren't counted
+	PaylinkVisitBotUserAgents string `envconfig:"PAYLINK_VISIT_BOT_USER_AGENTS" default:"bot,spider,crawl,slurp,curl,wget,python-requests,go-http-client,okhttp,headless,phantomjs,preview"`
+
+	// PaylinkVisitsFlushIntervalSeconds is an interval of sending accumulated paylink visits to billing
+	PaylinkVisitsFlushIntervalSeconds int64 `envconfig:"PAYLINK_VISITS_FLUSH_INTERVAL_SECONDS" default:"10"`
+
+	// PaylinkVisitsWindowMinutes is a window inside which repeated paylink visits of the same visitor are counted once
+	PaylinkVisitsWindowMinutes int64 `envconfig:"PAYLINK_VISITS_WINDOW_MINUTES" default:"30"`
+
+	// PaylinkVisitsMaxPending and PaylinkVisitsMaxVisitors limit memory used by paylink visits counting
+	PaylinkVisitsMaxPending  int `envconfig:"PAYLINK_VISITS_MAX_PENDING" default:"10000"`
+	PaylinkVisitsMaxVisitors int `envconfig:"PAYLINK_VISITS_MAX_VISITORS" default:"100000"`
+
+	// PaylinkShortUrlMask url like a https://checkout.pay.super.com/p/, used in the paylink QR codes.
+	// If empty the url is built from the request host
+	PaylinkShortUrlMask string `envconfig:"PAYLINK_SHORT_URL_MASK"`
+
+	// PaylinkQrCodeSize is a default size of the paylink QR code image in pixels, size requested by client is limited by PaylinkQrCodeMaxSize
+	PaylinkQrCodeSize    int `envconfig:"PAYLINK_QR_CODE_SIZE" default:"256"`
+	PaylinkQrCodeMaxSize int `envconfig:"PAYLINK_QR_CODE_MAX_SIZE" default:"2048"`
+
+	// PaylinkQrCodeLevel is a default error correction level of the paylink QR code: L, M, Q or H
+	PaylinkQrCodeLevel string `envconfig:"PAYLINK_QR_CODE_LEVEL" default:"M"`
+
+	// AttributionParameters is a comma separated list of query parameters saved as order attribution, empty list disables attribution
+	AttributionParameters string `envconfig:"ATTRIBUTION_PARAMETERS" default:"utm_source,utm_medium,utm_campaign,utm_term,utm_content,gclid,fbclid,ref"`
+
+	// AttributionCookieLifetimeHours is a lifetime of the cookie which keeps attribution between customer visits
+	AttributionCookieLifetimeHours int64 `envconfig:"ATTRIBUTION_COOKIE_LIFETIME_HOURS" default:"720"`
+
+	// VatCheckerUrl is a base url of the VIES REST API like https://ec.europa.eu/taxation_customs/vies/rest-api,
+	// vat ids are checked offline only if empty
+	VatCheckerUrl string `envconfig:"VAT_CHECKER_URL"`
+
+	// VatCheckerTimeoutSeconds is a timeout of the vat id online check
+	VatCheckerTimeoutSeconds int64 `envconfig:"VAT_CHECKER_TIMEOUT_SECONDS" default:"5"`
+
+	// VatCheckerStrict rejects vat ids which can't be checked online because registry is unavailable,
+	// otherwise they are sent to billing as not verified
+	VatCheckerStrict bool `envconfig:"VAT_CHECKER_STRICT" default:"false"`
+
+	// BinDatabaseFile is a path to the csv file with columns bin,brand,type,category,bank,country,
+	// the file is reloaded with the service configuration
+	BinDatabaseFile string `envconfig:"BIN_DATABASE_FILE"`
+
+	// BinBillingFallback enables lookup in billing for bins missing in the database file
+	BinBillingFallback bool `envconfig:"BIN_BILLING_FALLBACK" default:"true"`
+
+	// BinCacheSize and BinCacheTtlMinutes limit cache of the bin lookup results
+	BinCacheSize       int   `envconfig:"BIN_CACHE_SIZE" default:"10000"`
+	BinCacheTtlMinutes int64 `envconfig:"BIN_CACHE_TTL_MINUTES" default:"60"`
+
+	// BinLookupLimit is a max count of bin lookups per order inside a minute
+	BinLookupLimit int `envconfig:"BIN_LOOKUP_LIMIT" default:"30"`
+
+	// GeoIpDatabaseFile is a path to the MaxMind format country or city database, GeoIP is disabled if empty.
+	// The file is reloaded with the service configuration
+	GeoIpDatabaseFile string `envconfig:"GEOIP_DATABASE_FILE"`
+
+	// DeviceDataMaxSize is a max size of the device fingerprint request body in bytes
+	DeviceDataMaxSize int `envconfig:"DEVICE_DATA_MAX_SIZE" default:"4096"`
+
+	// DeviceDataLimit is a max count of device fingerprint requests per order inside a minute
+	DeviceDataLimit int `envconfig:"DEVICE_DATA_LIMIT" default:"10"`
+
+	// CaptchaProvider is one of hcaptcha, recaptcha, turnstile or fake, payment challenge is disabled if empty
+	CaptchaProvider string `envconfig:"CAPTCHA_PROVIDER"`
+
+	// CaptchaSecret is a secret key of the provider, fake provider accepts it as the only valid token.
+	// CaptchaSiteKey is returned with the challenge required error to render the widget
+	CaptchaSecret  string `envconfig:"CAPTCHA_SECRET"`
+	CaptchaSiteKey string `envconfig:"CAPTCHA_SITE_KEY"`
+
+	// CaptchaVerifyUrl overrides default siteverify url of the provider
+	CaptchaVerifyUrl string `envconfig:"CAPTCHA_VERIFY_URL"`
+
+	// CaptchaMinScore is a min score of the providers returning score like reCAPTCHA v3
+	CaptchaMinScore float64 `envconfig:"CAPTCHA_MIN_SCORE" default:"0.5"`
+
+	// CaptchaTimeoutSeconds is a timeout of the token verification request
+	CaptchaTimeoutSeconds int64 `envconfig:"CAPTCHA_TIMEOUT_SECONDS" default:"5"`
+
+	// CaptchaFailuresPerIp, CaptchaFailuresPerOrder and CaptchaFailuresPerCookie are counts of the failed payments
+	// inside CaptchaFailuresWindowMinutes after which challenge is required, zero disables the rule
+	CaptchaFailuresPerIp         int   `envconfig:"CAPTCHA_FAILURES_PER_IP" default:"10"`
+	CaptchaFailuresPerOrder      int   `envconfig:"CAPTCHA_FAILURES_PER_ORDER" default:"3"`
+	CaptchaFailuresPerCookie     int   `envconfig:"CAPTCHA_FAILURES_PER_COOKIE" default:"5"`
+	CaptchaFailuresWindowMinutes int64 `envconfig:"CAPTCHA_FAILURES_WINDOW_MINUTES" default:"60"`
+
+	// EventsSink is a destination of the payment form events: log, file or broker, events aren't accepted if empty
+	EventsSink string `envconfig:"EVENTS_SINK" default:"log"`
+
+	// EventsFile is a path to the file events are appended to as JSON lines, the file is reopened with the service configuration
+	EventsFile string `envconfig:"EVENTS_FILE"`
+
+	// EventsBrokerTopic is a topic events are published to by the micro service broker
+	EventsBrokerTopic string `envconfig:"EVENTS_BROKER_TOPIC" default:"checkout.payment_form.events"`
+
+	// EventsFlushIntervalSeconds is an interval of writing accumulated events to the sink
+	EventsFlushIntervalSeconds int64 `envconfig:"EVENTS_FLUSH_INTERVAL_SECONDS" default:"5"`
+
+	// EventsMaxPending limits memory used by events waiting for flush, new events are dropped when it's reached
+	EventsMaxPending int `envconfig:"EVENTS_MAX_PENDING" default:"10000"`
+
+	// EventsMaxSize is a max size of the events request body in bytes
+	EventsMaxSize int `envconfig:"EVENTS_MAX_SIZE" default:"16384"`
+
+	// EventsLimit is a max count of events requests per order inside a minute
+	EventsLimit int `envconfig:"EVENTS_LIMIT" default:"60"`
+
+	// MaintenanceRetryAfterSeconds is sent in Retry-After header of the requests rejected in maintenance mode
+	MaintenanceRetryAfterSeconds int64 `envconfig:"MAINTENANCE_RETRY_AFTER_SECONDS" default:"300"`
+
+	// MerchantKeyCacheSize and MerchantKeyCacheTtlMinutes limit cache of the merchant api keys,
+	// revoked keys are accepted until they expire in the cache
+	MerchantKeyCacheSize       int   `envconfig:"MERCHANT_KEY_CACHE_SIZE" default:"1000"`
+	MerchantKeyCacheTtlMinutes int64 `envconfig:"MERCHANT_KEY_CACHE_TTL_MINUTES" default:"5"`
+
+	// MerchantRateLimit is a max count of requests per api key inside a minute for keys without own limit, zero disables limiting
+	MerchantRateLimit int `envconfig:"MERCHANT_RATE_LIMIT" default:"600"`
+
+	// MerchantIpRateLimit is a max count of requests per ip inside a minute checked before the api key is looked up,
+	// zero disables limiting
+	MerchantIpRateLimit int `envconfig:"MERCHANT_IP_RATE_LIMIT" default:"1200"`
+
+	// MerchantSignatureMaxSkewSeconds is a max difference between timestamp of the signed request and server time
+	MerchantSignatureMaxSkewSeconds int64 `envconfig:"MERCHANT_SIGNATURE_MAX_SKEW_SECONDS" default:"300"`
+
+	// MerchantSignatureCacheSize is a max count of signatures of the accepted requests kept to reject their replays
+	MerchantSignatureCacheSize int `envconfig:"MERCHANT_SIGNATURE_CACHE_SIZE" default:"100000"`
+
+	// OrderStatusTopic is a broker topic of the billing order status notifications, notifications aren't received if empty
+	OrderStatusTopic string `envconfig:"ORDER_STATUS_TOPIC" default:"order.status_changed"`
+
+	// OrderStatusTtlSeconds is a time order status received from billing is used without billing request
+	OrderStatusTtlSeconds int64 `envconfig:"ORDER_STATUS_TTL_SECONDS" default:"600"`
+
+	// OrderStatusMaxOrders limits memory used by order statuses
+	OrderStatusMaxOrders int `envconfig:"ORDER_STATUS_MAX_ORDERS" default:"100000"`
+
+	// ApplePayMerchantIdentifier, ApplePayMerchantCertFile and ApplePayMerchantKeyFile are Apple Pay merchant identity,
+	// Apple Pay is disabled when they are empty
+	ApplePayMerchantIdentifier string `envconfig:"APPLE_PAY_MERCHANT_IDENTIFIER"`
+	ApplePayMerchantCertFile   string `envconfig:"APPLE_PAY_MERCHANT_CERT_FILE"`
+	ApplePayMerchantKeyFile    string `envconfig:"APPLE_PAY_MERCHANT_KEY_FILE"`
+	ApplePayDisplayName        string `envconfig:"APPLE_PAY_DISPLAY_NAME" default:"PaySuper"`
+
+	// ApplePayDomain is a payment form domain registered in Apple Pay
+	ApplePayDomain string `envconfig:"APPLE_PAY_DOMAIN"`
+
+	// ApplePayValidationHosts is a comma separated list of hosts allowed in the merchant validation url, subdomains are allowed
+	ApplePayValidationHosts string `envconfig:"APPLE_PAY_VALIDATION_HOSTS" default:"apple.com"`
+
+	// ApplePayDomainAssociationFile is a path to the domain association file received from Apple
+	ApplePayDomainAssociationFile string `envconfig:"APPLE_PAY_DOMAIN_ASSOCIATION_FILE"`
+
+	// ApplePaySessionLimit is a max count of Apple Pay session requests per order inside a minute
+	ApplePaySessionLimit int `envconfig:"APPLE_PAY_SESSION_LIMIT" default:"10"`
+
+	// SigningSecret used to sign tokens issued by checkout, must be the same for all checkout instances.
+	// It's required if price quotes or attribution are enabled
+	SigningSecret string `envconfig:"SIGNING_SECRET"`
+
+	// QuoteTokenLifetimeMinutes is a max lifetime of the price quote token, zero disables price quotes
+	QuoteTokenLifetimeMinutes int64 `envconfig:"QUOTE_TOKEN_LIFETIME_MINUTES" default:"15"`
+
+	// RefundRequestLimit is a max count of customer refund requests per order inside the RefundRequestLimitWindowHours
+	RefundRequestLimit            int   `envconfig:"REFUND_REQUEST_LIMIT" default:"3"`
+	RefundRequestLimitWindowHours int64 `envconfig:"REFUND_REQUEST_LIMIT_WINDOW_HOURS" default:"24"`
+
+	// PromoCodeAttemptsLimit is a max count of promo code applying attempts per order inside the PromoCodeAttemptsLimitWindowMinutes
+	PromoCodeAttemptsLimit              int   `envconfig:"PROMO_CODE_ATTEMPTS_LIMIT" default:"5"`
+	PromoCodeAttemptsLimitWindowMinutes int64 `envconfig:"PROMO_CODE_ATTEMPTS_LIMIT_WINDOW_MINUTES" default:"60"`
 }
@@ -11,10 +11,15 @@ type Dictionary struct {
 }
 
 const (
-	RequestParameterId        = "id"
-	RequestParameterOrderId   = "order_id"
-	RequestParameterZipUsa    = "zip_usa"
-	RequestParameterReceiptId = "receipt_id"
+	RequestParameterId              = "id"
+	RequestParameterOrderId         = "order_id"
+	RequestParameterZipUsa          = "zip_usa"
+	RequestParameterPostalCode      = "postal_code"
+	RequestParameterPhone           = "phone"
+	RequestParameterReceiptId       = "receipt_id"
+	RequestParameterCode            = "code"
+	RequestParameterBin             = "bin"
+	RequestParameterMerchantOrderId = "merchant_order_id"
 
 	QueryParameterNameUtmMedium   = "utm_medium"
 	QueryParameterNameUtmCampaign = "utm_campaign"
@@ -26,8 +31,16 @@ const (
 	HeaderUserAgent           = "User-Agent"
 	HeaderXApiSignatureHeader = "X-API-SIGNATURE"
 	HeaderReferer             = "referer"
+	HeaderXIpCountry          = "X-Ip-Country"
+	HeaderXCaptchaToken       = "X-Captcha-Token"
+	HeaderRetryAfter          = "Retry-After"
+	HeaderXApiKeyId           = "X-Api-Key-Id"
+	HeaderXApiKey             = "X-Api-Key"
+	HeaderXApiTimestamp       = "X-Api-Timestamp"
+	HeaderXApiHmac            = "X-Api-Hmac"
 
 	CustomerTokenCookiesName = "_ps_ctkn"
+	AttributionCookiesName   = "_ps_attr"
 
 	ErrorFieldService = "service"
 	ErrorFieldMethod  = "method"
@@ -37,6 +50,12 @@ const (
 
 	ValidationParameterOrderId   = "OrderId"
 	ValidationParameterOrderUuid = "OrderUuid"
+	ValidationParameterReason    = "Reason"
+	ValidationParameterComment   = "Comment"
+	ValidationParameterPromoCode = "PromoCode"
+	ValidationParameterBin       = "Bin"
+	ValidationParameterCompany   = "Company"
+	ValidationParameterVatId     = "VatId"
 )
 
 func LogSrvCallFailedGRPC(log logger.Logger, err error, name, method string, req interface{}) {
@@ -29,9 +29,63 @@ var (
 	ErrorRequestParamsIncorrect        = NewManagementApiResponseError("co000006", "incorrect request parameters")
 	ErrorRequestDataInvalid            = NewManagementApiResponseError("co000007", "request data invalid")
 	ErrorMessageIncorrectZip           = NewManagementApiResponseError("co000008", "incorrect zip code")
+	ErrorMessageIncorrectPostalCode    = NewManagementApiResponseError("co000028", "incorrect postal code")
+	ErrorMessageIncorrectPhone         = NewManagementApiResponseError("co000029", "incorrect phone number")
+	ErrorTooManyRequests               = NewManagementApiResponseError("co000009", "too many requests. try request later")
+	ErrorIncorrectRefundReason         = NewManagementApiResponseError("co000010", "incorrect refund reason")
+	ErrorIncorrectComment              = NewManagementApiResponseError("co000011", "comment contains forbidden characters or too long")
+	ErrorIncorrectPromoCode            = NewManagementApiResponseError("co000012", "incorrect promo code")
+	ErrorPromoCodeAttemptsExceeded     = NewManagementApiResponseError("co000013", "promo code attempts limit exceeded. try request later")
+	ErrorQuoteTokenInvalid             = NewManagementApiResponseError("co000014", "price quote token is invalid")
+	ErrorQuoteTokenExpired             = NewManagementApiResponseError("co000015", "price quote token is expired")
+	ErrorQrCodeSizeTooLarge            = NewManagementApiResponseError("co000016", "qr code size is too large")
+	ErrorApplePayNotConfigured         = NewManagementApiResponseError("co000017", "apple pay isn't available")
+	ErrorApplePayValidationUrlInvalid  = NewManagementApiResponseError("co000018", "apple pay validation url is invalid")
+	ErrorApplePaySessionFailed         = NewManagementApiResponseError("co000019", "apple pay session can't be created")
+	ErrorIncorrectWalletToken          = NewManagementApiResponseError("co000020", "wallet payment token is invalid")
+	ErrorIncorrectCardNumber           = NewManagementApiResponseError("co000021", "incorrect bank card number")
+	ErrorIncorrectCardExpiry           = NewManagementApiResponseError("co000022", "bank card is expired or expiration date is incorrect")
+	ErrorIncorrectCardCvv              = NewManagementApiResponseError("co000023", "incorrect bank card cvv")
+	ErrorIncorrectEmail                = NewManagementApiResponseError("co000024", "incorrect email")
+	ErrorPaymentFieldNotAllowed        = NewManagementApiResponseError("co000025", "field isn't allowed in the payment request")
+	ErrorIncorrectBin                  = NewManagementApiResponseError("co000026", "incorrect bank card bin")
+	ErrorBinNotFound                   = NewManagementApiResponseError("co000027", "bank card bin not found")
+	ErrorIncorrectVatId                = NewManagementApiResponseError("co000030", "incorrect vat id")
+	ErrorVatIdCountryMismatch          = NewManagementApiResponseError("co000031", "vat id doesn't match billing address country")
+	ErrorVatIdNotRegistered            = NewManagementApiResponseError("co000032", "vat id isn't registered")
+	ErrorVatIdCheckUnavailable         = NewManagementApiResponseError("co000033", "vat id can't be checked. try request later")
+	ErrorIncorrectCompanyName          = NewManagementApiResponseError("co000034", "incorrect company name")
+	ErrorDeviceDataTooLarge            = NewManagementApiResponseError("co000035", "device data is too large")
+	ErrorIncorrectDeviceData           = NewManagementApiResponseError("co000036", "incorrect device data")
+	ErrorCaptchaRequired               = NewManagementApiResponseError("co000037", "captcha challenge required")
+	ErrorCaptchaInvalid                = NewManagementApiResponseError("co000038", "captcha token is invalid")
+	ErrorCaptchaUnavailable            = NewManagementApiResponseError("co000039", "captcha can't be verified. try request later")
+	ErrorEventsTooLarge                = NewManagementApiResponseError("co000040", "events batch is too large")
+	ErrorIncorrectEvents               = NewManagementApiResponseError("co000041", "incorrect events")
+	ErrorMaintenance                   = NewManagementApiResponseError("co000042", "service is under maintenance. try request later")
+	ErrorMerchantUnauthorized          = NewManagementApiResponseError("co000043", "api key or request signature is invalid")
+	ErrorMerchantRequestExpired        = NewManagementApiResponseError("co000044", "request timestamp is incorrect or expired")
+	ErrorMerchantProjectForbidden      = NewManagementApiResponseError("co000045", "api key isn't allowed to access the project")
+	ErrorMerchantAuthUnavailable       = NewManagementApiResponseError("co000046", "api key can't be verified. try request later")
+	ErrorQuotesDisabled                = NewManagementApiResponseError("co000047", "price quotes are disabled")
+	ErrorOriginForbidden               = NewManagementApiResponseError("co000048", "request origin isn't allowed for the project")
+	ErrorRequestBodyTooLarge           = NewManagementApiResponseError("co000049", "request body is too large")
+	ErrorMerchantRequestReplayed       = NewManagementApiResponseError("co000050", "signed request was already received")
+
+	ValidationTagErrors = map[string]grpc.ResponseErrorMessage{
+		RequestParameterZipUsa:     ErrorMessageIncorrectZip,
+		RequestParameterPostalCode: ErrorMessageIncorrectPostalCode,
+		RequestParameterPhone:      ErrorMessageIncorrectPhone,
+	}
 
 	ValidationErrors = map[string]grpc.ResponseErrorMessage{
 		ValidationParameterOrderId:   ErrorIncorrectOrderId,
 		ValidationParameterOrderUuid: ErrorIncorrectOrderId,
+		ValidationParameterReason:    ErrorIncorrectRefundReason,
+		ValidationParameterComment:   ErrorIncorrectComment,
+		ValidationParameterPromoCode: ErrorIncorrectPromoCode,
+		ValidationParameterBin:       ErrorIncorrectBin,
+		ValidationParameterCompany:   ErrorIncorrectCompanyName,
+		ValidationParameterVatId:     ErrorIncorrectVatId,
 	}
 )
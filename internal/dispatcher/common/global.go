@@ -0,0 +1,111 @@
+package common
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	errCookieDomainEmpty        = errors.New("cookie domain is empty")
+	errOrderInlineFormUrlMask   = errors.New("order inline form url mask must be an absolute url")
+	errAllowOriginEmptyOrigin   = errors.New("allow origin contains empty origin")
+	errApiV1SunsetDate          = errors.New("api v1 sunset date must be like 2021-06-30")
+	errSigningSecretEmpty       = errors.New("signing secret is required if price quotes or attribution are enabled")
+	errGlobalConfigLoadRequired = errors.New("global config load function is required")
+)
+
+// GlobalConfig keeps snapshot of the global configuration, snapshot is replaced atomically on reload
+// so requests being processed complete with the configuration they started with
+type GlobalConfig struct {
+	mx    sync.Mutex
+	value atomic.Value
+	load  func(cfg *Config) error
+}
+
+// NewGlobalConfig loads and validates initial configuration
+func NewGlobalConfig(load func(cfg *Config) error) (*GlobalConfig, error) {
+	if load == nil {
+		return nil, errGlobalConfigLoadRequired
+	}
+
+	g := &GlobalConfig{load: load}
+
+	if err := g.Reload(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// Get returns current configuration, returned snapshot must not be changed
+func (g *GlobalConfig) Get() *Config {
+	return g.value.Load().(*Config)
+}
+
+// Reload loads configuration again, previous configuration is kept if new one can't be loaded or is invalid
+func (g *GlobalConfig) Reload() error {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	cfg := &Config{}
+
+	if err := g.load(cfg); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	g.value.Store(cfg)
+	return nil
+}
+
+// Validate checks settings which break request processing if they are wrong
+func (c *Config) Validate() error {
+	if c.CookieDomain == "" {
+		return errCookieDomainEmpty
+	}
+
+	u, err := url.Parse(c.OrderInlineFormUrlMask)
+
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errOrderInlineFormUrlMask
+	}
+
+	if c.SigningSecret == "" && (c.QuoteTokenLifetimeMinutes > 0 || c.AttributionParameters != "") {
+		return errSigningSecretEmpty
+	}
+
+	if c.ApiV1SunsetDate != "" {
+		if _, err := time.Parse(SunsetDateLayout, c.ApiV1SunsetDate); err != nil {
+			return errApiV1SunsetDate
+		}
+	}
+
+	if c.AllowOrigin == "" {
+		return nil
+	}
+
+	for _, origin := range strings.Split(c.AllowOrigin, ",") {
+		if strings.TrimSpace(origin) == "" {
+			return errAllowOriginEmptyOrigin
+		}
+	}
+
+	return nil
+}
+
+// ApiV1Sunset returns date when API v1 is removed, false is returned if API v1 isn't deprecated
+func (c *Config) ApiV1Sunset() (time.Time, bool) {
+	if c.ApiV1SunsetDate == "" {
+		return time.Time{}, false
+	}
+
+	sunset, err := time.Parse(SunsetDateLayout, c.ApiV1SunsetDate)
+	return sunset, err == nil
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/pkg/card"
+	"gopkg.in/go-playground/validator.v9"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	PaymentFieldEmail = "email"
+	PaymentFieldPan   = "pan"
+	PaymentFieldCvv   = "cvv"
+	PaymentFieldMonth = "month"
+	PaymentFieldYear  = "year"
+)
+
+// PaymentFieldError describes validation error of the single payment request field
+type PaymentFieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PaymentDataValidationError is returned to the payment form when payment request data is invalid
+type PaymentDataValidationError struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Fields  []*PaymentFieldError `json:"fields"`
+}
+
+// PaymentDataValidator checks payment request data before it's sent to billing
+type PaymentDataValidator struct {
+	allowed  atomic.Value
+	validate *validator.Validate
+	now      func() time.Time
+}
+
+// NewPaymentDataValidator returns validator accepting comma separated list of fields, any field is accepted if list is empty
+func NewPaymentDataValidator(allowedFields string, validate *validator.Validate) *PaymentDataValidator {
+	v := &PaymentDataValidator{
+		validate: validate,
+		now:      time.Now,
+	}
+	v.SetAllowedFields(allowedFields)
+	return v
+}
+
+// SetAllowedFields replaces comma separated list of accepted fields
+func (v *PaymentDataValidator) SetAllowedFields(allowedFields string) {
+	allowed := make(map[string]bool)
+
+	for _, field := range strings.Split(allowedFields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			allowed[field] = true
+		}
+	}
+
+	v.allowed.Store(allowed)
+}
+
+// Validate checks payment request data and normalizes bank card number, nil is returned if data is valid
+func (v *PaymentDataValidator) Validate(data map[string]string) *PaymentDataValidationError {
+	var fields []*PaymentFieldError
+
+	addError := func(field string, err grpc.ResponseErrorMessage) {
+		fields = append(fields, &PaymentFieldError{Field: field, Code: err.Code, Message: err.Message})
+	}
+
+	if allowed := v.allowed.Load().(map[string]bool); len(allowed) > 0 {
+		var unknown []string
+
+		for field := range data {
+			if !allowed[field] {
+				unknown = append(unknown, field)
+			}
+		}
+
+		sort.Strings(unknown)
+
+		for _, field := range unknown {
+			addError(field, ErrorPaymentFieldNotAllowed)
+		}
+	}
+
+	if email := data[PaymentFieldEmail]; email != "" && v.validate.Var(email, "email,max=255") != nil {
+		addError(PaymentFieldEmail, ErrorIncorrectEmail)
+	}
+
+	brand := card.BrandUnknown
+	_, isCard := data[PaymentFieldPan]
+
+	if isCard {
+		pan := card.Normalize(data[PaymentFieldPan])
+
+		if card.ValidNumber(pan) {
+			data[PaymentFieldPan] = pan
+			brand = card.Brand(pan)
+		} else {
+			addError(PaymentFieldPan, ErrorIncorrectCardNumber)
+		}
+	}
+
+	month, year := data[PaymentFieldMonth], data[PaymentFieldYear]
+
+	if (isCard || month != "" || year != "") && !card.ValidExpiry(month, year, v.now()) {
+		addError(PaymentFieldMonth, ErrorIncorrectCardExpiry)
+	}
+
+	// cvv is sent without card number for the saved cards
+	if cvv := data[PaymentFieldCvv]; (isCard || cvv != "") && !card.ValidCvv(brand, cvv) {
+		addError(PaymentFieldCvv, ErrorIncorrectCardCvv)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &PaymentDataValidationError{
+		Code:    ErrorValidationFailed.Code,
+		Message: ErrorValidationFailed.Message,
+		Fields:  fields,
+	}
+}
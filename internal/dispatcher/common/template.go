@@ -5,6 +5,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"html/template"
 	"io"
+	"sync/atomic"
 )
 
 var FuncMap = template.FuncMap{
@@ -16,15 +17,22 @@ var FuncMap = template.FuncMap{
 
 // Template
 type Template struct {
-	tpl *template.Template
+	tpl atomic.Value
 }
 
 // Render
 func (t *Template) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
-	return t.tpl.ExecuteTemplate(w, name, data)
+	return t.tpl.Load().(*template.Template).ExecuteTemplate(w, name, data)
+}
+
+// Set replaces templates, requests being rendered complete with the previous ones
+func (t *Template) Set(tpl *template.Template) {
+	t.tpl.Store(tpl)
 }
 
 // NewTemplate
 func NewTemplate(tpl *template.Template) *Template {
-	return &Template{tpl: tpl}
+	t := &Template{}
+	t.Set(tpl)
+	return t
 }
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ApiV1 = "v1"
+	ApiV2 = "v2"
+
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+
+	// SunsetDateLayout is a layout of the sunset dates in the configuration
+	SunsetDateLayout = "2006-01-02"
+)
+
+// GetApiVersion returns API version of the route path, empty string is returned for routes outside of the API
+func GetApiVersion(path string) string {
+	for version, prefix := range map[string]string{ApiV1: NoAuthGroupPath, ApiV2: ApiV2GroupPath} {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return version
+		}
+	}
+
+	return ""
+}
+
+// SetDeprecationHeaders marks response as deprecated, sunset is a date when the route is removed and isn't sent if zero
+func SetDeprecationHeaders(ctx echo.Context, sunset time.Time) {
+	header := ctx.Response().Header()
+	header.Set(HeaderDeprecation, "true")
+
+	if !sunset.IsZero() {
+		header.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+	}
+}
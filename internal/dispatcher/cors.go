@@ -0,0 +1,186 @@
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/origins"
+	"net/http"
+	"strings"
+)
+
+const (
+	corsAllowMethods  = "GET,HEAD,PUT,PATCH,POST,DELETE"
+	corsAllowHeaders  = "content-type,x-captcha-token"
+	corsExposeHeaders = "content-type,set-cookie,cookie,retry-after,deprecation,sunset"
+)
+
+var (
+	errOriginsLookupFailed = errors.New("project origins lookup in billing failed")
+
+	// corsBodyRoutes are routes which project is known from the request body only. Preflight of these routes is
+	// allowed for any origin and the origin is checked on the actual request instead.
+	corsBodyRoutes = map[string]bool{
+		common.NoAuthGroupPath + "/order":          true,
+		common.NoAuthGroupPath + "/order/recreate": true,
+		common.NoAuthGroupPath + "/quote":          true,
+		common.ApiV2GroupPath + "/order":           true,
+		common.ApiV2GroupPath + "/order/recreate":  true,
+		common.ApiV2GroupPath + "/quote":           true,
+	}
+)
+
+type corsOrigins struct {
+	allowOrigin string
+	patterns    []string
+}
+
+// corsRequestBody contains fields which identify project of the request body
+type corsRequestBody struct {
+	Project string `json:"project"`
+	Order   string `json:"order"`
+	OrderId string `json:"order_id"`
+}
+
+// CORSMiddleware allows cross-origin requests from origins of the global configuration and origins of the project
+// which the request belongs to. Project is resolved by the order id path parameter or by the request body.
+// Preflight requests have no body, so preflight of routes addressed by the body only is allowed and the actual
+// request from a disallowed origin is rejected.
+// Responses vary by origin because allowed origin is sent back instead of the wildcard.
+func (d *Dispatcher) CORSMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := c.Response().Header()
+		origin := c.Request().Header.Get(echo.HeaderOrigin)
+		preflight := c.Request().Method == http.MethodOptions
+
+		header.Add(echo.HeaderVary, echo.HeaderOrigin)
+
+		if preflight {
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+		}
+
+		bodyRoute := origin != "" && corsBodyRoutes[c.Path()] && d.globalCfg.Get().CorsProjectOrigins
+
+		if !(preflight && bodyRoute) && !d.isOriginAllowed(c, origin, preflight) {
+			if preflight {
+				return c.NoContent(http.StatusNoContent)
+			}
+			if bodyRoute {
+				return echo.NewHTTPError(http.StatusForbidden, common.ErrorOriginForbidden)
+			}
+			return next(c)
+		}
+
+		header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+		header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+
+		if !preflight {
+			header.Set(echo.HeaderAccessControlExposeHeaders, corsExposeHeaders)
+			return next(c)
+		}
+
+		header.Set(echo.HeaderAccessControlAllowMethods, corsAllowMethods)
+		header.Set(echo.HeaderAccessControlAllowHeaders, corsAllowHeaders)
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// isOriginAllowed checks global allowed origins first, project origins are requested only if global ones don't match
+func (d *Dispatcher) isOriginAllowed(c echo.Context, origin string, preflight bool) bool {
+	if origin == "" {
+		return false
+	}
+
+	cfg := d.globalCfg.Get()
+	global, ok := d.cors.Load().(*corsOrigins)
+
+	if !ok || global.allowOrigin != cfg.AllowOrigin {
+		global = &corsOrigins{allowOrigin: cfg.AllowOrigin, patterns: origins.Split(cfg.AllowOrigin)}
+		d.cors.Store(global)
+	}
+
+	if origins.Allowed(global.patterns, origin) {
+		return true
+	}
+
+	if !cfg.CorsProjectOrigins {
+		return false
+	}
+
+	projectId, orderId := getCorsRequestIds(c, preflight)
+
+	if projectId == "" && orderId == "" {
+		return false
+	}
+
+	project, err := d.origins.Lookup(c.Request().Context(), projectId, orderId)
+
+	if err != nil {
+		return false
+	}
+
+	return origins.Allowed(project.Origins, origin)
+}
+
+func (d *Dispatcher) lookupBillingOrigins(ctx context.Context, projectId, orderId string) (*origins.Project, error) {
+	req := &billingext.GetProjectAllowedOriginsRequest{ProjectId: projectId, OrderId: orderId}
+	res, err := d.appSet.Services.BillingExt.GetProjectAllowedOrigins(ctx, req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(d.L(), err, pkg.ServiceName, "GetProjectAllowedOrigins", req)
+		return nil, err
+	}
+
+	if res.Status == http.StatusNotFound || (res.Status == pkg.ResponseStatusOk && res.Item == nil) {
+		return nil, origins.ErrNotFound
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		d.L().Error(errOriginsLookupFailed.Error(), logger.PairArgs("status", res.Status, "project_id", projectId, "order_id", orderId))
+		return nil, errOriginsLookupFailed
+	}
+
+	return &origins.Project{Id: res.Item.ProjectId, Origins: res.Item.Origins}, nil
+}
+
+// getCorsRequestIds returns project or order id of the request, malformed ids are ignored to not reach billing
+func getCorsRequestIds(c echo.Context, preflight bool) (projectId, orderId string) {
+	if id := c.Param(common.RequestParameterOrderId); isOrderId(id) {
+		return "", id
+	}
+
+	if preflight || !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return "", ""
+	}
+
+	body := &corsRequestBody{}
+
+	if err := json.Unmarshal(common.ExtractRawBodyContext(c), body); err != nil {
+		return "", ""
+	}
+
+	if bson.IsObjectIdHex(body.Project) {
+		return body.Project, ""
+	}
+
+	for _, id := range []string{body.OrderId, body.Order} {
+		if isOrderId(id) {
+			return "", id
+		}
+	}
+
+	return "", ""
+}
+
+func isOrderId(id string) bool {
+	_, err := uuid.Parse(id)
+	return id != "" && err == nil
+}
@@ -0,0 +1,243 @@
+package dispatcher_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	corsGlobalOrigin  = "https://checkout.pay.super.com"
+	corsProjectOrigin = "https://shop.com"
+
+	corsOrderPath       = "/order"
+	corsQuotePath       = "/quote"
+	corsOrderStatusPath = "/orders/:order_id/status"
+)
+
+// corsRoute mounts routes addressed by the order id path parameter and by the request body
+type corsRoute struct{}
+
+func (r *corsRoute) Route(groups *common.Groups) {
+	groups.Shared(func(group *echo.Group) {
+		group.GET(corsOrderStatusPath, r.ok)
+		group.POST(corsOrderPath, r.ok)
+		group.POST(corsQuotePath, r.ok)
+	})
+}
+
+func (r *corsRoute) ok(ctx echo.Context) error {
+	return ctx.NoContent(http.StatusOK)
+}
+
+type CorsTestSuite struct {
+	suite.Suite
+	caller *test.EchoReqResCaller
+	ext    *extMock.Service
+}
+
+func Test_Cors(t *testing.T) {
+	suite.Run(t, new(CorsTestSuite))
+}
+
+func (suite *CorsTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	global := settings["dispatcher"].(map[string]interface{})["global"].(map[string]interface{})
+	global["allowOrigin"] = corsGlobalOrigin
+	global["corsProjectOrigins"] = true
+	global["corsProjectOriginsCacheSize"] = 10
+	global["corsProjectOriginsCacheTtlMinutes"] = 10
+
+	suite.ext = &extMock.Service{}
+	srv := common.Services{BillingExt: suite.ext}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		return common.Handlers{
+			&corsRoute{},
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *CorsTestSuite) onOrderOrigins(orderId string) {
+	suite.ext.On("GetProjectAllowedOrigins", mock2.Anything, &billingext.GetProjectAllowedOriginsRequest{OrderId: orderId}).
+		Return(&billingext.GetProjectAllowedOriginsResponse{
+			Status: pkg.ResponseStatusOk,
+			Item:   &billingext.ProjectAllowedOrigins{ProjectId: bson.NewObjectId().Hex(), Origins: []string{corsProjectOrigin}},
+		}, nil)
+}
+
+func (suite *CorsTestSuite) onProjectOrigins(projectId string) {
+	suite.ext.On("GetProjectAllowedOrigins", mock2.Anything, &billingext.GetProjectAllowedOriginsRequest{ProjectId: projectId}).
+		Return(&billingext.GetProjectAllowedOriginsResponse{
+			Status: pkg.ResponseStatusOk,
+			Item:   &billingext.ProjectAllowedOrigins{ProjectId: projectId, Origins: []string{corsProjectOrigin}},
+		}, nil)
+}
+
+func (suite *CorsTestSuite) executeOrderStatus(method, orderId, origin string) *httptest.ResponseRecorder {
+	res, _ := suite.caller.Builder().
+		Method(method).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath+corsOrderStatusPath).
+		AddHeader(echo.HeaderOrigin, origin).
+		AddHeader(echo.HeaderAccessControlRequestMethod, http.MethodGet).
+		Exec(suite.T())
+
+	return res
+}
+
+func (suite *CorsTestSuite) executeCreateOrder(projectId, origin string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath+corsOrderPath).
+		Init(test.ReqInitJSON()).
+		AddHeader(echo.HeaderOrigin, origin).
+		BodyString(fmt.Sprintf(`{"project": "%s"}`, projectId)).
+		Exec(suite.T())
+}
+
+func (suite *CorsTestSuite) Test_GlobalOrigin_Allowed() {
+	res := suite.executeOrderStatus(http.MethodGet, uuid.New().String(), corsGlobalOrigin)
+
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), corsGlobalOrigin, res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(suite.T(), "true", res.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	assert.Contains(suite.T(), res.Header()[echo.HeaderVary], echo.HeaderOrigin)
+	suite.ext.AssertNotCalled(suite.T(), "GetProjectAllowedOrigins", mock2.Anything, mock2.Anything)
+}
+
+func (suite *CorsTestSuite) Test_ProjectOrigin_ByOrderId() {
+	orderId := uuid.New().String()
+	suite.onOrderOrigins(orderId)
+
+	for i := 0; i < 2; i++ {
+		res := suite.executeOrderStatus(http.MethodGet, orderId, corsProjectOrigin)
+
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+		assert.Equal(suite.T(), corsProjectOrigin, res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Contains(suite.T(), res.Header()[echo.HeaderVary], echo.HeaderOrigin)
+	}
+
+	suite.ext.AssertNumberOfCalls(suite.T(), "GetProjectAllowedOrigins", 1)
+}
+
+func (suite *CorsTestSuite) Test_ProjectOrigin_Preflight() {
+	orderId := uuid.New().String()
+	suite.onOrderOrigins(orderId)
+
+	res := suite.executeOrderStatus(http.MethodOptions, orderId, corsProjectOrigin)
+
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+	assert.Equal(suite.T(), corsProjectOrigin, res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.NotEmpty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowMethods))
+	assert.Contains(suite.T(), res.Header()[echo.HeaderVary], echo.HeaderOrigin)
+	assert.Contains(suite.T(), res.Header()[echo.HeaderVary], echo.HeaderAccessControlRequestMethod)
+}
+
+func (suite *CorsTestSuite) Test_UnknownOrigin_Denied() {
+	orderId := uuid.New().String()
+	suite.onOrderOrigins(orderId)
+
+	res := suite.executeOrderStatus(http.MethodOptions, orderId, "https://evil.com")
+
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Empty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowMethods))
+	assert.Contains(suite.T(), res.Header()[echo.HeaderVary], echo.HeaderOrigin)
+
+	res = suite.executeOrderStatus(http.MethodGet, orderId, "https://evil.com")
+
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func (suite *CorsTestSuite) Test_ProjectOrigin_ByRequestBody() {
+	projectId := bson.NewObjectId().Hex()
+	suite.onProjectOrigins(projectId)
+
+	res, err := suite.executeCreateOrder(projectId, corsProjectOrigin)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), corsProjectOrigin, res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func (suite *CorsTestSuite) Test_ProjectOrigin_BodyRoutePreflight() {
+	for _, path := range []string{
+		common.NoAuthGroupPath + corsOrderPath,
+		common.ApiV2GroupPath + corsOrderPath,
+		common.NoAuthGroupPath + corsQuotePath,
+	} {
+		res, _ := suite.caller.Builder().
+			Method(http.MethodOptions).
+			Path(path).
+			AddHeader(echo.HeaderOrigin, corsProjectOrigin).
+			AddHeader(echo.HeaderAccessControlRequestMethod, http.MethodPost).
+			AddHeader(echo.HeaderAccessControlRequestHeaders, echo.HeaderContentType).
+			Exec(suite.T())
+
+		assert.Equal(suite.T(), http.StatusNoContent, res.Code, path)
+		assert.Equal(suite.T(), corsProjectOrigin, res.Header().Get(echo.HeaderAccessControlAllowOrigin), path)
+		assert.Contains(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowMethods), http.MethodPost, path)
+		assert.Contains(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowHeaders), "content-type", path)
+	}
+
+	// project is unknown until the actual request
+	suite.ext.AssertNotCalled(suite.T(), "GetProjectAllowedOrigins", mock2.Anything, mock2.Anything)
+}
+
+func (suite *CorsTestSuite) Test_BodyRoute_UnknownOrigin_Forbidden() {
+	projectId := bson.NewObjectId().Hex()
+	suite.onProjectOrigins(projectId)
+
+	res, err := suite.executeCreateOrder(projectId, "https://evil.com")
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusForbidden, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorOriginForbidden, httpErr.Message)
+}
+
+func (suite *CorsTestSuite) Test_ProjectOrigin_BillingError() {
+	orderId := uuid.New().String()
+	suite.ext.On("GetProjectAllowedOrigins", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("billing unavailable"))
+
+	for i := 0; i < 2; i++ {
+		res := suite.executeOrderStatus(http.MethodGet, orderId, corsProjectOrigin)
+
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+		assert.Empty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	}
+
+	// failed lookups aren't cached
+	suite.ext.AssertNumberOfCalls(suite.T(), "GetProjectAllowedOrigins", 2)
+}
+
+func (suite *CorsTestSuite) Test_MalformedOrderId_NotRequested() {
+	res := suite.executeOrderStatus(http.MethodGet, "not-an-order", corsProjectOrigin)
+
+	assert.Empty(suite.T(), res.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	suite.ext.AssertNotCalled(suite.T(), "GetProjectAllowedOrigins", mock2.Anything, mock2.Anything)
+}
@@ -9,12 +9,23 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/geoip"
+	httpEcho "github.com/paysuper/paysuper-checkout/pkg/http"
+	"github.com/paysuper/paysuper-checkout/pkg/merchantauth"
 	"github.com/paysuper/paysuper-checkout/pkg/micro"
+	"github.com/paysuper/paysuper-checkout/pkg/origins"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
 	"html/template"
 	"io/ioutil"
 	"net/http"
 	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	shutdownTimeout = 10 * time.Second
 )
 
 // Dispatcher
@@ -23,45 +34,67 @@ type Dispatcher struct {
 	cfg    Config
 	appSet AppSet
 	provider.LMT
-	globalCfg *common.Config
+	globalCfg *common.GlobalConfig
 	ms        *micro.Micro
+	geoIp     geoip.Resolver
+	renderer  *common.Template
+	origins   *origins.Cache
+	// merchantKeys, merchantSignatures, merchantLimiter and merchantIpLimiter authenticate and limit requests
+	// of the merchant group
+	merchantKeys       *merchantauth.Cache
+	merchantSignatures *merchantauth.Replays
+	merchantLimiter    *ratelimit.Limiter
+	merchantIpLimiter  *ratelimit.Limiter
+	// groups keeps routes groups with body limits of the routes
+	groups *common.Groups
+	// cors keeps *corsOrigins parsed from the last seen global allowed origins
+	cors atomic.Value
+	// maintenance is 1 when maintenance mode is enabled
+	maintenance int32
 }
 
 // dispatch
 func (d *Dispatcher) Dispatch(echoHttp *echo.Echo) error {
-	t, e := template.New("").Funcs(common.FuncMap).ParseGlob(d.cfg.WorkDir + "/assets/web/template/*.html")
+	t, e := d.parseTemplates()
 	if e != nil {
 		return e
 	}
-	echoHttp.Renderer = common.NewTemplate(t)
+	d.renderer.Set(t)
+	echoHttp.Renderer = d.renderer
 	echoHttp.Binder = &common.Binder{}
 	// Called after routes
 	echoHttp.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Output: logger.NewLevelWriter(d.L(), logger.LevelInfo),
 		Format: `{"id":"${id}","remote_ip":"${remote_ip}",` +
 			`"host":"${host}","method":"${method}","uri":"${uri}","user_agent":"${user_agent}",` +
+			`"ip_country":"${header:X-Ip-Country}",` +
 			`"status":${status},"error":"${error}","latency":${latency},"latency_human":"${latency_human}"` +
 			`,"bytes_in":${bytes_in},"bytes_out":${bytes_out}}`,
 	})) // 3
 
-	allowOrigins := strings.Split(d.globalCfg.AllowOrigin, ",")
-
 	echoHttp.Use(d.RecoverMiddleware()) // 3
-	echoHttp.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins:     allowOrigins,
-		AllowCredentials: true,
-		AllowHeaders:     []string{"content-type"},
-		ExposeHeaders:    []string{"content-type", "set-cookie", "cookie"},
-	})) // 2
 	// Called before routes
-	echoHttp.Use(d.RawBodyPreMiddleware) // 1
+	echoHttp.Use(d.RawBodyPreMiddleware)   // 1
+	echoHttp.Use(d.CORSMiddleware)         // 1
+	echoHttp.Use(d.DeprecationMiddleware)  // 1
+	echoHttp.Use(d.GeoIpMiddleware)        // 1
+	echoHttp.Use(d.MaintenanceMiddleware)  // 1
+	echoHttp.Use(d.MerchantAuthMiddleware) // 1
 	// init group routes
 	grp := &common.Groups{
-		Common: echoHttp.Group(common.NoAuthGroupPath),
+		V1:       echoHttp.Group(common.NoAuthGroupPath),
+		V2:       echoHttp.Group(common.ApiV2GroupPath),
+		Merchant: echoHttp.Group(common.MerchantGroupPath),
+		Root:     echoHttp.Group(""),
 	}
-	// init routes
+	d.groups = grp
+	// init routes, handlers are reloaded after the global configuration which reload is registered by ProviderGlobalCfg
 	for _, handler := range d.appSet.Handlers {
 		handler.Route(grp)
+
+		if r, ok := handler.(common.Reloader); ok {
+			d.cfg.OnReload(r.Reload)
+		}
 	}
 	if d.cfg.PathRouteDump != "" {
 		d.dumpRoutesToFile(echoHttp)
@@ -69,6 +102,74 @@ func (d *Dispatcher) Dispatch(echoHttp *echo.Echo) error {
 	return nil
 }
 
+// Start runs background work of the handlers, the work is stopped when ctx is cancelled or by Shutdown
+func (d *Dispatcher) Start(ctx context.Context) error {
+	for _, handler := range d.appSet.Handlers {
+		if s, ok := handler.(httpEcho.Starter); ok {
+			if e := s.Start(ctx); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// Shutdown finishes background work of the handlers after the http server is stopped
+func (d *Dispatcher) Shutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	for _, handler := range d.appSet.Handlers {
+		if s, ok := handler.(httpEcho.Shutdowner); ok {
+			s.Shutdown(ctx)
+		}
+	}
+}
+
+// InvalidateCaches purges in-memory caches of the dispatcher and handlers
+func (d *Dispatcher) InvalidateCaches(ctx context.Context) []string {
+	d.origins.Purge()
+	d.merchantKeys.Purge()
+	caches := []string{"cors_origins", "merchant_keys"}
+
+	for _, handler := range d.appSet.Handlers {
+		if i, ok := handler.(common.CacheInvalidator); ok {
+			caches = append(caches, i.InvalidateCaches(ctx)...)
+		}
+	}
+
+	return caches
+}
+
+// ReloadTemplates parses html templates again, previous templates are kept if parsing fails
+func (d *Dispatcher) ReloadTemplates() error {
+	t, e := d.parseTemplates()
+	if e != nil {
+		return e
+	}
+
+	d.renderer.Set(t)
+	return nil
+}
+
+// SetMaintenance enables or disables maintenance mode
+func (d *Dispatcher) SetMaintenance(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&d.maintenance, v)
+}
+
+// Maintenance returns true if maintenance mode is enabled
+func (d *Dispatcher) Maintenance() bool {
+	return atomic.LoadInt32(&d.maintenance) == 1
+}
+
+func (d *Dispatcher) parseTemplates() (*template.Template, error) {
+	return template.New("").Funcs(common.FuncMap).ParseGlob(d.cfg.WorkDir + "/assets/web/template/*.html")
+}
+
 func (d *Dispatcher) dumpRoutesToFile(echoHttp *echo.Echo) {
 
 	var list []string
@@ -80,7 +181,11 @@ func (d *Dispatcher) dumpRoutesToFile(echoHttp *echo.Echo) {
 		if strings.Contains(r.Name, "v4.glob..func1") {
 			continue
 		}
-		list = append(list, r.Path+" "+r.Method+" "+strRepl.Replace(r.Name))
+		version := common.GetApiVersion(r.Path)
+		if version == "" {
+			version = "-"
+		}
+		list = append(list, r.Path+" "+r.Method+" "+version+" "+strRepl.Replace(r.Name))
 	}
 
 	sort.Strings(list)
@@ -91,6 +196,7 @@ func (d *Dispatcher) dumpRoutesToFile(echoHttp *echo.Echo) {
 		Cells: []*simpletable.Cell{
 			{Align: simpletable.AlignCenter, Text: "Path"},
 			{Align: simpletable.AlignCenter, Text: "Method"},
+			{Align: simpletable.AlignCenter, Text: "Version"},
 			{Align: simpletable.AlignCenter, Text: "Handler"},
 		},
 	}
@@ -101,6 +207,7 @@ func (d *Dispatcher) dumpRoutesToFile(echoHttp *echo.Echo) {
 			{Align: simpletable.AlignLeft, Text: row[0]},
 			{Align: simpletable.AlignLeft, Text: row[1]},
 			{Align: simpletable.AlignLeft, Text: row[2]},
+			{Align: simpletable.AlignLeft, Text: row[3]},
 		}
 		table.Body.Cells = append(table.Body.Cells, r)
 	}
@@ -128,7 +235,9 @@ type Config struct {
 	Debug         bool `fallback:"shared.debug"`
 	WorkDir       string
 	PathRouteDump string
-	invoker       *invoker.Invoker
+	// Maintenance enables maintenance mode, changes are applied on reload and override mode set by management service
+	Maintenance bool
+	invoker     *invoker.Invoker
 }
 
 // OnReload
@@ -148,14 +257,29 @@ type AppSet struct {
 }
 
 // New
-func New(ctx context.Context, set provider.AwareSet, appSet AppSet, cfg *Config, globalCfg *common.Config, ms *micro.Micro) *Dispatcher {
+func New(ctx context.Context, set provider.AwareSet, appSet AppSet, cfg *Config, globalCfg *common.GlobalConfig, ms *micro.Micro) *Dispatcher {
 	set.Logger = set.Logger.WithFields(logger.Fields{"service": common.Prefix})
-	return &Dispatcher{
+	d := &Dispatcher{
 		ctx:       ctx,
 		cfg:       *cfg,
 		appSet:    appSet,
 		LMT:       &set,
 		globalCfg: globalCfg,
 		ms:        ms,
+		renderer:  &common.Template{},
 	}
+	d.origins = origins.NewCache(
+		origins.SourceFunc(d.lookupBillingOrigins),
+		globalCfg.Get().CorsProjectOriginsCacheSize,
+		time.Duration(globalCfg.Get().CorsProjectOriginsCacheTtlMinutes)*time.Minute,
+	)
+	d.merchantKeys = merchantauth.NewCache(
+		merchantauth.SourceFunc(d.lookupBillingMerchantKey),
+		globalCfg.Get().MerchantKeyCacheSize,
+		time.Duration(globalCfg.Get().MerchantKeyCacheTtlMinutes)*time.Minute,
+	)
+	d.merchantSignatures = merchantauth.NewReplays(globalCfg.Get().MerchantSignatureCacheSize)
+	d.merchantLimiter = ratelimit.New(globalCfg.Get().MerchantRateLimit, time.Minute)
+	d.merchantIpLimiter = ratelimit.New(globalCfg.Get().MerchantIpRateLimit, time.Minute)
+	return d
 }
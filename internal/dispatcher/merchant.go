@@ -0,0 +1,156 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/merchantauth"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errMerchantKeyLookupFailed = errors.New("merchant api key lookup in billing failed")
+)
+
+// MerchantAuthMiddleware authenticates requests of the merchant group and limits requests per ip and per api key.
+// Request is authenticated by the api key secret sent in X-Api-Key header
+// or by HMAC signature of the request sent in X-Api-Hmac header with the signing time in X-Api-Timestamp header.
+// Requests are limited per ip before the api key lookup, so unknown keys don't reach billing without a limit.
+func (d *Dispatcher) MerchantAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !strings.HasPrefix(c.Path(), common.MerchantGroupPath+"/") {
+			return next(c)
+		}
+
+		cfg := d.globalCfg.Get()
+
+		if httpErr := limitMerchantRequest(c, d.merchantIpLimiter, c.RealIP(), cfg.MerchantIpRateLimit); httpErr != nil {
+			return httpErr
+		}
+
+		key, httpErr := d.authenticateMerchant(c)
+
+		if httpErr != nil {
+			return httpErr
+		}
+
+		limit := key.RateLimit
+
+		if limit <= 0 {
+			limit = cfg.MerchantRateLimit
+		}
+
+		if httpErr := limitMerchantRequest(c, d.merchantLimiter, key.Id, limit); httpErr != nil {
+			return httpErr
+		}
+
+		common.SetMerchantKeyContext(c, key)
+		return next(c)
+	}
+}
+
+// limitMerchantRequest registers request of the key and rejects it with Retry-After header if the limit is exceeded
+func limitMerchantRequest(c echo.Context, limiter *ratelimit.Limiter, key string, limit int) *echo.HTTPError {
+	if hits := limiter.Hit(key); limit <= 0 || hits <= limit {
+		return nil
+	}
+
+	retry := math.Ceil(limiter.Retry(key).Seconds())
+	c.Response().Header().Set(common.HeaderRetryAfter, strconv.FormatFloat(retry, 'f', 0, 64))
+	return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorTooManyRequests)
+}
+
+func (d *Dispatcher) authenticateMerchant(c echo.Context) (*merchantauth.Key, *echo.HTTPError) {
+	header := c.Request().Header
+	keyId := header.Get(common.HeaderXApiKeyId)
+
+	if keyId == "" {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+	}
+
+	key, err := d.merchantKeys.Lookup(c.Request().Context(), keyId)
+
+	if err == merchantauth.ErrNotFound {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+	}
+
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, common.ErrorMerchantAuthUnavailable)
+	}
+
+	if secret := header.Get(common.HeaderXApiKey); secret != "" {
+		err = key.CheckSecret(secret)
+	} else {
+		timestamp, e := strconv.ParseInt(header.Get(common.HeaderXApiTimestamp), 10, 64)
+
+		if e != nil {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMerchantRequestExpired)
+		}
+
+		skew := time.Duration(d.globalCfg.Get().MerchantSignatureMaxSkewSeconds) * time.Second
+		signature := header.Get(common.HeaderXApiHmac)
+		err = key.Verify(
+			signature,
+			timestamp,
+			c.Request().Method,
+			c.Request().URL.RequestURI(),
+			common.ExtractRawBodyContext(c),
+			time.Now(),
+			skew,
+		)
+
+		// signature is registered after verification only, so unsigned requests can't fill the replays
+		if err == nil && d.merchantSignatures.Seen(key.Id+":"+strings.ToLower(signature), skew) {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMerchantRequestReplayed)
+		}
+	}
+
+	if err == merchantauth.ErrExpired {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMerchantRequestExpired)
+	}
+
+	if err != nil {
+		d.L().Warn("merchant request authentication failed", logger.PairArgs("key_id", keyId, "err", err.Error()))
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+	}
+
+	return key, nil
+}
+
+func (d *Dispatcher) lookupBillingMerchantKey(ctx context.Context, id string) (*merchantauth.Key, error) {
+	req := &billingext.GetMerchantApiKeyRequest{KeyId: id}
+	res, err := d.appSet.Services.BillingExt.GetMerchantApiKey(ctx, req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(d.L(), err, pkg.ServiceName, "GetMerchantApiKey", req)
+		return nil, err
+	}
+
+	if res.Status == http.StatusNotFound || (res.Status == pkg.ResponseStatusOk && res.Item == nil) {
+		return nil, merchantauth.ErrNotFound
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		d.L().Error(errMerchantKeyLookupFailed.Error(), logger.PairArgs("status", res.Status, "key_id", id))
+		return nil, errMerchantKeyLookupFailed
+	}
+
+	key := &merchantauth.Key{
+		Id:         res.Item.KeyId,
+		MerchantId: res.Item.MerchantId,
+		ProjectId:  res.Item.ProjectId,
+		Secret:     res.Item.Secret,
+		RateLimit:  int(res.Item.RateLimit),
+	}
+
+	return key, nil
+}
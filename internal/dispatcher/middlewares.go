@@ -7,7 +7,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/http"
 )
 
 // RecoverMiddleware
@@ -29,10 +32,26 @@ func (d *Dispatcher) RecoverMiddleware() echo.MiddlewareFunc {
 	}
 }
 
-// RawBodyPreMiddleware
+// RawBodyPreMiddleware reads request body which is limited by the route limit or the global one,
+// bigger bodies are rejected without reading them entirely
 func (d *Dispatcher) RawBodyPreMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		buf, _ := ioutil.ReadAll(c.Request().Body)
+		size, msg := d.globalCfg.Get().RequestBodyMaxSize, common.ErrorRequestBodyTooLarge
+
+		if limit := d.groups.GetBodyLimit(c.Request().Method, c.Path()); limit != nil {
+			size, msg = limit.Size(), limit.Error
+		}
+
+		if c.Request().ContentLength > int64(size) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, msg)
+		}
+
+		buf, _ := ioutil.ReadAll(io.LimitReader(c.Request().Body, int64(size)+1))
+
+		if len(buf) > size {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, msg)
+		}
+
 		rdr := ioutil.NopCloser(bytes.NewBuffer(buf))
 		c.Request().Body = rdr
 		common.SetRawBodyContext(c, buf)
@@ -40,6 +59,57 @@ func (d *Dispatcher) RawBodyPreMiddleware(next echo.HandlerFunc) echo.HandlerFun
 	}
 }
 
+// GeoIpMiddleware resolves country of the customer ip address for handlers, logs and metrics.
+// Country header is always overwritten to prevent logging of the value sent by client
+func (d *Dispatcher) GeoIpMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if d.geoIp == nil {
+			c.Request().Header.Del(common.HeaderXIpCountry)
+			return next(c)
+		}
+
+		country := d.geoIp.Country(net.ParseIP(c.RealIP()))
+		c.Request().Header.Set(common.HeaderXIpCountry, country)
+		common.SetIpCountryContext(c, country)
+
+		tag := country
+
+		if tag == "" {
+			tag = "unknown"
+		}
+
+		d.M().Tagged(map[string]string{"country": tag}).Counter("geoip_requests").Inc(1)
+		return next(c)
+	}
+}
+
+// MaintenanceMiddleware passes requests to handlers with maintenance flag in maintenance mode,
+// handlers creating orders and payments reject requests or render maintenance page
+func (d *Dispatcher) MaintenanceMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if d.Maintenance() {
+			common.SetMaintenanceContext(c)
+		}
+
+		return next(c)
+	}
+}
+
+// DeprecationMiddleware marks responses of the API v1 routes as deprecated when API v1 sunset date is configured
+func (d *Dispatcher) DeprecationMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if common.GetApiVersion(c.Path()) != common.ApiV1 {
+			return next(c)
+		}
+
+		if sunset, ok := d.globalCfg.Get().ApiV1Sunset(); ok {
+			common.SetDeprecationHeaders(c, sunset)
+		}
+
+		return next(c)
+	}
+}
+
 // BodyDumpMiddleware
 func (d *Dispatcher) BodyDumpMiddleware() echo.MiddlewareFunc {
 	return middleware.BodyDump(func(ctx echo.Context, reqBody, resBody []byte) {
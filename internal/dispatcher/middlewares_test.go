@@ -0,0 +1,107 @@
+package dispatcher_test
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	bodyGlobalMaxSize = 64
+	bodyRouteMaxSize  = 16
+
+	bodyPath        = "/body"
+	bodyLimitedPath = "/body/limited"
+)
+
+// bodyRoute returns size of the request body read by the dispatcher
+type bodyRoute struct{}
+
+func (r *bodyRoute) Route(groups *common.Groups) {
+	groups.Shared(func(group *echo.Group) {
+		group.POST(bodyPath, r.size)
+		route := group.POST(bodyLimitedPath, r.size)
+		groups.LimitBody(route, &common.BodyLimit{
+			Size:  func() int { return bodyRouteMaxSize },
+			Error: common.ErrorEventsTooLarge,
+		})
+	})
+}
+
+func (r *bodyRoute) size(ctx echo.Context) error {
+	return ctx.String(http.StatusOK, strconv.Itoa(len(common.ExtractRawBodyContext(ctx))))
+}
+
+type RawBodyTestSuite struct {
+	suite.Suite
+	caller *test.EchoReqResCaller
+}
+
+func Test_RawBody(t *testing.T) {
+	suite.Run(t, new(RawBodyTestSuite))
+}
+
+func (suite *RawBodyTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	global := settings["dispatcher"].(map[string]interface{})["global"].(map[string]interface{})
+	global["requestBodyMaxSize"] = bodyGlobalMaxSize
+
+	suite.caller, e = test.SetUp(settings, common.Services{}, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		return common.Handlers{
+			&bodyRoute{},
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *RawBodyTestSuite) execute(path string, size int) (int, string, error) {
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + path).
+		BodyString(strings.Repeat("a", size)).
+		Exec(suite.T())
+
+	return res.Code, res.Body.String(), err
+}
+
+func (suite *RawBodyTestSuite) Test_GlobalLimit() {
+	code, body, err := suite.execute(bodyPath, bodyGlobalMaxSize)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, code)
+	assert.Equal(suite.T(), strconv.Itoa(bodyGlobalMaxSize), body)
+
+	code, _, err = suite.execute(bodyPath, bodyGlobalMaxSize+1)
+
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, code)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorRequestBodyTooLarge, httpErr.Message)
+}
+
+func (suite *RawBodyTestSuite) Test_RouteLimit() {
+	code, body, err := suite.execute(bodyLimitedPath, bodyRouteMaxSize)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, code)
+	assert.Equal(suite.T(), strconv.Itoa(bodyRouteMaxSize), body)
+
+	code, _, err = suite.execute(bodyLimitedPath, bodyRouteMaxSize+1)
+
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, code)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorEventsTooLarge, httpErr.Message)
+}
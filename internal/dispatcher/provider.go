@@ -4,12 +4,17 @@ import (
 	"context"
 	"github.com/ProtocolONE/go-core/v2/pkg/config"
 	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/google/wire"
 	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/management"
 	"github.com/paysuper/paysuper-checkout/internal/validators"
+	"github.com/paysuper/paysuper-checkout/pkg/geoip"
 	"github.com/paysuper/paysuper-checkout/pkg/micro"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -25,16 +30,27 @@ func ProviderCfg(cfg config.Configurator) (*Config, func(), error) {
 }
 
 // ProviderGlobalCfg
-func ProviderGlobalCfg(cfg config.Configurator) (*common.Config, func(), error) {
-	c := &common.Config{}
-	e := cfg.UnmarshalKey(common.UnmarshalGlobalConfigKey, c)
-	return c, func() {}, e
+func ProviderGlobalCfg(cfg config.Configurator, dispatcherCfg *Config, set provider.AwareSet) (*common.GlobalConfig, func(), error) {
+	g, e := common.NewGlobalConfig(func(c *common.Config) error {
+		return cfg.UnmarshalKey(common.UnmarshalGlobalConfigKey, c)
+	})
+	if e != nil {
+		return nil, nil, e
+	}
+	dispatcherCfg.OnReload(func(_ context.Context) {
+		if e := g.Reload(); e != nil {
+			set.L().Error("global configuration reload failed, previous configuration is used", logger.PairArgs("err", e.Error()))
+		}
+	})
+	return g, func() {}, nil
 }
 
 // ProviderServices
 func ProviderServices(srv *micro.Micro) common.Services {
 	return common.Services{
-		Billing: grpc.NewBillingService(pkg.ServiceName, srv.Client()),
+		Billing:    grpc.NewBillingService(pkg.ServiceName, srv.Client()),
+		BillingExt: billingext.NewService(pkg.ServiceName, srv.Client()),
+		Broker:     srv.Broker(),
 	}
 }
 
@@ -50,6 +66,10 @@ func ProviderValidators(v *validators.ValidatorSet) (validate *validator.Validat
 	if err = validate.RegisterValidation("zip_usa", v.ZipUsaValidator); err != nil {
 		return
 	}
+	if err = validate.RegisterValidation("postal_code", v.PostalCodeValidator); err != nil {
+		return
+	}
+	validate.RegisterStructValidation(v.OrderUserValidator, billing.OrderUser{})
 	if err = validate.RegisterValidation("name", v.NameValidator); err != nil {
 		return
 	}
@@ -59,13 +79,60 @@ func ProviderValidators(v *validators.ValidatorSet) (validate *validator.Validat
 	if err = validate.RegisterValidation("locale", v.UserLocaleValidator); err != nil {
 		return
 	}
+	if err = validate.RegisterValidation("free_text", v.FreeTextValidator); err != nil {
+		return
+	}
+	if err = validate.RegisterValidation("promo_code", v.PromoCodeValidator); err != nil {
+		return
+	}
 	return validate, func() {}, nil
 }
 
 // ProviderDispatcher
-func ProviderDispatcher(ctx context.Context, set provider.AwareSet, appSet AppSet, cfg *Config, globalCfg *common.Config, ms *micro.Micro) (*Dispatcher, func(), error) {
+func ProviderDispatcher(ctx context.Context, set provider.AwareSet, appSet AppSet, cfg *Config, globalCfg *common.GlobalConfig, ms *micro.Micro) (*Dispatcher, func(), error) {
 	d := New(ctx, set, appSet, cfg, globalCfg, ms)
-	return d, func() {}, nil
+
+	if e := ms.Handle(management.New(set, d)); e != nil {
+		return nil, nil, e
+	}
+
+	maintenance := cfg.Maintenance
+	d.SetMaintenance(maintenance)
+	cfg.OnReload(func(_ context.Context) {
+		if cfg.Maintenance == maintenance {
+			return
+		}
+
+		maintenance = cfg.Maintenance
+		d.SetMaintenance(maintenance)
+		d.L().Info("maintenance mode changed by configuration", logger.PairArgs("enabled", maintenance))
+	})
+	cfg.OnReload(func(_ context.Context) {
+		if e := d.ReloadTemplates(); e != nil {
+			d.L().Error("templates reload failed, previous templates are used", logger.PairArgs("err", e.Error()))
+		}
+	})
+
+	geoIpDatabaseFile := globalCfg.Get().GeoIpDatabaseFile
+
+	if geoIpDatabaseFile == "" {
+		return d, func() {}, nil
+	}
+
+	db, e := geoip.Open(geoIpDatabaseFile)
+
+	if e != nil {
+		return nil, nil, e
+	}
+
+	d.geoIp = db
+	cfg.OnReload(func(ctx context.Context) {
+		if e := db.Reload(); e != nil {
+			d.L().Error("geoip database reload failed, previous database is used", logger.PairArgs("err", e.Error()))
+		}
+	})
+
+	return d, func() { _ = db.Close() }, nil
 }
 
 var (
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/bin"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"net/http"
+	"time"
+)
+
+const (
+	binPath = "/orders/:order_id/bin/:bin"
+
+	binCacheControl = "private, max-age=86400"
+)
+
+var (
+	errBinLookupFailed = errors.New("bin lookup in billing failed")
+)
+
+type BinRequest struct {
+	OrderId string `json:"-" param:"order_id" validate:"required,uuid"`
+	Bin     string `json:"-" param:"bin" validate:"required,numeric,min=6,max=8"`
+}
+
+type BinRoute struct {
+	dispatch common.HandlerSet
+	cfg      *common.GlobalConfig
+	database *bin.CsvSource
+	cache    *bin.Cache
+	limiter  *ratelimit.Limiter
+	provider.LMT
+}
+
+func NewBinRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *BinRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "BinRoute"})
+	cfg := globalCfg.Get()
+	route := &BinRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      globalCfg,
+		limiter:  ratelimit.New(cfg.BinLookupLimit, time.Minute),
+	}
+
+	var sources []bin.Source
+
+	if cfg.BinDatabaseFile != "" {
+		database, err := bin.NewCsvSource(cfg.BinDatabaseFile)
+
+		if err != nil {
+			route.L().Error("bin database can't be loaded", logger.PairArgs("err", err.Error()))
+		} else {
+			route.database = database
+			sources = append(sources, database)
+		}
+	}
+
+	if cfg.BinBillingFallback {
+		sources = append(sources, bin.SourceFunc(route.lookupBillingBin))
+	}
+
+	route.cache = bin.NewCache(
+		bin.Chain(sources...),
+		cfg.BinCacheSize,
+		time.Duration(cfg.BinCacheTtlMinutes)*time.Minute,
+	)
+
+	return route
+}
+
+func (h *BinRoute) Route(groups *common.Groups) {
+	groups.Shared(func(group *echo.Group) {
+		group.GET(binPath, h.getBin)
+	})
+}
+
+// Reload applies bin lookup limit of the reloaded configuration and reloads bin database file,
+// previous bins are kept if the file is broken
+func (h *BinRoute) Reload(_ context.Context) {
+	h.limiter.SetLimit(h.cfg.Get().BinLookupLimit, time.Minute)
+
+	if h.database == nil {
+		return
+	}
+
+	if err := h.database.Reload(); err != nil {
+		h.L().Error("bin database can't be reloaded", logger.PairArgs("err", err.Error()))
+		return
+	}
+
+	h.cache.Purge()
+	h.L().Info("bin database reloaded", logger.PairArgs("bins", h.database.Len()))
+}
+
+// InvalidateCaches purges bins cache, database and billing are requested again
+func (h *BinRoute) InvalidateCaches(_ context.Context) []string {
+	h.cache.Purge()
+	return []string{"bin"}
+}
+
+// getBin returns card brand, type and issuing country by the first 6-8 digits of the card number
+func (h *BinRoute) getBin(ctx echo.Context) error {
+	req := &BinRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	if !h.limiter.Allow(req.OrderId) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	}
+
+	info, err := h.cache.Lookup(ctx.Request().Context(), req.Bin)
+
+	if err == bin.ErrNotFound {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorBinNotFound)
+	}
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderCacheControl, binCacheControl)
+
+	return ctx.JSON(http.StatusOK, info)
+}
+
+func (h *BinRoute) lookupBillingBin(ctx context.Context, number string) (*bin.Info, error) {
+	req := &billingext.BinDataRequest{Bin: number}
+	res, err := h.dispatch.Services.BillingExt.GetBinData(ctx, req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "GetBinData", req)
+		return nil, err
+	}
+
+	if res.Status == http.StatusNotFound || (res.Status == pkg.ResponseStatusOk && res.Item == nil) {
+		return nil, bin.ErrNotFound
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		h.L().Error(errBinLookupFailed.Error(), logger.PairArgs("status", res.Status, "bin", number))
+		return nil, errBinLookupFailed
+	}
+
+	return &bin.Info{
+		Bin:      res.Item.Bin,
+		Brand:    res.Item.Brand,
+		Type:     res.Item.Type,
+		Category: res.Item.Category,
+		Bank:     res.Item.Bank,
+		Country:  res.Item.Country,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type BinTestSuite struct {
+	suite.Suite
+	router   *BinRoute
+	caller   *test.EchoReqResCaller
+	database string
+}
+
+func Test_Bin(t *testing.T) {
+	suite.Run(t, new(BinTestSuite))
+}
+
+func (suite *BinTestSuite) SetupTest() {
+	var e error
+
+	file, e := ioutil.TempFile("", "bins*.csv")
+
+	if e != nil {
+		panic(e)
+	}
+
+	_, _ = file.WriteString("bin,brand,type,category,bank,country\n411111,visa,credit,classic,Test Bank,US\n")
+	_ = file.Close()
+	suite.database = file.Name()
+
+	settings := test.DefaultSettings()
+	settings["dispatcher"].(map[string]interface{})["global"].(map[string]interface{})["binDatabaseFile"] = suite.database
+	srv := common.Services{}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewBinRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *BinTestSuite) TearDownTest() {
+	_ = os.Remove(suite.database)
+}
+
+// Test GetBin route
+func (suite *BinTestSuite) executeGetBinTest(orderId, bin string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterOrderId, orderId, ":"+common.RequestParameterBin, bin).
+		Path(common.NoAuthGroupPath + binPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+}
+
+func (suite *BinTestSuite) Test_GetBin_Database() {
+	ext := &extMock.Service{}
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetBinTest(uuid.New().String(), "41111111")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.JSONEq(suite.T(), `{"bin":"411111","brand":"visa","type":"credit","category":"classic","bank":"Test Bank","country":"US"}`, res.Body.String())
+	assert.Equal(suite.T(), binCacheControl, res.Header().Get(echo.HeaderCacheControl))
+	ext.AssertNotCalled(suite.T(), "GetBinData", mock2.Anything, mock2.Anything)
+}
+
+func (suite *BinTestSuite) Test_GetBin_BillingFallbackCached() {
+	ext := &extMock.Service{}
+	ext.On("GetBinData", mock2.Anything, &billingext.BinDataRequest{Bin: "555555"}).
+		Return(&billingext.BinDataResponse{
+			Status: pkg.ResponseStatusOk,
+			Item:   &billingext.BinData{Bin: "555555", Brand: "mastercard", Type: "debit", Country: "GB"},
+		}, nil).
+		Once()
+	suite.router.dispatch.Services.BillingExt = ext
+
+	for i := 0; i < 2; i++ {
+		res, err := suite.executeGetBinTest(uuid.New().String(), "555555")
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+		assert.Contains(suite.T(), res.Body.String(), `"brand":"mastercard"`)
+	}
+
+	ext.AssertNumberOfCalls(suite.T(), "GetBinData", 1)
+}
+
+func (suite *BinTestSuite) Test_GetBin_NotFound() {
+	ext := &extMock.Service{}
+	ext.On("GetBinData", mock2.Anything, mock2.Anything).
+		Return(&billingext.BinDataResponse{Status: pkg.ResponseStatusNotFound}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeGetBinTest(uuid.New().String(), "400000")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorBinNotFound, httpErr.Message)
+}
+
+func (suite *BinTestSuite) Test_GetBin_BillingReturnError() {
+	ext := &extMock.Service{}
+	ext.On("GetBinData", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeGetBinTest(uuid.New().String(), "400000")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+}
+
+func (suite *BinTestSuite) Test_GetBin_ValidationError() {
+	for _, bin := range []string{"41111", "411111111", "41111a"} {
+		_, err := suite.executeGetBinTest(uuid.New().String(), bin)
+
+		assert.Error(suite.T(), err)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Regexp(suite.T(), common.ErrorIncorrectBin.Message, httpErr.Message)
+	}
+}
+
+func (suite *BinTestSuite) Test_GetBin_TooManyRequests() {
+	orderId := uuid.New().String()
+	suite.router.limiter = ratelimit.New(1, time.Hour)
+
+	res, err := suite.executeGetBinTest(orderId, "411111")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	_, err = suite.executeGetBinTest(orderId, "411111")
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+}
+
+func (suite *BinTestSuite) Test_Reload() {
+	assert.NoError(suite.T(), ioutil.WriteFile(suite.database, []byte("400000,visa,debit,,Bank,DE\n"), 0644))
+
+	res, err := suite.executeGetBinTest(uuid.New().String(), "411111")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	suite.router.Reload(context.Background())
+
+	res, err = suite.executeGetBinTest(uuid.New().String(), "400000")
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), res.Body.String(), `"country":"DE"`)
+
+	ext := &extMock.Service{}
+	ext.On("GetBinData", mock2.Anything, mock2.Anything).
+		Return(&billingext.BinDataResponse{Status: pkg.ResponseStatusNotFound}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err = suite.executeGetBinTest(uuid.New().String(), "411111")
+	assert.Error(suite.T(), err)
+}
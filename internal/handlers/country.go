@@ -16,21 +16,23 @@ const (
 
 type CountryRoute struct {
 	dispatch common.HandlerSet
-	cfg      *common.Config
+	cfg      *common.GlobalConfig
 	provider.LMT
 }
 
-func NewCountryRoute(set common.HandlerSet, cfg *common.Config) *CountryRoute {
+func NewCountryRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *CountryRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "CountryRoute"})
 	return &CountryRoute{
 		dispatch: set,
 		LMT:      &set.AwareSet,
-		cfg:      cfg,
+		cfg:      globalCfg,
 	}
 }
 
 func (h *CountryRoute) Route(groups *common.Groups) {
-	groups.Common.GET(paymentCountriesOrderIdPath, h.getPaymentCountries)
+	groups.Shared(func(group *echo.Group) {
+		group.GET(paymentCountriesOrderIdPath, h.getPaymentCountries)
+	})
 }
 
 func (h *CountryRoute) getPaymentCountries(ctx echo.Context) error {
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	devicePath = "/orders/:order_id/device"
+
+	deviceHeaderMaxLength = 256
+)
+
+// deviceHeaders are request headers passed to billing as fraud signals
+var deviceHeaders = []string{
+	echo.HeaderAccept,
+	echo.HeaderAcceptEncoding,
+	common.HeaderAcceptLanguage,
+	common.HeaderUserAgent,
+	"Sec-Ch-Ua",
+	"Sec-Ch-Ua-Mobile",
+	"Sec-Ch-Ua-Platform",
+	"Dnt",
+	"Via",
+}
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+type DeviceRequest struct {
+	OrderId string                        `json:"-" param:"order_id" validate:"required,uuid"`
+	Device  *billingext.DeviceFingerprint `json:"-" validate:"required"`
+}
+
+type DeviceRoute struct {
+	dispatch common.HandlerSet
+	cfg      *common.GlobalConfig
+	limiter  *ratelimit.Limiter
+	provider.LMT
+}
+
+func NewDeviceRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *DeviceRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "DeviceRoute"})
+	cfg := globalCfg.Get()
+	return &DeviceRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      globalCfg,
+		limiter:  ratelimit.New(cfg.DeviceDataLimit, time.Minute),
+	}
+}
+
+// Reload applies device data limit of the reloaded configuration
+func (h *DeviceRoute) Reload(_ context.Context) {
+	h.limiter.SetLimit(h.cfg.Get().DeviceDataLimit, time.Minute)
+}
+
+func (h *DeviceRoute) Route(groups *common.Groups) {
+	groups.Shared(func(group *echo.Group) {
+		route := group.POST(devicePath, h.setDeviceData, rejectInMaintenance(h.cfg))
+		groups.LimitBody(route, &common.BodyLimit{
+			Size:  func() int { return h.cfg.Get().DeviceDataMaxSize },
+			Error: common.ErrorDeviceDataTooLarge,
+		})
+	})
+}
+
+// setDeviceData attaches browser fingerprint and signals observed by checkout to the order for the fraud scoring
+func (h *DeviceRoute) setDeviceData(ctx echo.Context) error {
+	body := common.ExtractRawBodyContext(ctx)
+	req := &DeviceRequest{
+		OrderId: ctx.Param(common.RequestParameterOrderId),
+		Device:  &billingext.DeviceFingerprint{},
+	}
+
+	// unknown fields are rejected to keep the data sent to billing strictly bounded
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(req.Device); err != nil || decoder.More() {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorIncorrectDeviceData)
+	}
+
+	normalizeDeviceFingerprint(req.Device)
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	if !h.limiter.Allow(req.OrderId) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	}
+
+	data := &billingext.SetOrderDeviceDataRequest{
+		OrderId:     req.OrderId,
+		Device:      req.Device,
+		Server:      getDeviceServerSignals(ctx),
+		CollectedAt: time.Now().Unix(),
+	}
+	res, err := h.dispatch.Services.BillingExt.SetOrderDeviceData(ctx.Request().Context(), data)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(data, err, pkg.ServiceName, "SetOrderDeviceData")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// normalizeDeviceFingerprint trims strings, converts hashes to lower case and removes duplicated languages
+func normalizeDeviceFingerprint(d *billingext.DeviceFingerprint) {
+	d.VisitorId = strings.TrimSpace(d.VisitorId)
+	d.Timezone = strings.TrimSpace(d.Timezone)
+	d.Platform = strings.TrimSpace(d.Platform)
+	d.WebglVendor = strings.TrimSpace(d.WebglVendor)
+	d.WebglRenderer = strings.TrimSpace(d.WebglRenderer)
+	d.CanvasHash = strings.ToLower(strings.TrimSpace(d.CanvasHash))
+	d.WebglHash = strings.ToLower(strings.TrimSpace(d.WebglHash))
+	d.AudioHash = strings.ToLower(strings.TrimSpace(d.AudioHash))
+	d.FontsHash = strings.ToLower(strings.TrimSpace(d.FontsHash))
+
+	if len(d.Languages) == 0 {
+		return
+	}
+
+	languages := make([]string, 0, len(d.Languages))
+	seen := make(map[string]bool, len(d.Languages))
+
+	for _, lang := range d.Languages {
+		lang = strings.TrimSpace(lang)
+		key := strings.ToLower(lang)
+
+		if lang == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		languages = append(languages, lang)
+	}
+
+	d.Languages = languages
+}
+
+// getDeviceServerSignals returns request properties which can't be changed by the payment form script
+func getDeviceServerSignals(ctx echo.Context) *billingext.DeviceServerSignals {
+	r := ctx.Request()
+	signals := &billingext.DeviceServerSignals{
+		Ip:        ctx.RealIP(),
+		IpCountry: common.ExtractIpCountryContext(ctx),
+		Protocol:  r.Proto,
+		Headers:   make(map[string]string),
+	}
+
+	if forwarded := r.Header.Get(echo.HeaderXForwardedFor); forwarded != "" {
+		signals.ForwardedHops = len(strings.Split(forwarded, ","))
+	}
+
+	if r.TLS != nil {
+		signals.TlsVersion = tlsVersions[r.TLS.Version]
+		signals.TlsCipherSuite = fmt.Sprintf("0x%04x", r.TLS.CipherSuite)
+	}
+
+	for _, name := range deviceHeaders {
+		value := r.Header.Get(name)
+
+		if value == "" {
+			continue
+		}
+
+		if len(value) > deviceHeaderMaxLength {
+			value = value[:deviceHeaderMaxLength]
+		}
+
+		signals.Headers[name] = value
+	}
+
+	return signals
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type DeviceTestSuite struct {
+	suite.Suite
+	router *DeviceRoute
+	caller *test.EchoReqResCaller
+}
+
+func Test_Device(t *testing.T) {
+	suite.Run(t, new(DeviceTestSuite))
+}
+
+func (suite *DeviceTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewDeviceRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+// Test SetDeviceData route
+func (suite *DeviceTestSuite) executeSetDeviceDataTest(orderId, body string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath+devicePath).
+		Init(test.ReqInitJSON()).
+		Init(test.ReqInitIpCountry("DE")).
+		AddHeader(common.HeaderUserAgent, "Mozilla/5.0").
+		AddHeader(echo.HeaderXForwardedFor, "127.0.0.1, 10.0.0.1").
+		AddHeader("Sec-CH-UA-Platform", `"Windows"`).
+		BodyString(body).
+		Exec(suite.T())
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_Ok() {
+	orderId := uuid.New().String()
+	body := `{"visitor_id": "a1b2c3", "screen": {"width": 1920, "height": 1080, "color_depth": 24, "pixel_ratio": 1.5},
+		"timezone": "Europe/Berlin", "timezone_offset": -60, "languages": ["de-DE", " de-DE", "en"],
+		"canvas_hash": "ABCDEF0123", "webdriver": false}`
+
+	ext := &extMock.Service{}
+	ext.On("SetOrderDeviceData", mock2.Anything, mock2.MatchedBy(func(req *billingext.SetOrderDeviceDataRequest) bool {
+		return req.OrderId == orderId &&
+			req.Device.Screen.Width == 1920 &&
+			req.Device.CanvasHash == "abcdef0123" &&
+			assert.ObjectsAreEqual([]string{"de-DE", "en"}, req.Device.Languages) &&
+			req.Server.IpCountry == "DE" &&
+			req.Server.ForwardedHops == 2 &&
+			req.Server.Headers[common.HeaderUserAgent] == "Mozilla/5.0" &&
+			req.Server.Headers["Sec-Ch-Ua-Platform"] == `"Windows"` &&
+			req.CollectedAt > 0
+	})).Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeSetDeviceDataTest(orderId, body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+	ext.AssertExpectations(suite.T())
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_TooLarge() {
+	body := `{"webgl_renderer": "` + strings.Repeat("a", suite.router.cfg.Get().DeviceDataMaxSize) + `"}`
+
+	_, err := suite.executeSetDeviceDataTest(uuid.New().String(), body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorDeviceDataTooLarge, httpErr.Message)
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_SchemaError() {
+	cases := []string{
+		`{"unknown": 1}`,
+		`{"screen": {"width": 1, "depth": 2}}`,
+		`{"screen": "1920x1080"}`,
+		`{"languages": "en"}`,
+		`{} {}`,
+		`not json`,
+	}
+
+	for _, body := range cases {
+		_, err := suite.executeSetDeviceDataTest(uuid.New().String(), body)
+
+		assert.Error(suite.T(), err, body)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Equal(suite.T(), common.ErrorIncorrectDeviceData, httpErr.Message, body)
+	}
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_ValidationError() {
+	cases := []string{
+		`{"screen": {"width": 99999}}`,
+		`{"timezone_offset": 1000}`,
+		`{"languages": ["a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"]}`,
+		`{"canvas_hash": "not a hash"}`,
+		`{"visitor_id": "<script>"}`,
+	}
+
+	for _, body := range cases {
+		_, err := suite.executeSetDeviceDataTest(uuid.New().String(), body)
+
+		assert.Error(suite.T(), err, body)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Equal(suite.T(), common.ErrorValidationFailed.Code, httpErr.Message.(grpc.ResponseErrorMessage).Code, body)
+	}
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_OrderIdError() {
+	_, err := suite.executeSetDeviceDataTest("unknown", `{}`)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message)
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_LimitExceeded() {
+	orderId := uuid.New().String()
+	suite.router.limiter = ratelimit.New(1, time.Minute)
+
+	ext := &extMock.Service{}
+	ext.On("SetOrderDeviceData", mock2.Anything, mock2.Anything).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeSetDeviceDataTest(orderId, `{}`)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+
+	_, err = suite.executeSetDeviceDataTest(orderId, `{}`)
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorTooManyRequests, httpErr.Message)
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_BillingServerError() {
+	ext := &extMock.Service{}
+	ext.On("SetOrderDeviceData", mock2.Anything, mock2.Anything).Return(nil, errors.New("some error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeSetDeviceDataTest(uuid.New().String(), `{}`)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+}
+
+func (suite *DeviceTestSuite) Test_SetDeviceData_BillingResponseStatusError() {
+	ext := &extMock.Service{}
+	ext.On("SetOrderDeviceData", mock2.Anything, mock2.Anything).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusNotFound, Message: &grpc.ResponseErrorMessage{Message: "order not found"}}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeSetDeviceDataTest(uuid.New().String(), `{}`)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+}
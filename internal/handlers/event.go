@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/events"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"net/http"
+	"time"
+)
+
+const (
+	eventsPath = "/orders/:order_id/events"
+
+	eventUserAgentMaxLength = 256
+)
+
+type EventItem struct {
+	Type string            `json:"type" validate:"required,oneof=form_opened method_selected country_changed payment_submitted error_shown"`
+	Time int64             `json:"time" validate:"required,min=1"`
+	Data map[string]string `json:"data" validate:"max=10,dive,keys,required,max=32,printascii,endkeys,max=255"`
+}
+
+type EventsRequest struct {
+	OrderId string       `json:"-" param:"order_id" validate:"required,uuid"`
+	Events  []*EventItem `json:"events" validate:"required,min=1,max=20,dive,required"`
+}
+
+type EventRoute struct {
+	dispatch common.HandlerSet
+	cfg      *common.GlobalConfig
+	limiter  *ratelimit.Limiter
+	buffer   *events.Buffer
+	file     *events.FileSink
+	provider.LMT
+}
+
+func NewEventRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *EventRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "EventRoute"})
+	cfg := globalCfg.Get()
+	route := &EventRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      globalCfg,
+		limiter:  ratelimit.New(cfg.EventsLimit, time.Minute),
+	}
+
+	if cfg.EventsSink == "" {
+		return route
+	}
+
+	route.buffer = events.NewBuffer(route.newEventsSink(), events.Options{
+		Interval:   time.Duration(cfg.EventsFlushIntervalSeconds) * time.Second,
+		MaxPending: cfg.EventsMaxPending,
+	})
+
+	return route
+}
+
+// Start runs periodic writing of payment form events
+func (h *EventRoute) Start(ctx context.Context) error {
+	if h.buffer == nil {
+		return nil
+	}
+
+	go h.buffer.Run(ctx, func(err error) {
+		h.L().Error("payment form events writing failed", logger.PairArgs("err", err.Error()))
+	})
+	return nil
+}
+
+// newEventsSink returns sink chosen by the configuration, events are logged if the sink can't be created
+func (h *EventRoute) newEventsSink() events.Sink {
+	cfg := h.cfg.Get()
+
+	switch cfg.EventsSink {
+	case events.SinkFile:
+		file, err := events.NewFileSink(cfg.EventsFile)
+
+		if err == nil {
+			h.file = file
+			return file
+		}
+
+		h.L().Error("events file can't be opened", logger.PairArgs("err", err.Error(), "file", cfg.EventsFile))
+	case events.SinkBroker:
+		if h.dispatch.Services.Broker != nil {
+			return events.NewBrokerSink(h.dispatch.Services.Broker, cfg.EventsBrokerTopic)
+		}
+
+		h.L().Error("events broker isn't available")
+	case events.SinkLog:
+	default:
+		h.L().Error("unknown events sink", logger.PairArgs("sink", cfg.EventsSink))
+	}
+
+	return events.NewLogSink(h.L())
+}
+
+// Shutdown writes payment form events which weren't written yet
+func (h *EventRoute) Shutdown(ctx context.Context) {
+	if h.buffer == nil {
+		return
+	}
+
+	if err := h.buffer.Close(ctx); err != nil {
+		h.L().Error("payment form events writing on shutdown failed", logger.PairArgs("err", err.Error()))
+	}
+
+	if h.file != nil {
+		if err := h.file.Close(); err != nil {
+			h.L().Error("events file can't be closed", logger.PairArgs("err", err.Error()))
+		}
+	}
+}
+
+// Reload applies events limit of the reloaded configuration and reopens events file after it was rotated
+func (h *EventRoute) Reload(_ context.Context) {
+	h.limiter.SetLimit(h.cfg.Get().EventsLimit, time.Minute)
+
+	if h.file == nil {
+		return
+	}
+
+	if err := h.file.Reopen(); err != nil {
+		h.L().Error("events file can't be reopened", logger.PairArgs("err", err.Error(), "file", h.cfg.Get().EventsFile))
+	}
+}
+
+func (h *EventRoute) Route(groups *common.Groups) {
+	if h.buffer == nil {
+		return
+	}
+
+	groups.Shared(func(group *echo.Group) {
+		route := group.POST(eventsPath, h.addEvents)
+		groups.LimitBody(route, &common.BodyLimit{
+			Size:  func() int { return h.cfg.Get().EventsMaxSize },
+			Error: common.ErrorEventsTooLarge,
+		})
+	})
+}
+
+// addEvents accepts batch of the payment form events, events are written to the sink asynchronously
+func (h *EventRoute) addEvents(ctx echo.Context) error {
+	body := common.ExtractRawBodyContext(ctx)
+	req := &EventsRequest{}
+
+	// unknown fields are rejected to keep events schema strict for the analytics consumers
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(req); err != nil || decoder.More() {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorIncorrectEvents)
+	}
+
+	req.OrderId = ctx.Param(common.RequestParameterOrderId)
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	if !h.limiter.Allow(req.OrderId) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	}
+
+	userAgent := ctx.Request().Header.Get(common.HeaderUserAgent)
+
+	if len(userAgent) > eventUserAgentMaxLength {
+		userAgent = userAgent[:eventUserAgentMaxLength]
+	}
+
+	receivedAt := time.Now().Unix()
+	ipCountry := common.ExtractIpCountryContext(ctx)
+	batch := make([]*events.Event, 0, len(req.Events))
+
+	for _, item := range req.Events {
+		batch = append(batch, &events.Event{
+			Type:       item.Type,
+			OrderId:    req.OrderId,
+			Time:       item.Time,
+			ReceivedAt: receivedAt,
+			IpCountry:  ipCountry,
+			UserAgent:  userAgent,
+			Data:       item.Data,
+		})
+	}
+
+	if accepted := h.buffer.Add(batch...); accepted < len(batch) {
+		h.L().Error("payment form events dropped, buffer is full", logger.PairArgs("order_id", req.OrderId, "dropped", len(batch)-accepted))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/events"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type eventsSinkStub struct {
+	mx     sync.Mutex
+	events []*events.Event
+}
+
+func (s *eventsSinkStub) Write(_ context.Context, batch []*events.Event) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.events = append(s.events, batch...)
+	return nil
+}
+
+type EventTestSuite struct {
+	suite.Suite
+	router *EventRoute
+	caller *test.EchoReqResCaller
+	sink   *eventsSinkStub
+}
+
+func Test_Event(t *testing.T) {
+	suite.Run(t, new(EventTestSuite))
+}
+
+func (suite *EventTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewEventRoute(set.HandlerSet, set.GlobalConfig)
+		suite.sink = &eventsSinkStub{}
+		suite.router.buffer = events.NewBuffer(suite.sink, events.Options{Interval: time.Hour})
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+// Test AddEvents route
+func (suite *EventTestSuite) executeAddEventsTest(orderId, body string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath+eventsPath).
+		Init(test.ReqInitJSON()).
+		Init(test.ReqInitIpCountry("DE")).
+		AddHeader(common.HeaderUserAgent, "Mozilla/5.0 "+strings.Repeat("a", eventUserAgentMaxLength)).
+		BodyString(body).
+		Exec(suite.T())
+}
+
+func (suite *EventTestSuite) Test_AddEvents_Ok() {
+	orderId := uuid.New().String()
+	body := `{"events": [
+		{"type": "form_opened", "time": 1571043600000},
+		{"type": "method_selected", "time": 1571043601000, "data": {"method": "bank_card"}}
+	]}`
+
+	res, err := suite.executeAddEventsTest(orderId, body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusAccepted, res.Code)
+	assert.Equal(suite.T(), 2, suite.router.buffer.Pending())
+	assert.NoError(suite.T(), suite.router.buffer.Flush(context.Background()))
+
+	assert.Len(suite.T(), suite.sink.events, 2)
+	event := suite.sink.events[1]
+	assert.Equal(suite.T(), "method_selected", event.Type)
+	assert.Equal(suite.T(), orderId, event.OrderId)
+	assert.Equal(suite.T(), int64(1571043601000), event.Time)
+	assert.Equal(suite.T(), "DE", event.IpCountry)
+	assert.Len(suite.T(), event.UserAgent, eventUserAgentMaxLength)
+	assert.Equal(suite.T(), "bank_card", event.Data["method"])
+	assert.True(suite.T(), event.ReceivedAt > 0)
+}
+
+func (suite *EventTestSuite) Test_AddEvents_TooLarge() {
+	body := `{"events": [{"type": "form_opened", "time": 1, "data": {"a": "` + strings.Repeat("a", suite.router.cfg.Get().EventsMaxSize) + `"}}]}`
+
+	_, err := suite.executeAddEventsTest(uuid.New().String(), body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorEventsTooLarge, httpErr.Message)
+}
+
+func (suite *EventTestSuite) Test_AddEvents_SchemaError() {
+	cases := []string{
+		`{"events": [{"type": "form_opened", "time": 1, "order_id": "x"}]}`,
+		`{"events": [{"type": "form_opened", "time": 1, "data": {"a": 1}}]}`,
+		`{"events": {"type": "form_opened"}}`,
+		`{"events": []} {}`,
+		`not json`,
+	}
+
+	for _, body := range cases {
+		_, err := suite.executeAddEventsTest(uuid.New().String(), body)
+
+		assert.Error(suite.T(), err, body)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Equal(suite.T(), common.ErrorIncorrectEvents, httpErr.Message, body)
+	}
+
+	assert.Equal(suite.T(), 0, suite.router.buffer.Pending())
+}
+
+func (suite *EventTestSuite) Test_AddEvents_ValidationError() {
+	cases := []string{
+		`{"events": []}`,
+		`{"events": [null]}`,
+		`{"events": [{"type": "unknown", "time": 1}]}`,
+		`{"events": [{"type": "form_opened"}]}`,
+		`{"events": [{"type": "form_opened", "time": 1, "data": {"` + strings.Repeat("k", 33) + `": "v"}}]}`,
+		`{"events": [{"type": "form_opened", "time": 1, "data": {"k": "` + strings.Repeat("v", 256) + `"}}]}`,
+		`{"events": [` + strings.TrimSuffix(strings.Repeat(`{"type": "form_opened", "time": 1},`, 21), ",") + `]}`,
+	}
+
+	for _, body := range cases {
+		_, err := suite.executeAddEventsTest(uuid.New().String(), body)
+
+		assert.Error(suite.T(), err, body)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Equal(suite.T(), common.ErrorValidationFailed.Code, httpErr.Message.(grpc.ResponseErrorMessage).Code, body)
+	}
+}
+
+func (suite *EventTestSuite) Test_AddEvents_OrderIdError() {
+	_, err := suite.executeAddEventsTest("unknown", `{"events": [{"type": "form_opened", "time": 1}]}`)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message)
+}
+
+func (suite *EventTestSuite) Test_AddEvents_LimitExceeded() {
+	orderId := uuid.New().String()
+	body := `{"events": [{"type": "form_opened", "time": 1}]}`
+	suite.router.limiter = ratelimit.New(1, time.Minute)
+
+	res, err := suite.executeAddEventsTest(orderId, body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusAccepted, res.Code)
+
+	_, err = suite.executeAddEventsTest(orderId, body)
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorTooManyRequests, httpErr.Message)
+}
+
+func (suite *EventTestSuite) Test_AddEvents_BufferFull() {
+	suite.router.buffer = events.NewBuffer(suite.sink, events.Options{Interval: time.Hour, MaxPending: 1})
+	body := `{"events": [{"type": "form_opened", "time": 1}, {"type": "error_shown", "time": 2}]}`
+
+	res, err := suite.executeAddEventsTest(uuid.New().String(), body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusAccepted, res.Code)
+	assert.Equal(suite.T(), 1, suite.router.buffer.Pending())
+	assert.Equal(suite.T(), 1, suite.router.buffer.Dropped())
+}
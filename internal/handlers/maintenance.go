@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	maintenanceTemplateName = "maintenance.html"
+	maintenanceDefaultLang  = "en"
+)
+
+type MaintenanceTemplateData struct {
+	Lang    string
+	Title   string
+	Message string
+}
+
+var maintenanceTexts = map[string]*MaintenanceTemplateData{
+	"en": {
+		Title:   "Scheduled maintenance",
+		Message: "Payments are temporarily unavailable due to scheduled maintenance. Please try again in a few minutes.",
+	},
+	"ru": {
+		Title:   "Технические работы",
+		Message: "Оплата временно недоступна из-за плановых технических работ. Пожалуйста, повторите попытку через несколько минут.",
+	},
+	"de": {
+		Title:   "Wartungsarbeiten",
+		Message: "Zahlungen sind wegen geplanter Wartungsarbeiten vorübergehend nicht möglich. Bitte versuchen Sie es in einigen Minuten erneut.",
+	},
+	"fr": {
+		Title:   "Maintenance programmée",
+		Message: "Les paiements sont temporairement indisponibles en raison d'une maintenance programmée. Veuillez réessayer dans quelques minutes.",
+	},
+	"es": {
+		Title:   "Mantenimiento programado",
+		Message: "Los pagos no están disponibles temporalmente debido a un mantenimiento programado. Vuelva a intentarlo en unos minutos.",
+	},
+	"it": {
+		Title:   "Manutenzione programmata",
+		Message: "I pagamenti sono temporaneamente non disponibili a causa di una manutenzione programmata. Riprova tra qualche minuto.",
+	},
+	"pt": {
+		Title:   "Manutenção programada",
+		Message: "Os pagamentos estão temporariamente indisponíveis devido a uma manutenção programada. Tente novamente em alguns minutos.",
+	},
+}
+
+// renderMaintenance renders maintenance page in the first language of Accept-Language header which has translation
+func renderMaintenance(ctx echo.Context, retryAfter int64) error {
+	lang := getMaintenanceLang(ctx.Request().Header.Get(common.HeaderAcceptLanguage))
+	text := maintenanceTexts[lang]
+
+	ctx.Response().Header().Set(common.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+
+	return ctx.Render(http.StatusServiceUnavailable, maintenanceTemplateName, &MaintenanceTemplateData{
+		Lang:    lang,
+		Title:   text.Title,
+		Message: text.Message,
+	})
+}
+
+// rejectInMaintenance rejects requests of the route in maintenance mode
+func rejectInMaintenance(globalCfg *common.GlobalConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !common.ExtractMaintenanceContext(ctx) {
+				return next(ctx)
+			}
+
+			ctx.Response().Header().Set(common.HeaderRetryAfter, strconv.FormatInt(globalCfg.Get().MaintenanceRetryAfterSeconds, 10))
+			return echo.NewHTTPError(http.StatusServiceUnavailable, common.ErrorMaintenance)
+		}
+	}
+}
+
+// renderInMaintenance renders maintenance page instead of the page of the route in maintenance mode
+func renderInMaintenance(globalCfg *common.GlobalConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !common.ExtractMaintenanceContext(ctx) {
+				return next(ctx)
+			}
+
+			return renderMaintenance(ctx, globalCfg.Get().MaintenanceRetryAfterSeconds)
+		}
+	}
+}
+
+func getMaintenanceLang(acceptLanguage string) string {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		tag = strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+
+		if _, ok := maintenanceTexts[lang]; ok {
+			return lang
+		}
+	}
+
+	return maintenanceDefaultLang
+}
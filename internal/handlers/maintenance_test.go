@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	billMock "github.com/paysuper/paysuper-billing-server/pkg/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// maintenanceNotGatedRoutes are routes with write methods which neither create nor change orders and payments
+var maintenanceNotGatedRoutes = map[string]bool{
+	eventsPath: true,
+	quotePath:  true,
+}
+
+type MaintenanceTestSuite struct {
+	suite.Suite
+	router   *OrderRoute
+	caller   *test.EchoReqResCaller
+	handlers common.Handlers
+	ext      *extMock.Service
+}
+
+func Test_Maintenance(t *testing.T) {
+	suite.Run(t, new(MaintenanceTestSuite))
+}
+
+func (suite *MaintenanceTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	settings["dispatcher"].(map[string]interface{})["maintenance"] = true
+
+	// merchant requests are authenticated before maintenance mode is checked by the route
+	suite.ext = &extMock.Service{}
+	suite.ext.On("GetMerchantApiKey", mock2.Anything, &billingext.GetMerchantApiKeyRequest{KeyId: merchantKeyId}).
+		Return(&billingext.GetMerchantApiKeyResponse{
+			Status: pkg.ResponseStatusOk,
+			Item: &billingext.MerchantApiKey{
+				KeyId:      merchantKeyId,
+				MerchantId: bson.NewObjectId().Hex(),
+				ProjectId:  bson.NewObjectId().Hex(),
+				Secret:     merchantKeySecret,
+			},
+		}, nil)
+	srv := common.Services{BillingExt: suite.ext, Billing: &billMock.BillingService{}}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewOrderRoute(set.HandlerSet, set.GlobalConfig)
+		suite.handlers = common.Handlers{
+			NewBinRoute(set.HandlerSet, set.GlobalConfig),
+			NewCountryRoute(set.HandlerSet, set.GlobalConfig),
+			NewDeviceRoute(set.HandlerSet, set.GlobalConfig),
+			NewEventRoute(set.HandlerSet, set.GlobalConfig),
+			NewMerchantRoute(set.HandlerSet, set.GlobalConfig),
+			suite.router,
+			NewPaymentRoute(set.HandlerSet, set.GlobalConfig),
+			NewQuoteRoute(set.HandlerSet, set.GlobalConfig),
+			NewRecurringRoute(set.HandlerSet, set.GlobalConfig),
+			NewWalletRoute(set.HandlerSet, set.GlobalConfig),
+		}
+		return suite.handlers
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *MaintenanceTestSuite) Test_MutatingRoute_Rejected() {
+	bill := &billMock.BillingService{}
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + orderPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"project": "5be2d0b4b0b30d0007383ce6", "amount": 10, "currency": "USD"}`).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorMaintenance, httpErr.Message)
+	assert.Equal(suite.T(), "300", res.Header().Get(common.HeaderRetryAfter))
+	bill.AssertNotCalled(suite.T(), "OrderCreateProcess", mock2.Anything, mock2.Anything)
+}
+
+// Test_WriteRoutes_Rejected walks all routes and checks that every route which creates or changes orders and payments
+// is rejected before billing is called, billing mocks have no expectations and fail the request if they are called
+func (suite *MaintenanceTestSuite) Test_WriteRoutes_Rejected() {
+	e := echo.New()
+	groups := &common.Groups{
+		V1:       e.Group(common.NoAuthGroupPath),
+		V2:       e.Group(common.ApiV2GroupPath),
+		Merchant: e.Group(common.MerchantGroupPath),
+		Root:     e.Group(""),
+	}
+
+	for _, handler := range suite.handlers {
+		handler.Route(groups)
+	}
+
+	checked := 0
+
+	for _, route := range e.Routes() {
+		write := route.Method != http.MethodGet || strings.HasSuffix(route.Path, paymentReturnPath)
+		notGated := false
+
+		for path := range maintenanceNotGatedRoutes {
+			notGated = notGated || strings.HasSuffix(route.Path, path)
+		}
+
+		if !write || notGated {
+			continue
+		}
+
+		segments := strings.Split(route.Path, "/")
+
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = uuid.New().String()
+			}
+		}
+
+		res, _ := suite.caller.Builder().
+			Method(route.Method).
+			Path(strings.Join(segments, "/")).
+			Init(test.ReqInitJSON()).
+			AddHeader(common.HeaderXApiKeyId, merchantKeyId).
+			AddHeader(common.HeaderXApiKey, merchantKeySecret).
+			BodyString(`{}`).
+			Exec(suite.T())
+
+		assert.Equal(suite.T(), http.StatusServiceUnavailable, res.Code, route.Method+" "+route.Path)
+		assert.Equal(suite.T(), "300", res.Header().Get(common.HeaderRetryAfter), route.Method+" "+route.Path)
+		checked++
+	}
+
+	assert.NotZero(suite.T(), checked)
+	suite.ext.AssertNotCalled(suite.T(), "ApplyPromoCode", mock2.Anything, mock2.Anything)
+	suite.ext.AssertNotCalled(suite.T(), "PaymentCreateByWallet", mock2.Anything, mock2.Anything)
+	suite.ext.AssertNotCalled(suite.T(), "ProcessPaymentReturn", mock2.Anything, mock2.Anything)
+}
+
+func (suite *MaintenanceTestSuite) Test_PaylinkShortLink_MaintenancePage() {
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterCode, "abc123").
+		Path(paylinkShortLinkPath).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, res.Code)
+	assert.Equal(suite.T(), "300", res.Header().Get(common.HeaderRetryAfter))
+	suite.ext.AssertNotCalled(suite.T(), "GetPaylinkByShortCode", mock2.Anything, mock2.Anything)
+}
+
+func (suite *MaintenanceTestSuite) Test_NotGatedRoute_Served() {
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, uuid.New().String()).
+		Path(common.NoAuthGroupPath + eventsPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"events": [{"type": "form_opened", "time": 1571043600000}]}`).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusAccepted, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderRetryAfter))
+}
+
+func (suite *MaintenanceTestSuite) Test_SafeRoute_Served() {
+	orderId := uuid.New().String()
+	suite.router.dispatch.OrderStates.Set(orderstate.State{OrderId: orderId, Status: "created"})
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + orderStatusPath).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderRetryAfter))
+}
+
+func (suite *MaintenanceTestSuite) Test_Paylink_MaintenancePage() {
+	bill := &billMock.BillingService{}
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, uuid.New().String()).
+		Path(common.NoAuthGroupPath+paylinkIdPath).
+		AddHeader(common.HeaderAcceptLanguage, "de-DE,de;q=0.9,en;q=0.8").
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, res.Code)
+	assert.Equal(suite.T(), "300", res.Header().Get(common.HeaderRetryAfter))
+	assert.Contains(suite.T(), res.Body.String(), `<html lang="de">`)
+	assert.Contains(suite.T(), res.Body.String(), maintenanceTexts["de"].Title)
+	bill.AssertNotCalled(suite.T(), "OrderCreateByPaylink", mock2.Anything, mock2.Anything)
+}
+
+func (suite *MaintenanceTestSuite) Test_EmbeddedPaylink_MaintenancePage() {
+	bill := &billMock.BillingService{}
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, uuid.New().String()).
+		Path(common.NoAuthGroupPath+paylinkEmbedPath).
+		AddHeader(common.HeaderAcceptLanguage, "xx").
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), maintenanceTexts[maintenanceDefaultLang].Title)
+	bill.AssertNotCalled(suite.T(), "OrderCreateByPaylink", mock2.Anything, mock2.Anything)
+}
+
+func (suite *MaintenanceTestSuite) Test_MaintenanceLang() {
+	cases := map[string]string{
+		"":                         "en",
+		"ru-RU,ru;q=0.9,en;q=0.8":  "ru",
+		"xx, pt-BR;q=0.9":          "pt",
+		"zh-CN,zh;q=0.9":           "en",
+		" FR ; q=1":                "fr",
+		"es-419,es;q=0.9,it;q=0.8": "es",
+	}
+
+	for header, lang := range cases {
+		assert.Equal(suite.T(), lang, getMaintenanceLang(header), header)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"net/http"
+)
+
+const (
+	merchantOrdersPath      = "/orders"
+	merchantOrderStatusPath = "/orders/merchant/:merchant_order_id/status"
+	merchantOrderCancelPath = "/orders/:order_id/cancel"
+)
+
+type MerchantOrderStatusRequest struct {
+	MerchantOrderId string `json:"-" param:"merchant_order_id" validate:"required,max=255"`
+}
+
+type MerchantCancelOrderRequest struct {
+	OrderId string `json:"-" param:"order_id" validate:"required,uuid"`
+	Reason  string `json:"reason" validate:"omitempty,max=255,free_text"`
+}
+
+// MerchantRoute contains operations of the merchant backend, requests are authenticated by api keys of the project
+type MerchantRoute struct {
+	dispatch common.HandlerSet
+	cfg      *common.GlobalConfig
+	provider.LMT
+}
+
+func NewMerchantRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *MerchantRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "MerchantRoute"})
+	return &MerchantRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      globalCfg,
+	}
+}
+
+func (h *MerchantRoute) Route(groups *common.Groups) {
+	groups.Merchant.POST(merchantOrdersPath, h.createOrder, rejectInMaintenance(h.cfg))
+	groups.Merchant.GET(merchantOrderStatusPath, h.getOrderStatus)
+	groups.Merchant.POST(merchantOrderCancelPath, h.cancelOrder, rejectInMaintenance(h.cfg))
+}
+
+// createOrder creates order of the key project, user data is accepted without request signature
+// because the request is already authenticated by the api key
+func (h *MerchantRoute) createOrder(ctx echo.Context) error {
+	key := common.ExtractMerchantKeyContext(ctx)
+	req := &billing.OrderCreateRequest{}
+
+	if err := (&common.OrderJsonBinder{}).Bind(req, ctx); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if req.ProjectId == "" {
+		req.ProjectId = key.ProjectId
+	}
+
+	if req.ProjectId != key.ProjectId {
+		return echo.NewHTTPError(http.StatusForbidden, common.ErrorMerchantProjectForbidden)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	res, err := h.dispatch.Services.Billing.OrderCreateProcess(ctx.Request().Context(), req)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(req, err, pkg.ServiceName, "OrderCreateProcess")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	response := &CreateOrderJsonProjectResponse{
+		Id:             res.Item.Uuid,
+		PaymentFormUrl: h.cfg.Get().OrderInlineFormUrlMask + res.Item.Uuid,
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// getOrderStatus returns status of the key project order by the order id of the merchant
+func (h *MerchantRoute) getOrderStatus(ctx echo.Context) error {
+	req := &MerchantOrderStatusRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	data := &billingext.GetMerchantOrderStatusRequest{
+		ProjectId:       common.ExtractMerchantKeyContext(ctx).ProjectId,
+		MerchantOrderId: req.MerchantOrderId,
+	}
+	res, err := h.dispatch.Services.BillingExt.GetMerchantOrderStatus(ctx.Request().Context(), data)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(data, err, pkg.ServiceName, "GetMerchantOrderStatus")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return ctx.JSON(http.StatusOK, res.Item)
+}
+
+// cancelOrder cancels unpaid order of the key project, cached status of the order is dropped to request it from billing
+func (h *MerchantRoute) cancelOrder(ctx echo.Context) error {
+	req := &MerchantCancelOrderRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	data := &billingext.CancelOrderRequest{
+		OrderId:   req.OrderId,
+		ProjectId: common.ExtractMerchantKeyContext(ctx).ProjectId,
+		Reason:    req.Reason,
+	}
+	res, err := h.dispatch.Services.BillingExt.CancelOrder(ctx.Request().Context(), data)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(data, err, pkg.ServiceName, "CancelOrder")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	h.dispatch.OrderStates.Delete(req.OrderId)
+
+	return ctx.NoContent(http.StatusNoContent)
+}
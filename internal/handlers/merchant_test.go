@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	billMock "github.com/paysuper/paysuper-billing-server/pkg/mocks"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/merchantauth"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	merchantKeyId     = "key_id"
+	merchantKeySecret = "key_secret"
+)
+
+type MerchantTestSuite struct {
+	suite.Suite
+	router    *MerchantRoute
+	caller    *test.EchoReqResCaller
+	ext       *extMock.Service
+	projectId string
+}
+
+func Test_Merchant(t *testing.T) {
+	suite.Run(t, new(MerchantTestSuite))
+}
+
+func (suite *MerchantTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	global := settings["dispatcher"].(map[string]interface{})["global"].(map[string]interface{})
+	global["merchantKeyCacheSize"] = 10
+	global["merchantKeyCacheTtlMinutes"] = 10
+	global["merchantRateLimit"] = 100
+	global["merchantSignatureMaxSkewSeconds"] = 300
+	global["merchantIpRateLimit"] = 5
+
+	suite.projectId = bson.NewObjectId().Hex()
+	suite.ext = &extMock.Service{}
+	srv := common.Services{BillingExt: suite.ext}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewMerchantRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *MerchantTestSuite) onMerchantKey(rateLimit int32) {
+	suite.ext.On("GetMerchantApiKey", mock2.Anything, &billingext.GetMerchantApiKeyRequest{KeyId: merchantKeyId}).
+		Return(&billingext.GetMerchantApiKeyResponse{
+			Status: pkg.ResponseStatusOk,
+			Item: &billingext.MerchantApiKey{
+				KeyId:      merchantKeyId,
+				MerchantId: bson.NewObjectId().Hex(),
+				ProjectId:  suite.projectId,
+				Secret:     merchantKeySecret,
+				RateLimit:  rateLimit,
+			},
+		}, nil)
+}
+
+func (suite *MerchantTestSuite) onOrderCreate() *billMock.BillingService {
+	bill := &billMock.BillingService{}
+	bill.On("OrderCreateProcess", mock2.Anything, mock2.MatchedBy(func(req *billing.OrderCreateRequest) bool {
+		return req.ProjectId == suite.projectId
+	})).Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: uuid.New().String()}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+	return bill
+}
+
+func (suite *MerchantTestSuite) execute(method, path, body string, headers map[string]string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(method).
+		Path(common.MerchantGroupPath + path).
+		Init(test.ReqInitJSON()).
+		BodyString(body).
+		SetHeaders(headers).
+		Exec(suite.T())
+}
+
+func (suite *MerchantTestSuite) apiKeyHeaders() map[string]string {
+	return map[string]string{
+		common.HeaderXApiKeyId: merchantKeyId,
+		common.HeaderXApiKey:   merchantKeySecret,
+	}
+}
+
+func (suite *MerchantTestSuite) hmacHeaders(method, path, body string, signedAt time.Time) map[string]string {
+	return map[string]string{
+		common.HeaderXApiKeyId:     merchantKeyId,
+		common.HeaderXApiTimestamp: strconv.FormatInt(signedAt.Unix(), 10),
+		common.HeaderXApiHmac: merchantauth.Sign(
+			merchantKeySecret,
+			signedAt.Unix(),
+			method,
+			common.MerchantGroupPath+path,
+			[]byte(body),
+		),
+	}
+}
+
+func (suite *MerchantTestSuite) assertHttpError(err error, code int, msg interface{}) {
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	if assert.True(suite.T(), ok) {
+		assert.Equal(suite.T(), code, httpErr.Code)
+		assert.Equal(suite.T(), msg, httpErr.Message)
+	}
+}
+
+func (suite *MerchantTestSuite) Test_CreateOrder_ApiKey_Ok() {
+	suite.onMerchantKey(0)
+	bill := suite.onOrderCreate()
+
+	res, err := suite.execute(http.MethodPost, merchantOrdersPath, `{"user": {"id": "1", "email": "test@unit.test"}}`, suite.apiKeyHeaders())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	rsp := &CreateOrderJsonProjectResponse{}
+	assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	assert.NotEmpty(suite.T(), rsp.Id)
+	assert.Equal(suite.T(), suite.router.cfg.Get().OrderInlineFormUrlMask+rsp.Id, rsp.PaymentFormUrl)
+
+	// user data doesn't need a request signature because the request is authenticated by the api key
+	bill.AssertNotCalled(suite.T(), "CheckProjectRequestSignature", mock2.Anything, mock2.Anything)
+}
+
+func (suite *MerchantTestSuite) Test_CreateOrder_Hmac_Ok() {
+	suite.onMerchantKey(0)
+	suite.onOrderCreate()
+
+	body := fmt.Sprintf(`{"project": "%s", "user": {"id": "1"}}`, suite.projectId)
+
+	for i := 0; i < 2; i++ {
+		headers := suite.hmacHeaders(http.MethodPost, merchantOrdersPath, body, time.Now().Add(-time.Duration(i)*time.Second))
+		res, err := suite.execute(http.MethodPost, merchantOrdersPath, body, headers)
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+	}
+
+	suite.ext.AssertNumberOfCalls(suite.T(), "GetMerchantApiKey", 1)
+}
+
+func (suite *MerchantTestSuite) Test_CreateOrder_ProjectForbidden() {
+	suite.onMerchantKey(0)
+	bill := suite.onOrderCreate()
+
+	body := fmt.Sprintf(`{"project": "%s"}`, bson.NewObjectId().Hex())
+	_, err := suite.execute(http.MethodPost, merchantOrdersPath, body, suite.apiKeyHeaders())
+
+	suite.assertHttpError(err, http.StatusForbidden, common.ErrorMerchantProjectForbidden)
+	bill.AssertNotCalled(suite.T(), "OrderCreateProcess", mock2.Anything, mock2.Anything)
+}
+
+func (suite *MerchantTestSuite) Test_Hmac_InvalidSignature() {
+	suite.onMerchantKey(0)
+
+	headers := suite.hmacHeaders(http.MethodPost, merchantOrdersPath, `{}`, time.Now())
+	_, err := suite.execute(http.MethodPost, merchantOrdersPath, `{"user": {"id": "1"}}`, headers)
+
+	suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+}
+
+func (suite *MerchantTestSuite) Test_Hmac_Replayed() {
+	suite.onMerchantKey(0)
+	bill := suite.onOrderCreate()
+
+	body := fmt.Sprintf(`{"project": "%s", "user": {"id": "1"}}`, suite.projectId)
+	headers := suite.hmacHeaders(http.MethodPost, merchantOrdersPath, body, time.Now())
+
+	res, err := suite.execute(http.MethodPost, merchantOrdersPath, body, headers)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	_, err = suite.execute(http.MethodPost, merchantOrdersPath, body, headers)
+
+	suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantRequestReplayed)
+	bill.AssertNumberOfCalls(suite.T(), "OrderCreateProcess", 1)
+}
+
+func (suite *MerchantTestSuite) Test_Hmac_Expired() {
+	suite.onMerchantKey(0)
+
+	body := `{}`
+	headers := suite.hmacHeaders(http.MethodPost, merchantOrdersPath, body, time.Now().Add(-time.Hour))
+	_, err := suite.execute(http.MethodPost, merchantOrdersPath, body, headers)
+
+	suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantRequestExpired)
+
+	headers[common.HeaderXApiTimestamp] = "not-a-timestamp"
+	_, err = suite.execute(http.MethodPost, merchantOrdersPath, body, headers)
+
+	suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantRequestExpired)
+}
+
+func (suite *MerchantTestSuite) Test_ApiKey_InvalidSecret() {
+	suite.onMerchantKey(0)
+
+	headers := suite.apiKeyHeaders()
+	headers[common.HeaderXApiKey] = "wrong_secret"
+	_, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, headers)
+
+	suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+}
+
+func (suite *MerchantTestSuite) Test_Unauthorized() {
+	_, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, map[string]string{})
+	suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+
+	suite.ext.On("GetMerchantApiKey", mock2.Anything, mock2.Anything).
+		Return(&billingext.GetMerchantApiKeyResponse{Status: pkg.ResponseStatusNotFound}, nil)
+
+	for i := 0; i < 2; i++ {
+		_, err = suite.execute(http.MethodPost, merchantOrdersPath, `{}`, suite.apiKeyHeaders())
+		suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+	}
+
+	// unknown keys are cached too
+	suite.ext.AssertNumberOfCalls(suite.T(), "GetMerchantApiKey", 1)
+}
+
+func (suite *MerchantTestSuite) Test_AuthUnavailable() {
+	suite.ext.On("GetMerchantApiKey", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("billing unavailable"))
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, suite.apiKeyHeaders())
+		suite.assertHttpError(err, http.StatusServiceUnavailable, common.ErrorMerchantAuthUnavailable)
+	}
+
+	// failed lookups aren't cached
+	suite.ext.AssertNumberOfCalls(suite.T(), "GetMerchantApiKey", 2)
+}
+
+func (suite *MerchantTestSuite) Test_RateLimit() {
+	suite.onMerchantKey(2)
+	suite.onOrderCreate()
+
+	for i := 0; i < 2; i++ {
+		res, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, suite.apiKeyHeaders())
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+	}
+
+	res, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, suite.apiKeyHeaders())
+
+	suite.assertHttpError(err, http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	assert.NotEmpty(suite.T(), res.Header().Get(common.HeaderRetryAfter))
+}
+
+func (suite *MerchantTestSuite) Test_IpRateLimit() {
+	suite.ext.On("GetMerchantApiKey", mock2.Anything, mock2.Anything).
+		Return(&billingext.GetMerchantApiKeyResponse{Status: pkg.ResponseStatusNotFound}, nil)
+
+	for i := 0; i < 5; i++ {
+		headers := suite.apiKeyHeaders()
+		headers[common.HeaderXApiKeyId] = fmt.Sprintf("unknown_key_%d", i)
+		_, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, headers)
+
+		suite.assertHttpError(err, http.StatusUnauthorized, common.ErrorMerchantUnauthorized)
+	}
+
+	res, err := suite.execute(http.MethodPost, merchantOrdersPath, `{}`, suite.apiKeyHeaders())
+
+	suite.assertHttpError(err, http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	assert.NotEmpty(suite.T(), res.Header().Get(common.HeaderRetryAfter))
+	// unknown keys are limited before the lookup in billing
+	suite.ext.AssertNumberOfCalls(suite.T(), "GetMerchantApiKey", 5)
+}
+
+func (suite *MerchantTestSuite) Test_GetOrderStatus_Ok() {
+	suite.onMerchantKey(0)
+
+	orderId := uuid.New().String()
+	req := &billingext.GetMerchantOrderStatusRequest{ProjectId: suite.projectId, MerchantOrderId: "merchant-1"}
+	suite.ext.On("GetMerchantOrderStatus", mock2.Anything, req).
+		Return(&billingext.GetOrderStatusResponse{
+			Status: pkg.ResponseStatusOk,
+			Item:   &billingext.OrderStatus{OrderId: orderId, MerchantOrderId: "merchant-1", Status: "processed", UpdatedAt: 1},
+		}, nil)
+
+	path := "/orders/merchant/merchant-1/status"
+	res, err := suite.execute(http.MethodGet, path, "", suite.hmacHeaders(http.MethodGet, path, "", time.Now()))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	rsp := &billingext.OrderStatus{}
+	assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	assert.Equal(suite.T(), orderId, rsp.OrderId)
+	assert.Equal(suite.T(), "processed", rsp.Status)
+}
+
+func (suite *MerchantTestSuite) Test_GetOrderStatus_NotFound() {
+	suite.onMerchantKey(0)
+
+	msg := &grpc.ResponseErrorMessage{Code: "ma000001", Message: "order not found"}
+	suite.ext.On("GetMerchantOrderStatus", mock2.Anything, mock2.Anything).
+		Return(&billingext.GetOrderStatusResponse{Status: pkg.ResponseStatusNotFound, Message: msg}, nil)
+
+	_, err := suite.execute(http.MethodGet, "/orders/merchant/unknown/status", "", suite.apiKeyHeaders())
+
+	suite.assertHttpError(err, http.StatusNotFound, msg)
+}
+
+func (suite *MerchantTestSuite) Test_CancelOrder_Ok() {
+	suite.onMerchantKey(0)
+
+	orderId := uuid.New().String()
+	suite.router.dispatch.OrderStates.Set(orderstate.State{OrderId: orderId, Status: "created"})
+
+	req := &billingext.CancelOrderRequest{OrderId: orderId, ProjectId: suite.projectId, Reason: "out of stock"}
+	suite.ext.On("CancelOrder", mock2.Anything, req).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+
+	res, err := suite.execute(
+		http.MethodPost,
+		"/orders/"+orderId+"/cancel",
+		`{"reason": "out of stock"}`,
+		suite.apiKeyHeaders(),
+	)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+
+	_, ok := suite.router.dispatch.OrderStates.Get(orderId)
+	assert.False(suite.T(), ok)
+}
+
+func (suite *MerchantTestSuite) Test_CancelOrder_Paid() {
+	suite.onMerchantKey(0)
+
+	msg := &grpc.ResponseErrorMessage{Code: "ma000002", Message: "order is already paid"}
+	suite.ext.On("CancelOrder", mock2.Anything, mock2.Anything).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+
+	_, err := suite.execute(http.MethodPost, "/orders/"+uuid.New().String()+"/cancel", `{}`, suite.apiKeyHeaders())
+
+	suite.assertHttpError(err, http.StatusBadRequest, msg)
+}
+
+func (suite *MerchantTestSuite) Test_CancelOrder_IncorrectOrderId() {
+	suite.onMerchantKey(0)
+
+	_, err := suite.execute(http.MethodPost, "/orders/not-an-order/cancel", `{}`, suite.apiKeyHeaders())
+
+	assert.Error(suite.T(), err)
+	suite.ext.AssertNotCalled(suite.T(), "CancelOrder", mock2.Anything, mock2.Anything)
+}
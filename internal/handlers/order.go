@@ -2,16 +2,30 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	u "github.com/PuerkitoBio/purell"
 	"github.com/labstack/echo/v4"
+	"github.com/micro/go-micro/broker"
 	"github.com/paysuper/paysuper-billing-server/pkg"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-checkout/internal/helpers"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
+	"github.com/paysuper/paysuper-checkout/pkg/origins"
+	"github.com/paysuper/paysuper-checkout/pkg/qr"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"github.com/paysuper/paysuper-checkout/pkg/token"
+	"github.com/paysuper/paysuper-checkout/pkg/vat"
+	"github.com/paysuper/paysuper-checkout/pkg/visits"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -25,19 +39,51 @@ const (
 	orderNotifySalesPath     = "/orders/:order_id/notify_sale"
 	orderNotifyNewRegionPath = "/orders/:order_id/notify_new_region"
 	orderPlatformPath        = "/orders/:order_id/platform"
+	orderPromoCodePath       = "/orders/:order_id/promo_code"
+	orderStatusPath          = "/orders/:order_id/status"
 	orderReceiptPath         = "/orders/receipt/:receipt_id/:order_id"
+	orderRefundRequestPath   = "/orders/receipt/:receipt_id/:order_id/refund_request"
 	paylinkIdPath            = "/paylink/:id"
+	paylinkEmbedPath         = "/paylink/:id/embed"
+	paylinkQrCodePngPath     = "/paylink/:id/qr.png"
+	paylinkQrCodeSvgPath     = "/paylink/:id/qr.svg"
+	paylinkShortLinkPath     = "/p/:code"
 )
 
 const (
-	errorTemplateName = "error.html"
+	errorTemplateName          = "error.html"
+	paylinkPreviewTemplateName = "paylink_preview.html"
+	paylinkEmbedTemplateName   = "paylink_embed.html"
 )
 
+// BillingAddressPostalCode checks zip of the billing address by the rules of the address country
+// instead of the US only rules of the billing request
+type BillingAddressPostalCode struct {
+	Country string `validate:"required,len=2"`
+	Zip     string `validate:"omitempty,postal_code=Country"`
+}
+
+type BusinessBillingAddressRequest struct {
+	Company string `json:"company" validate:"required,max=255,free_text"`
+	VatId   string `json:"vat_id" validate:"required,max=32"`
+}
+
+type BusinessBillingAddressResponse struct {
+	Company       string                   `json:"company"`
+	VatId         string                   `json:"vat_id"`
+	VatIdVerified bool                     `json:"vat_id_verified"`
+	Tax           *billingext.TaxBreakdown `json:"tax"`
+}
+
 type CreateOrderJsonProjectResponse struct {
 	Id             string `json:"id"`
 	PaymentFormUrl string `json:"payment_form_url"`
 }
 
+type OrderQuoteTokenRequest struct {
+	QuoteToken string `json:"quote_token"`
+}
+
 type ListOrdersRequest struct {
 	MerchantId    string   `json:"merchant_id" validate:"required,hexadecimal,len=24"`
 	FileType      string   `json:"file_type" validate:"required"`
@@ -51,33 +97,184 @@ type ListOrdersRequest struct {
 	PmDateTo      int64    `json:"pm_date_to" validate:"omitempty,numeric,gt=0"`
 }
 
+type RefundRequestRequest struct {
+	ReceiptId string `json:"-" param:"receipt_id" validate:"required,uuid"`
+	OrderId   string `json:"-" param:"order_id" validate:"required,uuid"`
+	Reason    string `json:"reason" validate:"required,oneof=not_received not_as_described duplicate_payment unauthorized_payment other"`
+	Comment   string `json:"comment" validate:"omitempty,max=1000,free_text"`
+}
+
+type PromoCodeRequest struct {
+	OrderId   string `json:"-" param:"order_id" validate:"required,uuid"`
+	PromoCode string `json:"code" validate:"required,promo_code"`
+}
+
+type RemovePromoCodeRequest struct {
+	OrderId string `json:"-" param:"order_id" validate:"required,uuid"`
+}
+
+type OrderStatusRequest struct {
+	OrderId string `json:"-" param:"order_id" validate:"required,uuid"`
+}
+
+type OrderReceiptResponse struct {
+	*billing.OrderReceipt
+	RefundRequest *billingext.CustomerRefundRequest `json:"refund_request,omitempty"`
+}
+
+type PaylinkPreviewTemplateData struct {
+	Paylink *billingext.PaylinkPreview
+	Url     string
+}
+
+type PaylinkQrCodeRequest struct {
+	Id          string `json:"-" param:"id" validate:"required"`
+	Size        int    `json:"-" query:"size" validate:"omitempty,min=64"`
+	Level       string `json:"-" query:"level" validate:"omitempty,oneof=L M Q H"`
+	UtmSource   string `json:"-" query:"utm_source" validate:"omitempty,max=255"`
+	UtmMedium   string `json:"-" query:"utm_medium" validate:"omitempty,max=255"`
+	UtmCampaign string `json:"-" query:"utm_campaign" validate:"omitempty,max=255"`
+}
+
+type PaylinkEmbedTemplateData struct {
+	OrderId      string
+	FormUrl      string
+	FormOrigin   string
+	ParentOrigin string
+	JsLibraryUrl string
+}
+
 type OrderRoute struct {
-	dispatch common.HandlerSet
-	cfg      *common.Config
+	dispatch      common.HandlerSet
+	cfg           *common.GlobalConfig
+	refundLimiter *ratelimit.Limiter
+	promoLimiter  *ratelimit.Limiter
+	signer        *token.Signer
+	attrSigner    *token.Signer
+	crawler       *helpers.CrawlerDetector
+	attribution   *helpers.AttributionExtractor
+	bots          *helpers.CrawlerDetector
+	visits        *visits.Aggregator
+	vatChecker    vat.Checker
+	statuses      broker.Subscriber
 	provider.LMT
 }
 
-func NewOrderRoute(set common.HandlerSet, cfg *common.Config) *OrderRoute {
+func NewOrderRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *OrderRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "OrderRoute"})
-	return &OrderRoute{
+	cfg := globalCfg.Get()
+	attrSigner := token.NewSigner(helpers.AttributionTokenPurpose, cfg.SigningSecret)
+
+	route := &OrderRoute{
 		dispatch: set,
 		LMT:      &set.AwareSet,
-		cfg:      cfg,
+		cfg:      globalCfg,
+		refundLimiter: ratelimit.New(
+			cfg.RefundRequestLimit,
+			time.Duration(cfg.RefundRequestLimitWindowHours)*time.Hour,
+		),
+		promoLimiter: ratelimit.New(
+			cfg.PromoCodeAttemptsLimit,
+			time.Duration(cfg.PromoCodeAttemptsLimitWindowMinutes)*time.Minute,
+		),
+		signer:     token.NewSigner(quoteTokenPurpose, cfg.SigningSecret),
+		attrSigner: attrSigner,
+		crawler:    helpers.NewCrawlerDetector(cfg.PaylinkCrawlerUserAgents),
+		attribution: helpers.NewAttributionExtractor(
+			cfg.AttributionParameters,
+			attrSigner,
+			common.AttributionCookiesName,
+			time.Duration(cfg.AttributionCookieLifetimeHours)*time.Hour,
+		),
+		bots: helpers.NewCrawlerDetector(cfg.PaylinkVisitBotUserAgents),
 	}
+	route.visits = visits.New(route.flushPaylinkVisits, visits.Options{
+		Interval:    time.Duration(cfg.PaylinkVisitsFlushIntervalSeconds) * time.Second,
+		Window:      time.Duration(cfg.PaylinkVisitsWindowMinutes) * time.Minute,
+		MaxPending:  cfg.PaylinkVisitsMaxPending,
+		MaxVisitors: cfg.PaylinkVisitsMaxVisitors,
+	})
+
+	if cfg.VatCheckerUrl != "" {
+		route.vatChecker = vat.NewViesChecker(cfg.VatCheckerUrl, time.Duration(cfg.VatCheckerTimeoutSeconds)*time.Second)
+	}
+
+	return route
+}
+
+// Reload applies limits, signing secret, attribution parameters and user agent lists of the reloaded configuration
+func (h *OrderRoute) Reload(_ context.Context) {
+	cfg := h.cfg.Get()
+	h.refundLimiter.SetLimit(cfg.RefundRequestLimit, time.Duration(cfg.RefundRequestLimitWindowHours)*time.Hour)
+	h.promoLimiter.SetLimit(cfg.PromoCodeAttemptsLimit, time.Duration(cfg.PromoCodeAttemptsLimitWindowMinutes)*time.Minute)
+	h.signer.SetSecret(cfg.SigningSecret)
+	h.attrSigner.SetSecret(cfg.SigningSecret)
+	h.crawler.Set(cfg.PaylinkCrawlerUserAgents)
+	h.attribution.Set(cfg.AttributionParameters, time.Duration(cfg.AttributionCookieLifetimeHours)*time.Hour)
+	h.bots.Set(cfg.PaylinkVisitBotUserAgents)
+}
+
+// Start subscribes to order status notifications of billing and runs periodic sending of paylink visits
+func (h *OrderRoute) Start(ctx context.Context) error {
+	if topic := h.cfg.Get().OrderStatusTopic; h.dispatch.Services.Broker != nil && topic != "" {
+		sub, err := orderstate.Subscribe(h.dispatch.Services.Broker, topic, h.dispatch.OrderStates, func(err error) {
+			h.L().Error("order status notification skipped", logger.PairArgs("err", err.Error()))
+		})
+
+		if err != nil {
+			return err
+		}
+
+		h.statuses = sub
+	}
+
+	go h.visits.Run(ctx, func(err error) {
+		h.L().Error("paylink visits sending failed", logger.PairArgs("err", err.Error()))
+	})
+	return nil
+}
+
+// Shutdown unsubscribes from order status notifications and sends paylink visits which weren't sent yet
+func (h *OrderRoute) Shutdown(ctx context.Context) {
+	if h.statuses != nil {
+		if err := h.statuses.Unsubscribe(); err != nil {
+			h.L().Error("order status unsubscribe failed", logger.PairArgs("err", err.Error()))
+		}
+	}
+
+	if err := h.visits.Close(ctx); err != nil {
+		h.L().Error("paylink visits sending on shutdown failed", logger.PairArgs("err", err.Error()))
+	}
+}
+
+// InvalidateCaches purges order statuses received from billing notifications
+func (h *OrderRoute) InvalidateCaches(_ context.Context) []string {
+	h.dispatch.OrderStates.Purge()
+	return []string{"order_status"}
 }
 
 func (h *OrderRoute) Route(groups *common.Groups) {
-	groups.Common.POST(orderPath, h.createJson)
-	groups.Common.GET(orderIdPath, h.getPaymentFormData)
-	groups.Common.POST(orderReCreatePath, h.recreateOrder)
-	groups.Common.PATCH(orderLanguagePath, h.changeLanguage)
-	groups.Common.PATCH(orderCustomerPath, h.changeCustomer)
-	groups.Common.POST(orderBillingAddressPath, h.processBillingAddress)
-	groups.Common.POST(orderNotifySalesPath, h.notifySale)
-	groups.Common.POST(orderNotifyNewRegionPath, h.notifyNewRegion)
-	groups.Common.POST(orderPlatformPath, h.changePlatform)
-	groups.Common.GET(orderReceiptPath, h.getReceipt)
-	groups.Common.GET(paylinkIdPath, h.getOrderForPaylink)
+	groups.Shared(func(group *echo.Group) {
+		group.POST(orderPath, h.createJson, rejectInMaintenance(h.cfg))
+		group.GET(orderIdPath, h.getPaymentFormData)
+		group.POST(orderReCreatePath, h.recreateOrder, rejectInMaintenance(h.cfg))
+		group.PATCH(orderLanguagePath, h.changeLanguage, rejectInMaintenance(h.cfg))
+		group.PATCH(orderCustomerPath, h.changeCustomer, rejectInMaintenance(h.cfg))
+		group.POST(orderBillingAddressPath, h.processBillingAddress, rejectInMaintenance(h.cfg))
+		group.POST(orderNotifySalesPath, h.notifySale, rejectInMaintenance(h.cfg))
+		group.POST(orderNotifyNewRegionPath, h.notifyNewRegion, rejectInMaintenance(h.cfg))
+		group.POST(orderPlatformPath, h.changePlatform, rejectInMaintenance(h.cfg))
+		group.POST(orderPromoCodePath, h.applyPromoCode, rejectInMaintenance(h.cfg))
+		group.DELETE(orderPromoCodePath, h.removePromoCode, rejectInMaintenance(h.cfg))
+		group.GET(orderStatusPath, h.getOrderStatus)
+		group.GET(orderReceiptPath, h.getReceipt)
+		group.POST(orderRefundRequestPath, h.createRefundRequest, rejectInMaintenance(h.cfg))
+		group.GET(paylinkIdPath, h.getOrderForPaylink)
+		group.GET(paylinkEmbedPath, h.getEmbeddedOrderForPaylink)
+		group.GET(paylinkQrCodePngPath, h.getPaylinkQrCodePng)
+		group.GET(paylinkQrCodeSvgPath, h.getPaylinkQrCodeSvg)
+	})
+	groups.Root.GET(paylinkShortLinkPath, h.getOrderForPaylinkShortLink)
 }
 
 func (h *OrderRoute) createJson(ctx echo.Context) error {
@@ -102,8 +299,15 @@ func (h *OrderRoute) createJson(ctx echo.Context) error {
 		}
 	}
 
+	quote, httpErr := h.getQuoteTokenClaims(req)
+
+	if httpErr != nil {
+		return httpErr
+	}
+
 	ctxReq := ctx.Request().Context()
 	req.IssuerUrl = ctx.Request().Header.Get(common.HeaderReferer)
+	attribution := h.attribution.Extract(ctx)
 
 	var (
 		order *billing.Order
@@ -140,15 +344,64 @@ func (h *OrderRoute) createJson(ctx echo.Context) error {
 		order = rsp.Item
 	}
 
+	if quote != nil {
+		req := &billingext.LockOrderPriceRequest{
+			OrderId: order.Uuid,
+			QuoteId: quote.QuoteId,
+		}
+		rsp, err := h.dispatch.Services.BillingExt.LockOrderPrice(ctxReq, req)
+
+		if err != nil {
+			return h.dispatch.SrvCallHandler(req, err, pkg.ServiceName, "LockOrderPrice")
+		}
+
+		if rsp.Status != pkg.ResponseStatusOk {
+			return echo.NewHTTPError(int(rsp.Status), rsp.Message)
+		}
+	}
+
+	h.setOrderAttribution(ctx, order.Uuid, attribution)
+
 	response := &CreateOrderJsonProjectResponse{
 		Id:             order.Uuid,
-		PaymentFormUrl: h.cfg.OrderInlineFormUrlMask + order.Uuid,
+		PaymentFormUrl: h.cfg.Get().OrderInlineFormUrlMask + order.Uuid,
 	}
 
 	return ctx.JSON(http.StatusOK, response)
 }
 
+// getQuoteTokenClaims returns content of the price quote token passed with order creation request
+func (h *OrderRoute) getQuoteTokenClaims(req *billing.OrderCreateRequest) (*quoteTokenClaims, *echo.HTTPError) {
+	if req.RawBody == "" {
+		return nil, nil
+	}
+
+	tokenReq := &OrderQuoteTokenRequest{}
+
+	if err := json.Unmarshal([]byte(req.RawBody), tokenReq); err != nil || tokenReq.QuoteToken == "" {
+		return nil, nil
+	}
+
+	claims := &quoteTokenClaims{}
+
+	if err := h.signer.Verify(tokenReq.QuoteToken, claims); err != nil {
+		if err == token.ErrExpired {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, common.ErrorQuoteTokenExpired)
+		}
+
+		return nil, echo.NewHTTPError(http.StatusBadRequest, common.ErrorQuoteTokenInvalid)
+	}
+
+	if claims.ProjectId != req.ProjectId {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, common.ErrorQuoteTokenInvalid)
+	}
+
+	return claims, nil
+}
+
 func (h *OrderRoute) getPaymentFormData(ctx echo.Context) error {
+	cfg := h.cfg.Get()
+
 	req := &grpc.PaymentFormJsonDataRequest{
 		Locale:  ctx.Request().Header.Get(common.HeaderAcceptLanguage),
 		Ip:      ctx.RealIP(),
@@ -175,8 +428,8 @@ func (h *OrderRoute) getPaymentFormData(ctx echo.Context) error {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
-	expire := time.Now().Add(time.Duration(h.cfg.CustomerTokenCookiesLifetimeHours) * time.Hour)
-	helpers.SetResponseCookie(ctx, common.CustomerTokenCookiesName, res.Cookie, h.cfg.CookieDomain, expire)
+	expire := time.Now().Add(time.Duration(cfg.CustomerTokenCookiesLifetimeHours) * time.Hour)
+	helpers.SetResponseCookie(ctx, common.CustomerTokenCookiesName, res.Cookie, cfg.CookieDomain, expire)
 
 	return ctx.JSON(http.StatusOK, res.Item)
 }
@@ -199,9 +452,10 @@ func (h *OrderRoute) recreateOrder(ctx echo.Context) error {
 	}
 
 	order := res.Item
+
 	response := &CreateOrderJsonProjectResponse{
 		Id:             order.Uuid,
-		PaymentFormUrl: h.cfg.OrderInlineFormUrlMask + order.Uuid,
+		PaymentFormUrl: h.cfg.Get().OrderInlineFormUrlMask + order.Uuid,
 	}
 
 	return ctx.JSON(http.StatusOK, response)
@@ -256,13 +510,35 @@ func (h *OrderRoute) changeCustomer(ctx echo.Context) error {
 }
 
 func (h *OrderRoute) processBillingAddress(ctx echo.Context) error {
+	cfg := h.cfg.Get()
+
 	req := &grpc.ProcessBillingAddressRequest{
 		Cookie: helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
 		Ip:     ctx.RealIP(),
 	}
 
-	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
-		return err
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err := h.dispatch.Validate.StructExcept(req, "Zip"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	if err := h.dispatch.Validate.Struct(&BillingAddressPostalCode{Country: req.Country, Zip: req.Zip}); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	business := &BusinessBillingAddressRequest{}
+
+	if strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if err := json.Unmarshal(common.ExtractRawBodyContext(ctx), business); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+		}
+	}
+
+	if business.Company != "" || business.VatId != "" {
+		return h.processBusinessBillingAddress(ctx, req, business)
 	}
 
 	res, err := h.dispatch.Services.Billing.ProcessBillingAddress(ctx.Request().Context(), req)
@@ -275,12 +551,91 @@ func (h *OrderRoute) processBillingAddress(ctx echo.Context) error {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
-	expire := time.Now().Add(time.Duration(h.cfg.CustomerTokenCookiesLifetimeHours) * time.Hour)
-	helpers.SetResponseCookie(ctx, common.CustomerTokenCookiesName, res.Cookie, h.cfg.CookieDomain, expire)
+	h.setOrderIpCountry(ctx, req.OrderId, req.Country)
+
+	expire := time.Now().Add(time.Duration(cfg.CustomerTokenCookiesLifetimeHours) * time.Hour)
+	helpers.SetResponseCookie(ctx, common.CustomerTokenCookiesName, res.Cookie, cfg.CookieDomain, expire)
 
 	return ctx.JSON(http.StatusOK, res.Item)
 }
 
+// processBusinessBillingAddress checks vat id of the business customer and returns tax breakdown recalculated by billing
+func (h *OrderRoute) processBusinessBillingAddress(
+	ctx echo.Context,
+	req *grpc.ProcessBillingAddressRequest,
+	business *BusinessBillingAddressRequest,
+) error {
+	cfg := h.cfg.Get()
+
+	if err := h.dispatch.Validate.Struct(business); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	vatId, err := vat.Parse(business.VatId, req.Country)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorIncorrectVatId)
+	}
+
+	if vatId.Country != vat.CountryPrefix(req.Country) {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorVatIdCountryMismatch)
+	}
+
+	addressReq := &billingext.BusinessBillingAddressRequest{
+		OrderId: req.OrderId,
+		Country: req.Country,
+		Zip:     req.Zip,
+		Company: strings.TrimSpace(business.Company),
+		VatId:   vatId.String(),
+		Cookie:  req.Cookie,
+		Ip:      req.Ip,
+	}
+
+	if h.vatChecker != nil {
+		check, err := h.vatChecker.Check(ctx.Request().Context(), vatId)
+
+		if err != nil {
+			h.L().Error("vat id online check failed", logger.PairArgs("err", err.Error(), "vat_id", vatId.String()))
+
+			if cfg.VatCheckerStrict {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, common.ErrorVatIdCheckUnavailable)
+			}
+		} else {
+			if !check.Valid {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorVatIdNotRegistered)
+			}
+
+			addressReq.VatIdVerified = true
+			addressReq.VatIdName = check.Name
+			addressReq.VatIdAddress = check.Address
+		}
+	}
+
+	res, err := h.dispatch.Services.BillingExt.ProcessBusinessBillingAddress(ctx.Request().Context(), addressReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(addressReq, err, pkg.ServiceName, "ProcessBusinessBillingAddress")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	h.setOrderIpCountry(ctx, req.OrderId, req.Country)
+
+	expire := time.Now().Add(time.Duration(cfg.CustomerTokenCookiesLifetimeHours) * time.Hour)
+	helpers.SetResponseCookie(ctx, common.CustomerTokenCookiesName, res.Item.Cookie, cfg.CookieDomain, expire)
+
+	rsp := &BusinessBillingAddressResponse{
+		Company:       addressReq.Company,
+		VatId:         addressReq.VatId,
+		VatIdVerified: addressReq.VatIdVerified,
+		Tax:           res.Item.Tax,
+	}
+
+	return ctx.JSON(http.StatusOK, rsp)
+}
+
 func (h *OrderRoute) notifySale(ctx echo.Context) error {
 	req := &grpc.SetUserNotifyRequest{}
 
@@ -297,6 +652,39 @@ func (h *OrderRoute) notifySale(ctx echo.Context) error {
 	return ctx.NoContent(http.StatusNoContent)
 }
 
+// getOrderStatus returns status of the order received from billing notifications, billing is requested if status is unknown
+func (h *OrderRoute) getOrderStatus(ctx echo.Context) error {
+	req := &OrderStatusRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	if state, ok := h.dispatch.OrderStates.Get(req.OrderId); ok {
+		return ctx.JSON(http.StatusOK, state)
+	}
+
+	data := &billingext.GetOrderStatusRequest{OrderId: req.OrderId}
+	res, err := h.dispatch.Services.BillingExt.GetOrderStatus(ctx.Request().Context(), data)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(data, err, pkg.ServiceName, "GetOrderStatus")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	state := orderstate.State{
+		OrderId:   res.Item.OrderId,
+		Status:    res.Item.Status,
+		UpdatedAt: res.Item.UpdatedAt,
+	}
+	h.dispatch.OrderStates.Set(state)
+
+	return ctx.JSON(http.StatusOK, state)
+}
+
 func (h *OrderRoute) notifyNewRegion(ctx echo.Context) error {
 	req := &grpc.SetUserNotifyRequest{}
 
@@ -333,6 +721,71 @@ func (h *OrderRoute) changePlatform(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, res.Item)
 }
 
+func (h *OrderRoute) applyPromoCode(ctx echo.Context) error {
+	req := &PromoCodeRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	// every attempt is counted to prevent promo codes brute forcing
+	if !h.promoLimiter.Allow(req.OrderId) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorPromoCodeAttemptsExceeded)
+	}
+
+	promoReq := &billingext.ApplyPromoCodeRequest{
+		OrderId:        req.OrderId,
+		Code:           req.PromoCode,
+		Cookie:         helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
+		Ip:             ctx.RealIP(),
+		AcceptLanguage: ctx.Request().Header.Get(common.HeaderAcceptLanguage),
+		UserAgent:      ctx.Request().Header.Get(common.HeaderUserAgent),
+	}
+	res, err := h.dispatch.Services.BillingExt.ApplyPromoCode(ctx.Request().Context(), promoReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(promoReq, err, pkg.ServiceName, "ApplyPromoCode")
+	}
+
+	// attempts for orders unknown to billing aren't kept to not fill the limiter with random order ids,
+	// wrong promo codes of the existing orders are still counted
+	if res.Status == pkg.ResponseStatusNotFound && res.Message != nil &&
+		res.Message.Code == billingext.PromoCodeErrorOrderNotFound {
+		h.promoLimiter.Reset(req.OrderId)
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return ctx.JSON(http.StatusOK, res.Item)
+}
+
+func (h *OrderRoute) removePromoCode(ctx echo.Context) error {
+	req := &RemovePromoCodeRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	promoReq := &billingext.RemovePromoCodeRequest{
+		OrderId: req.OrderId,
+		Cookie:  helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
+		Ip:      ctx.RealIP(),
+	}
+	res, err := h.dispatch.Services.BillingExt.RemovePromoCode(ctx.Request().Context(), promoReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(promoReq, err, pkg.ServiceName, "RemovePromoCode")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return ctx.JSON(http.StatusOK, res.Item)
+}
+
 func (h *OrderRoute) getReceipt(ctx echo.Context) error {
 	req := &grpc.OrderReceiptRequest{}
 
@@ -350,32 +803,193 @@ func (h *OrderRoute) getReceipt(ctx echo.Context) error {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
-	return ctx.JSON(http.StatusOK, res.Receipt)
+	response := &OrderReceiptResponse{OrderReceipt: res.Receipt}
+	refundReq := &billingext.GetCustomerRefundRequest{OrderId: req.OrderId}
+	refundRes, err := h.dispatch.Services.BillingExt.GetCustomerRefundRequest(ctx.Request().Context(), refundReq)
+
+	// receipt must be returned even if refund request status isn't available
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "GetCustomerRefundRequest", refundReq)
+	} else if refundRes.Status == pkg.ResponseStatusOk {
+		response.RefundRequest = refundRes.Item
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *OrderRoute) createRefundRequest(ctx echo.Context) error {
+	req := &RefundRequestRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	receiptReq := &grpc.OrderReceiptRequest{ReceiptId: req.ReceiptId, OrderId: req.OrderId}
+	receiptRes, err := h.dispatch.Services.Billing.OrderReceipt(ctx.Request().Context(), receiptReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(receiptReq, err, pkg.ServiceName, "OrderReceipt")
+	}
+
+	if receiptRes.Status != http.StatusOK {
+		return echo.NewHTTPError(int(receiptRes.Status), receiptRes.Message)
+	}
+
+	if !h.refundLimiter.Allow(req.OrderId) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	}
+
+	refundReq := &billingext.CreateCustomerRefundRequest{
+		OrderId:   req.OrderId,
+		ReceiptId: req.ReceiptId,
+		Reason:    req.Reason,
+		Comment:   req.Comment,
+		Ip:        ctx.RealIP(),
+		Cookie:    helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
+	}
+	res, err := h.dispatch.Services.BillingExt.CreateCustomerRefundRequest(ctx.Request().Context(), refundReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(refundReq, err, pkg.ServiceName, "CreateCustomerRefundRequest")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return ctx.JSON(http.StatusOK, res.Item)
 }
 
 func (h *OrderRoute) getOrderForPaylink(ctx echo.Context) error {
+	cfg := h.cfg.Get()
+
 	paylinkId := ctx.Param(common.RequestParameterId)
 
-	go func() {
-		req := &grpc.PaylinkRequestById{Id: paylinkId}
-		// call with background context to prevent request abandoning when redirect will bw returned in response below
-		_, err := h.dispatch.Services.Billing.IncrPaylinkVisits(context.Background(), req)
+	// Link preview crawlers must not create orders and increase paylink visits
+	if h.crawler.IsCrawler(ctx.Request().UserAgent()) {
+		return h.getPaylinkPreview(ctx, paylinkId)
+	}
 
-		if err != nil {
-			common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "IncrPaylinkVisits", req)
-		}
-	}()
+	if common.ExtractMaintenanceContext(ctx) {
+		return renderMaintenance(ctx, cfg.MaintenanceRetryAfterSeconds)
+	}
+
+	res, err := h.orderCreateByPaylink(ctx, paylinkId, false)
+
+	if err != nil {
+		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+	}
+
+	if res.Status != http.StatusOK {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	inlineFormRedirectUrl, err := u.NormalizeURLString(
+		cfg.OrderInlineFormUrlMask+res.Item.Uuid+"?"+ctx.QueryParams().Encode(),
+		u.FlagsUsuallySafeGreedy|u.FlagRemoveDuplicateSlashes,
+	)
+
+	if err != nil {
+		h.L().Error("NormalizeURLString failed", logger.PairArgs("err", err.Error()))
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	return ctx.Redirect(http.StatusFound, inlineFormRedirectUrl)
+}
+
+func (h *OrderRoute) getEmbeddedOrderForPaylink(ctx echo.Context) error {
+	cfg := h.cfg.Get()
+
+	paylinkId := ctx.Param(common.RequestParameterId)
+
+	if h.crawler.IsCrawler(ctx.Request().UserAgent()) {
+		return h.getPaylinkPreview(ctx, paylinkId)
+	}
+
+	if common.ExtractMaintenanceContext(ctx) {
+		return renderMaintenance(ctx, cfg.MaintenanceRetryAfterSeconds)
+	}
+
+	res, err := h.orderCreateByPaylink(ctx, paylinkId, true)
 
-	qParams := ctx.QueryParams()
+	if err != nil {
+		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+	}
+
+	if res.Status != http.StatusOK {
+		return ctx.Render(int(res.Status), errorTemplateName, map[string]interface{}{})
+	}
+
+	formUrl, err := u.NormalizeURLString(
+		cfg.OrderInlineFormUrlMask+res.Item.Uuid+"?"+ctx.QueryParams().Encode(),
+		u.FlagsUsuallySafeGreedy|u.FlagRemoveDuplicateSlashes,
+	)
+
+	if err != nil {
+		h.L().Error("NormalizeURLString failed", logger.PairArgs("err", err.Error()))
+		return ctx.Render(http.StatusInternalServerError, errorTemplateName, map[string]interface{}{})
+	}
+
+	parsedFormUrl, err := url.Parse(formUrl)
+
+	if err != nil {
+		h.L().Error("payment form url parsing failed", logger.PairArgs("err", err.Error()))
+		return ctx.Render(http.StatusInternalServerError, errorTemplateName, map[string]interface{}{})
+	}
+
+	data := &PaylinkEmbedTemplateData{
+		OrderId:      res.Item.Uuid,
+		FormUrl:      formUrl,
+		FormOrigin:   parsedFormUrl.Scheme + "://" + parsedFormUrl.Host,
+		ParentOrigin: h.getEmbedParentOrigin(ctx, res.Item.Uuid),
+		JsLibraryUrl: cfg.PaymentFormJsLibraryUrl,
+	}
+
+	return ctx.Render(http.StatusOK, paylinkEmbedTemplateName, data)
+}
+
+// getEmbedParentOrigin returns origin of the page embedding the paylink if it's allowed for the project of the order,
+// empty origin is returned if it's unknown and the embedded page doesn't send messages to the parent window then
+func (h *OrderRoute) getEmbedParentOrigin(ctx echo.Context, orderId string) string {
+	referer, err := url.Parse(ctx.Request().Header.Get(common.HeaderReferer))
+
+	if err != nil || referer.Scheme == "" || referer.Host == "" {
+		return ""
+	}
+
+	origin := referer.Scheme + "://" + referer.Host
+	req := &billingext.GetProjectAllowedOriginsRequest{OrderId: orderId}
+	res, err := h.dispatch.Services.BillingExt.GetProjectAllowedOrigins(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "GetProjectAllowedOrigins", req)
+		return ""
+	}
+
+	if res.Status != pkg.ResponseStatusOk || res.Item == nil || !origins.Allowed(res.Item.Origins, origin) {
+		return ""
+	}
+
+	return origin
+}
+
+// orderCreateByPaylink increases paylink visits and creates order by paylink with attribution of the request
+func (h *OrderRoute) orderCreateByPaylink(
+	ctx echo.Context,
+	paylinkId string,
+	isEmbedded bool,
+) (*grpc.OrderCreateProcessResponse, error) {
+	h.countPaylinkVisit(ctx, paylinkId)
+	attribution := h.attribution.Extract(ctx)
 
 	req := &billing.OrderCreateByPaylink{
 		PaylinkId:   paylinkId,
 		PayerIp:     ctx.RealIP(),
 		IssuerUrl:   ctx.Request().Header.Get(common.HeaderReferer),
-		UtmSource:   qParams.Get(common.QueryParameterNameUtmSource),
-		UtmMedium:   qParams.Get(common.QueryParameterNameUtmMedium),
-		UtmCampaign: qParams.Get(common.QueryParameterNameUtmCampaign),
-		IsEmbedded:  false,
+		UtmSource:   attribution.Get(common.QueryParameterNameUtmSource),
+		UtmMedium:   attribution.Get(common.QueryParameterNameUtmMedium),
+		UtmCampaign: attribution.Get(common.QueryParameterNameUtmCampaign),
+		IsEmbedded:  isEmbedded,
 		Cookie:      helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
 	}
 
@@ -383,22 +997,232 @@ func (h *OrderRoute) getOrderForPaylink(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "OrderCreateByPaylink", req)
+		return nil, err
+	}
+
+	if res.Status == http.StatusOK {
+		h.setOrderAttribution(ctx, res.Item.Uuid, attribution)
+	}
+
+	return res, nil
+}
+
+// countPaylinkVisit registers paylink visit, bots visits aren't counted
+func (h *OrderRoute) countPaylinkVisit(ctx echo.Context, paylinkId string) {
+	userAgent := ctx.Request().UserAgent()
+
+	if userAgent == "" || h.bots.IsCrawler(userAgent) {
+		return
+	}
+
+	visitor := helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName)
+
+	if visitor == "" {
+		visitor = ctx.RealIP() + "|" + userAgent
+	}
+
+	h.visits.Add(paylinkId, fmt.Sprintf("%x", sha1.Sum([]byte(visitor))))
+}
+
+func (h *OrderRoute) flushPaylinkVisits(ctx context.Context, counts map[string]int) error {
+	req := &billingext.IncrPaylinkVisitsBatchRequest{}
+
+	for paylinkId, count := range counts {
+		req.Visits = append(req.Visits, &billingext.PaylinkVisits{PaylinkId: paylinkId, Count: count})
+	}
+
+	res, err := h.dispatch.Services.BillingExt.IncrPaylinkVisitsBatch(ctx, req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "IncrPaylinkVisitsBatch", req)
+		return err
+	}
+
+	// visits rejected by billing aren't sent again
+	if res.Status != pkg.ResponseStatusOk {
+		h.L().Error("paylink visits rejected by billing", logger.PairArgs("status", res.Status, "message", res.Message))
+	}
+
+	return nil
+}
+
+// setOrderAttribution passes attribution to billing and keeps it in the cookie for the next requests,
+// attribution errors don't break the order processing
+func (h *OrderRoute) setOrderAttribution(ctx echo.Context, orderId string, attribution *helpers.Attribution) {
+	if attribution.IsEmpty() {
+		return
+	}
+
+	if err := h.attribution.Save(ctx, attribution, h.cfg.Get().CookieDomain); err != nil {
+		h.L().Error("attribution cookie signing failed", logger.PairArgs("err", err.Error()))
+	}
+
+	req := &billingext.SetOrderAttributionRequest{
+		OrderId:    orderId,
+		Parameters: attribution.Parameters,
+		Referrer:   attribution.Referrer,
+		LandedAt:   attribution.LandedAt,
+	}
+	res, err := h.dispatch.Services.BillingExt.SetOrderAttribution(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "SetOrderAttribution", req)
+		return
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		h.L().Error("order attribution saving failed", logger.PairArgs("order_id", orderId, "message", res.Message))
+	}
+}
+
+// setOrderIpCountry passes country resolved by GeoIP to billing and flags its mismatch with the billing address country,
+// nothing is sent if GeoIP is disabled or country of the ip address is unknown
+func (h *OrderRoute) setOrderIpCountry(ctx echo.Context, orderId, billingCountry string) {
+	ipCountry := common.ExtractIpCountryContext(ctx)
+
+	if ipCountry == "" {
+		return
+	}
+
+	req := &billingext.SetOrderIpCountryRequest{
+		OrderId:         orderId,
+		Ip:              ctx.RealIP(),
+		IpCountry:       ipCountry,
+		BillingCountry:  billingCountry,
+		CountryMismatch: !strings.EqualFold(ipCountry, billingCountry),
+	}
+	res, err := h.dispatch.Services.BillingExt.SetOrderIpCountry(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "SetOrderIpCountry", req)
+		return
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		h.L().Error("order ip country saving failed", logger.PairArgs("order_id", orderId, "message", res.Message))
+	}
+}
+
+func (h *OrderRoute) getPaylinkPreview(ctx echo.Context, paylinkId string) error {
+	req := &billingext.PaylinkRequest{Id: paylinkId}
+	res, err := h.dispatch.Services.BillingExt.GetPaylinkPreview(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "GetPaylinkPreview", req)
 		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
 	}
 
-	if res.Status != http.StatusOK {
+	if res.Status != pkg.ResponseStatusOk {
+		return ctx.Render(int(res.Status), errorTemplateName, map[string]interface{}{})
+	}
+
+	data := &PaylinkPreviewTemplateData{
+		Paylink: res.Item,
+		Url:     ctx.Scheme() + "://" + ctx.Request().Host + ctx.Request().URL.RequestURI(),
+	}
+
+	return ctx.Render(http.StatusOK, paylinkPreviewTemplateName, data)
+}
+
+func (h *OrderRoute) getPaylinkQrCodePng(ctx echo.Context) error {
+	return h.getPaylinkQrCode(ctx, qr.PNG, "image/png")
+}
+
+func (h *OrderRoute) getPaylinkQrCodeSvg(ctx echo.Context) error {
+	return h.getPaylinkQrCode(ctx, qr.SVG, "image/svg+xml")
+}
+
+// getPaylinkQrCode returns QR code image with paylink short url, UTM parameters from the request are added to the url
+func (h *OrderRoute) getPaylinkQrCode(
+	ctx echo.Context,
+	encode func(content, level string, size int) ([]byte, error),
+	contentType string,
+) error {
+	cfg := h.cfg.Get()
+
+	req := &PaylinkQrCodeRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	if req.Size == 0 {
+		req.Size = cfg.PaylinkQrCodeSize
+	}
+
+	if req.Size > cfg.PaylinkQrCodeMaxSize {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorQrCodeSizeTooLarge)
+	}
+
+	if req.Level == "" {
+		req.Level = cfg.PaylinkQrCodeLevel
+	}
+
+	shortCodeReq := &billingext.PaylinkRequest{Id: req.Id}
+	res, err := h.dispatch.Services.BillingExt.GetPaylinkShortCode(ctx.Request().Context(), shortCodeReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(shortCodeReq, err, pkg.ServiceName, "GetPaylinkShortCode")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
-	inlineFormRedirectUrl, err := u.NormalizeURLString(
-		h.cfg.OrderInlineFormUrlMask+res.Item.Uuid+"?"+qParams.Encode(),
-		u.FlagsUsuallySafeGreedy|u.FlagRemoveDuplicateSlashes,
-	)
+	shortUrl := cfg.PaylinkShortUrlMask
+
+	if shortUrl == "" {
+		shortUrl = ctx.Scheme() + "://" + ctx.Request().Host + strings.Replace(paylinkShortLinkPath, ":code", "", 1)
+	}
+
+	shortUrl += url.PathEscape(res.Item.Code)
+	utm := url.Values{}
+
+	for name, value := range map[string]string{
+		common.QueryParameterNameUtmSource:   req.UtmSource,
+		common.QueryParameterNameUtmMedium:   req.UtmMedium,
+		common.QueryParameterNameUtmCampaign: req.UtmCampaign,
+	} {
+		if value != "" {
+			utm.Set(name, value)
+		}
+	}
+
+	if len(utm) > 0 {
+		shortUrl += "?" + utm.Encode()
+	}
+
+	image, err := encode(shortUrl, req.Level, req.Size)
 
 	if err != nil {
-		h.L().Error("NormalizeURLString failed", logger.PairArgs("err", err.Error()))
+		h.L().Error("qr code encoding failed", logger.PairArgs("err", err.Error(), "url", shortUrl))
 		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
-	return ctx.Redirect(http.StatusFound, inlineFormRedirectUrl)
+	return ctx.Blob(http.StatusOK, contentType, image)
+}
+
+// getOrderForPaylinkShortLink resolves paylink by short code and processes it as a paylink url
+func (h *OrderRoute) getOrderForPaylinkShortLink(ctx echo.Context) error {
+	// crawlers get paylink preview in maintenance mode, so only their short links are resolved by billing
+	if common.ExtractMaintenanceContext(ctx) && !h.crawler.IsCrawler(ctx.Request().UserAgent()) {
+		return renderMaintenance(ctx, h.cfg.Get().MaintenanceRetryAfterSeconds)
+	}
+
+	req := &billingext.PaylinkShortCodeRequest{Code: ctx.Param(common.RequestParameterCode)}
+	res, err := h.dispatch.Services.BillingExt.GetPaylinkByShortCode(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "GetPaylinkByShortCode", req)
+		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return ctx.Render(int(res.Status), errorTemplateName, map[string]interface{}{})
+	}
+
+	ctx.SetParamNames(common.RequestParameterId)
+	ctx.SetParamValues(res.Item.PaylinkId)
+
+	return h.getOrderForPaylink(ctx)
 }
@@ -1,21 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/broker/memory"
 	"github.com/paysuper/paysuper-billing-server/pkg"
 	billMock "github.com/paysuper/paysuper-billing-server/pkg/mocks"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/helpers"
 	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"github.com/paysuper/paysuper-checkout/pkg/vat"
 	"github.com/stretchr/testify/assert"
 	mock2 "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -267,6 +278,100 @@ func (suite *OrderTestSuite) Test_CreateJson_UserWithBadSignature_BillingReturnE
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
+func (suite *OrderTestSuite) Test_CreateJson_Ok_WithQuoteToken() {
+	orderId := uuid.New().String()
+	projectId := "ffffffffffffffffffffffff"
+	quoteToken, _ := suite.router.signer.Sign(&quoteTokenClaims{QuoteId: "quote_id", ProjectId: projectId}, time.Now().Add(time.Minute))
+	body := fmt.Sprintf(`{"project": "%s", "quote_token": "%s"}`, projectId, quoteToken)
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("LockOrderPrice", mock2.Anything, &billingext.LockOrderPriceRequest{OrderId: orderId, QuoteId: "quote_id"}).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeCreateJsonTest(body, map[string]string{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), orderId)
+	ext.AssertExpectations(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_CreateJson_QuoteTokenInvalid() {
+	body := `{"project": "ffffffffffffffffffffffff", "quote_token": "invalid"}`
+	res, err := suite.executeCreateJsonTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorQuoteTokenInvalid, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateJson_QuoteTokenProjectMismatch() {
+	quoteToken, _ := suite.router.signer.Sign(&quoteTokenClaims{QuoteId: "quote_id", ProjectId: "eeeeeeeeeeeeeeeeeeeeeeee"}, time.Now().Add(time.Minute))
+	body := fmt.Sprintf(`{"project": "ffffffffffffffffffffffff", "quote_token": "%s"}`, quoteToken)
+	res, err := suite.executeCreateJsonTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorQuoteTokenInvalid, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateJson_QuoteTokenExpired() {
+	projectId := "ffffffffffffffffffffffff"
+	quoteToken, _ := suite.router.signer.Sign(&quoteTokenClaims{QuoteId: "quote_id", ProjectId: projectId}, time.Now().Add(-time.Minute))
+	body := fmt.Sprintf(`{"project": "%s", "quote_token": "%s"}`, projectId, quoteToken)
+	res, err := suite.executeCreateJsonTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorQuoteTokenExpired, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateJson_LockOrderPrice_BillingResponseStatusError() {
+	orderId := uuid.New().String()
+	projectId := "ffffffffffffffffffffffff"
+	quoteToken, _ := suite.router.signer.Sign(&quoteTokenClaims{QuoteId: "quote_id", ProjectId: projectId}, time.Now().Add(time.Minute))
+	body := fmt.Sprintf(`{"project": "%s", "quote_token": "%s"}`, projectId, quoteToken)
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("LockOrderPrice", mock2.Anything, mock2.Anything).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeCreateJsonTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
 // Test GetPaymentFormData route
 func (suite *OrderTestSuite) executeGetPaymentFormDataTest(orderId string, cookie *http.Cookie) (*httptest.ResponseRecorder, error) {
 	return suite.caller.Builder().
@@ -283,7 +388,7 @@ func (suite *OrderTestSuite) Test_GetPaymentFormData_Ok() {
 	cookie := new(http.Cookie)
 	cookie.Name = common.CustomerTokenCookiesName
 	cookie.Value = "ffffffffffffffffffffffff"
-	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.CustomerTokenCookiesLifetimeHours) * time.Second)
+	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.Get().CustomerTokenCookiesLifetimeHours) * time.Second)
 	cookie.HttpOnly = true
 
 	bill := &billMock.BillingService{}
@@ -304,7 +409,7 @@ func (suite *OrderTestSuite) Test_GetPaymentFormData_OrderValidationError() {
 	cookie := new(http.Cookie)
 	cookie.Name = common.CustomerTokenCookiesName
 	cookie.Value = "ffffffffffffffffffffffff"
-	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.CustomerTokenCookiesLifetimeHours) * time.Second)
+	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.Get().CustomerTokenCookiesLifetimeHours) * time.Second)
 	cookie.HttpOnly = true
 
 	res, err := suite.executeGetPaymentFormDataTest(orderId, cookie)
@@ -323,7 +428,7 @@ func (suite *OrderTestSuite) Test_GetPaymentFormData_BillingReturnError() {
 	cookie := new(http.Cookie)
 	cookie.Name = common.CustomerTokenCookiesName
 	cookie.Value = "ffffffffffffffffffffffff"
-	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.CustomerTokenCookiesLifetimeHours) * time.Second)
+	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.Get().CustomerTokenCookiesLifetimeHours) * time.Second)
 	cookie.HttpOnly = true
 
 	bill := &billMock.BillingService{}
@@ -348,7 +453,7 @@ func (suite *OrderTestSuite) Test_GetPaymentFormData_BillingResponseStatusError(
 	cookie := new(http.Cookie)
 	cookie.Name = common.CustomerTokenCookiesName
 	cookie.Value = "ffffffffffffffffffffffff"
-	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.CustomerTokenCookiesLifetimeHours) * time.Second)
+	cookie.Expires = time.Now().Add(time.Duration(suite.router.cfg.Get().CustomerTokenCookiesLifetimeHours) * time.Second)
 	cookie.HttpOnly = true
 
 	bill := &billMock.BillingService{}
@@ -701,6 +806,62 @@ func (suite *OrderTestSuite) Test_ProcessBillingAddress_Ok() {
 	assert.NotEmpty(suite.T(), res.Result().Cookies())
 }
 
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_IpCountryMismatch() {
+	orderId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("ProcessBillingAddress", mock2.Anything, mock2.Anything).
+		Return(&grpc.ProcessBillingAddressResponse{Status: pkg.ResponseStatusOk, Cookie: "setcookie"}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("SetOrderIpCountry", mock2.Anything, mock2.MatchedBy(func(req *billingext.SetOrderIpCountryRequest) bool {
+		return req.OrderId == orderId && req.IpCountry == "DE" && req.BillingCountry == "US" && req.CountryMismatch
+	})).Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + orderBillingAddressPath).
+		Init(test.ReqInitJSON()).
+		Init(test.ReqInitIpCountry("DE")).
+		BodyString(`{"country": "US", "zip": "98001"}`).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	ext.AssertExpectations(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_IpCountrySavingError() {
+	orderId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("ProcessBillingAddress", mock2.Anything, mock2.Anything).
+		Return(&grpc.ProcessBillingAddressResponse{Status: pkg.ResponseStatusOk, Cookie: "setcookie"}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("SetOrderIpCountry", mock2.Anything, mock2.MatchedBy(func(req *billingext.SetOrderIpCountryRequest) bool {
+		return req.IpCountry == "US" && req.BillingCountry == "US" && !req.CountryMismatch
+	})).Return(nil, errors.New("some error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + orderBillingAddressPath).
+		Init(test.ReqInitJSON()).
+		Init(test.ReqInitIpCountry("US")).
+		BodyString(`{"country": "US", "zip": "98001"}`).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	ext.AssertExpectations(suite.T())
+}
+
 func (suite *OrderTestSuite) Test_ProcessBillingAddress_OrderIdEmptyError() {
 	orderId := ""
 	body := `{"country": "US", "zip": "98001"}`
@@ -759,11 +920,229 @@ func (suite *OrderTestSuite) Test_ProcessBillingAddress_ValidationZipError() {
 
 	msg, ok := httpErr.Message.(grpc.ResponseErrorMessage)
 	assert.True(suite.T(), ok)
-	assert.Equal(suite.T(), common.ErrorMessageIncorrectZip.Message, msg.Message)
+	assert.Equal(suite.T(), common.ErrorMessageIncorrectPostalCode.Message, msg.Message)
 	assert.Regexp(suite.T(), "Zip", msg.Details)
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_PostalCodeByCountry_Ok() {
+	cases := map[string]string{
+		"GB": "SW1A 1AA",
+		"CA": "k1a 0b1",
+		"DE": "10115",
+		"BR": "01310-100",
+		"JP": "100-0001",
+		"KZ": "050000",
+	}
+
+	bill := &billMock.BillingService{}
+	bill.On("ProcessBillingAddress", mock2.Anything, mock2.Anything).
+		Return(&grpc.ProcessBillingAddressResponse{Status: pkg.ResponseStatusOk, Cookie: "setcookie"}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	for country, zip := range cases {
+		body := `{"country": "` + country + `", "zip": "` + zip + `"}`
+		res, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+		assert.NoError(suite.T(), err, country)
+		assert.Equal(suite.T(), http.StatusOK, res.Code, country)
+	}
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_PostalCodeByCountry_Error() {
+	cases := map[string]string{
+		"GB": "12345",
+		"CA": "K1A0B",
+		"DE": "1011",
+		"BR": "01310",
+		"JP": "1000001-1",
+		"KZ": "!",
+	}
+
+	for country, zip := range cases {
+		body := `{"country": "` + country + `", "zip": "` + zip + `"}`
+		_, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+		assert.Error(suite.T(), err, country)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+
+		msg, ok := httpErr.Message.(grpc.ResponseErrorMessage)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), common.ErrorMessageIncorrectPostalCode.Code, msg.Code, country)
+	}
+}
+
+func (suite *OrderTestSuite) newVatCheckerStub() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ms/DE/vat/136695976":
+			_, _ = w.Write([]byte(`{"isValid":true,"userError":"VALID","name":"Company GmbH","address":"Berlin"}`))
+		case "/ms/DE/vat/811191002":
+			_, _ = w.Write([]byte(`{"isValid":false,"userError":"INVALID"}`))
+		default:
+			_, _ = w.Write([]byte(`{"isValid":false,"userError":"MS_UNAVAILABLE"}`))
+		}
+	}))
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_Ok() {
+	orderId := uuid.New().String()
+	body := `{"country": "DE", "zip": "10115", "company": " Company GmbH ", "vat_id": "de 136 695 976"}`
+	tax := &billingext.TaxBreakdown{Amount: 100, TotalAmount: 100, Currency: "EUR", ReverseCharge: true}
+
+	ext := &extMock.Service{}
+	ext.On("ProcessBusinessBillingAddress", mock2.Anything, mock2.MatchedBy(func(req *billingext.BusinessBillingAddressRequest) bool {
+		return req.OrderId == orderId && req.Country == "DE" && req.Company == "Company GmbH" &&
+			req.VatId == "DE136695976" && !req.VatIdVerified
+	})).Return(&billingext.BusinessBillingAddressResponse{
+		Status: pkg.ResponseStatusOk,
+		Item:   &billingext.BusinessBillingAddressResult{Tax: tax, Cookie: "setcookie"},
+	}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessBillingAddressTest(orderId, body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.JSONEq(
+		suite.T(),
+		`{"company":"Company GmbH","vat_id":"DE136695976","vat_id_verified":false,"tax":{"amount":100,"tax":0,"tax_rate":0,"total_amount":100,"currency":"EUR","reverse_charge":true}}`,
+		res.Body.String(),
+	)
+	assert.NotEmpty(suite.T(), res.Result().Cookies())
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_ValidationError() {
+	cases := map[string]grpc.ResponseErrorMessage{
+		`{"country": "DE", "zip": "10115", "company": "Company GmbH", "vat_id": "DE136695977"}`: common.ErrorIncorrectVatId,
+		`{"country": "US", "zip": "98001", "company": "Company", "vat_id": "123456789"}`:        common.ErrorIncorrectVatId,
+		`{"country": "FR", "zip": "75001", "company": "Company GmbH", "vat_id": "DE136695976"}`: common.ErrorVatIdCountryMismatch,
+		`{"country": "US", "zip": "98001", "company": "Company GmbH", "vat_id": "DE136695976"}`: common.ErrorVatIdCountryMismatch,
+	}
+
+	for body, expected := range cases {
+		_, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+		assert.Error(suite.T(), err)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Equal(suite.T(), expected, httpErr.Message, body)
+	}
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_CompanyRequired() {
+	body := `{"country": "DE", "zip": "10115", "vat_id": "DE136695976"}`
+
+	_, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), common.ErrorIncorrectCompanyName.Message, httpErr.Message)
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_VatIdVerified() {
+	server := suite.newVatCheckerStub()
+	defer server.Close()
+	suite.router.vatChecker = vat.NewViesChecker(server.URL, time.Second)
+
+	body := `{"country": "DE", "zip": "10115", "company": "Company GmbH", "vat_id": "DE136695976"}`
+
+	ext := &extMock.Service{}
+	ext.On("ProcessBusinessBillingAddress", mock2.Anything, mock2.MatchedBy(func(req *billingext.BusinessBillingAddressRequest) bool {
+		return req.VatIdVerified && req.VatIdName == "Company GmbH" && req.VatIdAddress == "Berlin"
+	})).Return(&billingext.BusinessBillingAddressResponse{
+		Status: pkg.ResponseStatusOk,
+		Item:   &billingext.BusinessBillingAddressResult{Tax: &billingext.TaxBreakdown{}, Cookie: "setcookie"},
+	}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `"vat_id_verified":true`)
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_VatIdNotRegistered() {
+	server := suite.newVatCheckerStub()
+	defer server.Close()
+	suite.router.vatChecker = vat.NewViesChecker(server.URL, time.Second)
+
+	body := `{"country": "DE", "zip": "10115", "company": "Company GmbH", "vat_id": "DE811191002"}`
+
+	_, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorVatIdNotRegistered, httpErr.Message)
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_VatCheckerUnavailable() {
+	server := suite.newVatCheckerStub()
+	defer server.Close()
+	suite.router.vatChecker = vat.NewViesChecker(server.URL, time.Second)
+
+	body := `{"country": "FR", "zip": "75001", "company": "Company SA", "vat_id": "FR40303265045"}`
+
+	ext := &extMock.Service{}
+	ext.On("ProcessBusinessBillingAddress", mock2.Anything, mock2.MatchedBy(func(req *billingext.BusinessBillingAddressRequest) bool {
+		return !req.VatIdVerified
+	})).Return(&billingext.BusinessBillingAddressResponse{
+		Status: pkg.ResponseStatusOk,
+		Item:   &billingext.BusinessBillingAddressResult{Tax: &billingext.TaxBreakdown{}},
+	}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	cfg := *suite.router.cfg.Get()
+	cfg.VatCheckerStrict = true
+	suite.router.cfg, err = test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorVatIdCheckUnavailable, httpErr.Message)
+}
+
+func (suite *OrderTestSuite) Test_ProcessBillingAddress_Business_BillingResponseStatusError() {
+	body := `{"country": "DE", "zip": "10115", "company": "Company GmbH", "vat_id": "DE136695976"}`
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("ProcessBusinessBillingAddress", mock2.Anything, mock2.Anything).
+		Return(&billingext.BusinessBillingAddressResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeProcessBillingAddressTest(uuid.New().String(), body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+}
+
 func (suite *OrderTestSuite) Test_ProcessBillingAddress_BillingReturnError() {
 	orderId := uuid.New().String()
 	body := `{"country": "US", "zip": "98001"}`
@@ -1100,57 +1479,79 @@ func (suite *OrderTestSuite) Test_ChangePlatformPayment_BillingResponseStatusErr
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-// Test GetReceipt route
-func (suite *OrderTestSuite) executeGetReceiptTest(orderId string, receiptId string) (*httptest.ResponseRecorder, error) {
+// Test ApplyPromoCode route
+func (suite *OrderTestSuite) executePromoCodeTest(method, orderId, body string) (*httptest.ResponseRecorder, error) {
 	return suite.caller.Builder().
-		Method(http.MethodGet).
-		Params(":"+common.RequestParameterOrderId, orderId, ":"+common.RequestParameterReceiptId, receiptId).
-		Path(common.NoAuthGroupPath + orderReceiptPath).
+		Method(method).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + orderPromoCodePath).
 		Init(test.ReqInitJSON()).
+		BodyString(body).
 		Exec(suite.T())
 }
 
-func (suite *OrderTestSuite) Test_GetReceipt_Ok() {
+func (suite *OrderTestSuite) Test_ApplyPromoCode_Ok() {
 	orderId := uuid.New().String()
-	receiptId := uuid.New().String()
+	body := `{"code": "SUMMER-2020"}`
+	item := &billingext.PromoCodeResponseItem{
+		OrderAmounts: billingext.OrderAmounts{Amount: 9, TotalAmount: 10.8, Currency: "USD"},
+		PromoCode:    "SUMMER-2020",
+		Discount:     1,
+	}
 
-	bill := &billMock.BillingService{}
-	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
-		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
-	suite.router.dispatch.Services.Billing = bill
+	ext := &extMock.Service{}
+	ext.On("ApplyPromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusOk, Item: item}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
 
-	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+	res, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `"total_amount":10.8`)
+	assert.Contains(suite.T(), res.Body.String(), `"discount":1`)
+}
+
+func (suite *OrderTestSuite) Test_ApplyPromoCode_ValidationError() {
+	orderId := uuid.New().String()
+	body := `{"code": "bad code!"}`
+
+	res, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectPromoCode.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-func (suite *OrderTestSuite) Test_GetReceipt_ValidationError() {
+func (suite *OrderTestSuite) Test_ApplyPromoCode_OrderIdValidationError() {
 	orderId := "string"
-	receiptId := uuid.New().String()
+	body := `{"code": "SUMMER-2020"}`
 
-	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+	res, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
 
 	assert.Error(suite.T(), err)
 
 	httpErr, ok := err.(*echo.HTTPError)
 	assert.True(suite.T(), ok)
 	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
-	assert.Regexp(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message)
+	assert.Equal(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-func (suite *OrderTestSuite) Test_GetReceipt_BillingReturnError() {
+func (suite *OrderTestSuite) Test_ApplyPromoCode_BillingReturnError() {
 	orderId := uuid.New().String()
-	receiptId := uuid.New().String()
+	body := `{"code": "SUMMER-2020"}`
 
-	bill := &billMock.BillingService{}
-	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+	ext := &extMock.Service{}
+	ext.On("ApplyPromoCode", mock2.Anything, mock2.Anything).
 		Return(nil, errors.New("error"))
-	suite.router.dispatch.Services.Billing = bill
+	suite.router.dispatch.Services.BillingExt = ext
 
-	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+	res, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
 
 	assert.Error(suite.T(), err)
 
@@ -1161,17 +1562,17 @@ func (suite *OrderTestSuite) Test_GetReceipt_BillingReturnError() {
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-func (suite *OrderTestSuite) Test_GetReceipt_BillingResponseStatusError() {
+func (suite *OrderTestSuite) Test_ApplyPromoCode_BillingResponseStatusError() {
 	orderId := uuid.New().String()
-	receiptId := uuid.New().String()
+	body := `{"code": "SUMMER-2020"}`
 	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
 
-	bill := &billMock.BillingService{}
-	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
-		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
-	suite.router.dispatch.Services.Billing = bill
+	ext := &extMock.Service{}
+	ext.On("ApplyPromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
 
-	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+	res, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
 
 	assert.Error(suite.T(), err)
 
@@ -1182,73 +1583,856 @@ func (suite *OrderTestSuite) Test_GetReceipt_BillingResponseStatusError() {
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-// Test GetOrderForPaylink route
-func (suite *OrderTestSuite) executeGetOrderForPaylinkTest(id string) (*httptest.ResponseRecorder, error) {
-	return suite.caller.Builder().
-		Method(http.MethodGet).
-		Params(":"+common.RequestParameterId, id).
-		Path(common.NoAuthGroupPath + paylinkIdPath).
-		Init(test.ReqInitJSON()).
-		Exec(suite.T())
-}
+func (suite *OrderTestSuite) Test_ApplyPromoCode_UnknownOrderNotCounted() {
+	msg := &grpc.ResponseErrorMessage{Message: "order not found", Code: billingext.PromoCodeErrorOrderNotFound}
 
-func (suite *OrderTestSuite) Test_GetOrderForPaylink_Ok() {
-	id := uuid.New().String()
+	ext := &extMock.Service{}
+	ext.On("ApplyPromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusNotFound, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
 
-	bill := &billMock.BillingService{}
-	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
-	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
-		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
-	suite.router.dispatch.Services.Billing = bill
+	suite.router.promoLimiter = ratelimit.New(2, time.Hour)
 
-	res, err := suite.executeGetOrderForPaylinkTest(id)
+	for i := 0; i < 3; i++ {
+		_, err := suite.executePromoCodeTest(http.MethodPost, uuid.New().String(), `{"code": "SUMMER-2020"}`)
 
-	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), http.StatusFound, res.Code)
-	assert.Empty(suite.T(), res.Body.String())
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	}
 
-	url, err := res.Result().Location()
-	assert.NoError(suite.T(), err)
-	assert.Regexp(suite.T(), uuidRegExp, url.String())
+	assert.Equal(suite.T(), 0, suite.router.promoLimiter.Len())
 }
 
-func (suite *OrderTestSuite) Test_GetOrderForPaylink_Ok_WithGORutineIsFalse() {
+func (suite *OrderTestSuite) Test_ApplyPromoCode_UnknownCodeCounted() {
+	orderId := uuid.New().String()
+	msg := &grpc.ResponseErrorMessage{Message: "promo code not found", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("ApplyPromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusNotFound, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	suite.router.promoLimiter = ratelimit.New(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.executePromoCodeTest(http.MethodPost, orderId, `{"code": "SUMMER-2020"}`)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	}
+
+	_, err := suite.executePromoCodeTest(http.MethodPost, orderId, `{"code": "WINTER-2020"}`)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+	ext.AssertNumberOfCalls(suite.T(), "ApplyPromoCode", 2)
+}
+
+func (suite *OrderTestSuite) Test_ApplyPromoCode_AttemptsExceeded() {
+	orderId := uuid.New().String()
+	body := `{"code": "SUMMER-2020"}`
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("ApplyPromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	suite.router.promoLimiter = ratelimit.New(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
+		assert.Error(suite.T(), err)
+	}
+
+	res, err := suite.executePromoCodeTest(http.MethodPost, orderId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorPromoCodeAttemptsExceeded, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+	ext.AssertNumberOfCalls(suite.T(), "ApplyPromoCode", 2)
+}
+
+func (suite *OrderTestSuite) Test_RemovePromoCode_Ok() {
+	orderId := uuid.New().String()
+	item := &billingext.PromoCodeResponseItem{
+		OrderAmounts: billingext.OrderAmounts{Amount: 10, TotalAmount: 12, Currency: "USD"},
+	}
+
+	ext := &extMock.Service{}
+	ext.On("RemovePromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusOk, Item: item}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executePromoCodeTest(http.MethodDelete, orderId, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `"total_amount":12`)
+}
+
+func (suite *OrderTestSuite) Test_RemovePromoCode_ValidationError() {
+	orderId := "string"
+
+	res, err := suite.executePromoCodeTest(http.MethodDelete, orderId, "")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_RemovePromoCode_BillingReturnError() {
+	orderId := uuid.New().String()
+
+	ext := &extMock.Service{}
+	ext.On("RemovePromoCode", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executePromoCodeTest(http.MethodDelete, orderId, "")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_RemovePromoCode_BillingResponseStatusError() {
+	orderId := uuid.New().String()
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("RemovePromoCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PromoCodeResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executePromoCodeTest(http.MethodDelete, orderId, "")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+// Test GetOrderStatus route
+func (suite *OrderTestSuite) executeGetOrderStatusTest(orderId string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + orderStatusPath).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderStatus_FromNotification() {
+	orderId := uuid.New().String()
+	suite.router.dispatch.OrderStates.Set(orderstate.State{OrderId: orderId, Status: "processed", UpdatedAt: 100})
+
+	ext := &extMock.Service{}
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetOrderStatusTest(orderId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `"status":"processed"`)
+	ext.AssertNotCalled(suite.T(), "GetOrderStatus", mock2.Anything, mock2.Anything)
+}
+
+func (suite *OrderTestSuite) Test_GetOrderStatus_FromBilling() {
+	orderId := uuid.New().String()
+	item := &billingext.OrderStatus{OrderId: orderId, Status: "created", UpdatedAt: 100}
+
+	ext := &extMock.Service{}
+	ext.On("GetOrderStatus", mock2.Anything, &billingext.GetOrderStatusRequest{OrderId: orderId}).
+		Return(&billingext.GetOrderStatusResponse{Status: pkg.ResponseStatusOk, Item: item}, nil).Once()
+	suite.router.dispatch.Services.BillingExt = ext
+
+	for i := 0; i < 2; i++ {
+		res, err := suite.executeGetOrderStatusTest(orderId)
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+		assert.Contains(suite.T(), res.Body.String(), `"status":"created"`)
+	}
+
+	ext.AssertNumberOfCalls(suite.T(), "GetOrderStatus", 1)
+}
+
+func (suite *OrderTestSuite) Test_GetOrderStatus_ValidationError() {
+	_, err := suite.executeGetOrderStatusTest("string")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
+}
+
+func (suite *OrderTestSuite) Test_GetOrderStatus_BillingReturnError() {
+	ext := &extMock.Service{}
+	ext.On("GetOrderStatus", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeGetOrderStatusTest(uuid.New().String())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+}
+
+func (suite *OrderTestSuite) Test_GetOrderStatus_BillingResponseStatusError() {
+	msg := &grpc.ResponseErrorMessage{Message: "order not found"}
+
+	ext := &extMock.Service{}
+	ext.On("GetOrderStatus", mock2.Anything, mock2.Anything).
+		Return(&billingext.GetOrderStatusResponse{Status: pkg.ResponseStatusNotFound, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeGetOrderStatusTest(uuid.New().String())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+}
+
+// Test GetReceipt route
+func (suite *OrderTestSuite) executeGetReceiptTest(orderId string, receiptId string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterOrderId, orderId, ":"+common.RequestParameterReceiptId, receiptId).
+		Path(common.NoAuthGroupPath + orderReceiptPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetReceipt_Ok() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("GetCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(&billingext.CustomerRefundRequestResponse{Status: http.StatusNotFound}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+	assert.NotContains(suite.T(), res.Body.String(), "refund_request")
+}
+
+func (suite *OrderTestSuite) Test_GetReceipt_Ok_WithRefundRequest() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	item := &billingext.CustomerRefundRequest{OrderId: orderId, Status: billingext.CustomerRefundRequestStatusCreated}
+	ext := &extMock.Service{}
+	ext.On("GetCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(&billingext.CustomerRefundRequestResponse{Status: pkg.ResponseStatusOk, Item: item}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), "refund_request")
+	assert.Contains(suite.T(), res.Body.String(), billingext.CustomerRefundRequestStatusCreated)
+}
+
+func (suite *OrderTestSuite) Test_GetReceipt_Ok_RefundRequestBillingError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("GetCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.NotContains(suite.T(), res.Body.String(), "refund_request")
+}
+
+func (suite *OrderTestSuite) Test_GetReceipt_ValidationError() {
+	orderId := "string"
+	receiptId := uuid.New().String()
+
+	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetReceipt_BillingReturnError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetReceipt_BillingResponseStatusError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetReceiptTest(orderId, receiptId)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+// Test CreateRefundRequest route
+func (suite *OrderTestSuite) executeCreateRefundRequestTest(orderId, receiptId, body string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, orderId, ":"+common.RequestParameterReceiptId, receiptId).
+		Path(common.NoAuthGroupPath + orderRefundRequestPath).
+		Init(test.ReqInitJSON()).
+		BodyString(body).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_Ok() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "not_received", "comment": "Game key was not delivered"}`
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	item := &billingext.CustomerRefundRequest{OrderId: orderId, Status: billingext.CustomerRefundRequestStatusCreated}
+	ext := &extMock.Service{}
+	ext.On("CreateCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(&billingext.CustomerRefundRequestResponse{Status: pkg.ResponseStatusOk, Item: item}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), billingext.CustomerRefundRequestStatusCreated)
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_ValidationReasonError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "unknown"}`
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectRefundReason.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_ValidationCommentError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "other", "comment": "<script>alert(1)</script>"}`
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectComment.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_ValidationOrderIdError() {
+	orderId := "string"
+	receiptId := uuid.New().String()
+	body := `{"reason": "other"}`
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectOrderId.Message, httpErr.Message.(grpc.ResponseErrorMessage).Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_ReceiptBillingResponseStatusError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "other"}`
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: http.StatusNotFound, Message: msg}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_BillingReturnError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "other"}`
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("CreateCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_BillingResponseStatusError() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "other"}`
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("CreateCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(&billingext.CustomerRefundRequestResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_CreateRefundRequest_TooManyRequests() {
+	orderId := uuid.New().String()
+	receiptId := uuid.New().String()
+	body := `{"reason": "other"}`
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderReceipt", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderReceiptResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("CreateCustomerRefundRequest", mock2.Anything, mock2.Anything).
+		Return(&billingext.CustomerRefundRequestResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	suite.router.refundLimiter = ratelimit.New(1, time.Hour)
+
+	_, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+	assert.NoError(suite.T(), err)
+
+	res, err := suite.executeCreateRefundRequestTest(orderId, receiptId, body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorTooManyRequests, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+	ext.AssertNumberOfCalls(suite.T(), "CreateCustomerRefundRequest", 1)
+}
+
+// Test GetOrderForPaylink route
+func (suite *OrderTestSuite) executeGetOrderForPaylinkTest(id string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, id).
+		Path(common.NoAuthGroupPath + paylinkIdPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_Ok() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetOrderForPaylinkTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusFound, res.Code)
+	assert.Empty(suite.T(), res.Body.String())
+
+	url, err := res.Result().Location()
+	assert.NoError(suite.T(), err)
+	assert.Regexp(suite.T(), uuidRegExp, url.String())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_Ok_WithGORutineIsFalse() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetOrderForPaylinkTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusFound, res.Code)
+	assert.Empty(suite.T(), res.Body.String())
+
+	url, err := res.Result().Location()
+	assert.NoError(suite.T(), err)
+	assert.Regexp(suite.T(), uuidRegExp, url.String())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_BillingReturnError() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetOrderForPaylinkTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_BillingResponseStatusError() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusSystemError}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetOrderForPaylinkTest(id)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_InvalidFormUrl() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "%zz"}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetOrderForPaylinkTest(id)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorUnknown, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) executeGetPaylinkPreviewTest(id string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, id).
+		Path(common.NoAuthGroupPath+paylinkIdPath).
+		Init(test.ReqInitJSON()).
+		AddHeader(echo.HeaderUserAgent, "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)").
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_Crawler_Ok() {
+	id := uuid.New().String()
+	preview := &billingext.PaylinkPreview{
+		Id:          id,
+		Name:        "Game <Deluxe>",
+		Description: "description",
+		ImageUrl:    "https://cdn.pay.super.com/image.png",
+		Amount:      9.9,
+		Currency:    "USD",
+	}
+
+	bill := &billMock.BillingService{}
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkPreview", mock2.Anything, &billingext.PaylinkRequest{Id: id}).
+		Return(&billingext.PaylinkPreviewResponse{Status: pkg.ResponseStatusOk, Item: preview}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkPreviewTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `<meta property="og:title" content="Game &lt;Deluxe&gt;">`)
+	assert.Contains(suite.T(), res.Body.String(), `<meta property="og:image" content="https://cdn.pay.super.com/image.png">`)
+	assert.Contains(suite.T(), res.Body.String(), `<meta property="product:price:amount" content="9.90">`)
+	assert.Contains(suite.T(), res.Body.String(), `<meta name="twitter:card" content="summary_large_image">`)
+	bill.AssertNotCalled(suite.T(), "OrderCreateByPaylink", mock2.Anything, mock2.Anything)
+	bill.AssertNotCalled(suite.T(), "IncrPaylinkVisits", mock2.Anything, mock2.Anything)
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_Crawler_BillingReturnError() {
+	id := uuid.New().String()
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkPreview", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkPreviewTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_Crawler_BillingResponseStatusError() {
+	id := uuid.New().String()
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkPreview", mock2.Anything, mock2.Anything).
+		Return(&billingext.PaylinkPreviewResponse{Status: http.StatusNotFound}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkPreviewTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) executeGetEmbeddedOrderForPaylinkTest(id string) (*httptest.ResponseRecorder, error) {
+	return suite.executeGetEmbeddedOrderForPaylinkRefererTest(id, "")
+}
+
+func (suite *OrderTestSuite) executeGetEmbeddedOrderForPaylinkRefererTest(id, referer string) (*httptest.ResponseRecorder, error) {
+	builder := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, id).
+		Path(common.NoAuthGroupPath + paylinkEmbedPath).
+		Init(test.ReqInitJSON())
+
+	if referer != "" {
+		builder.AddHeader(common.HeaderReferer, referer)
+	}
+
+	return builder.Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetEmbeddedOrderForPaylink_Ok() {
 	id := uuid.New().String()
+	orderId := "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"
+	cfg := *suite.router.cfg.Get()
+	cfg.PaymentFormJsLibraryUrl = "https://cdn.pay.super.com/paysuper.js"
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
 
 	bill := &billMock.BillingService{}
-	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
-	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
-		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.MatchedBy(func(req *billing.OrderCreateByPaylink) bool {
+		return req.PaylinkId == id && req.IsEmbedded == true
+	})).Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
 	suite.router.dispatch.Services.Billing = bill
 
-	res, err := suite.executeGetOrderForPaylinkTest(id)
+	res, err := suite.executeGetEmbeddedOrderForPaylinkTest(id)
 
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), http.StatusFound, res.Code)
-	assert.Empty(suite.T(), res.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `<script src="https://cdn.pay.super.com/paysuper.js"></script>`)
+	assert.Contains(suite.T(), res.Body.String(), `src="http://localhost`+orderId)
+	assert.Contains(suite.T(), res.Body.String(), `"`+orderId+`"`)
+	assert.Contains(suite.T(), res.Body.String(), "ORDER_CREATED")
+	assert.Contains(suite.T(), res.Body.String(), `var parentOrigin = "";`)
+}
+
+func (suite *OrderTestSuite) Test_GetEmbeddedOrderForPaylink_ParentOrigin() {
+	orderId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("GetProjectAllowedOrigins", mock2.Anything, &billingext.GetProjectAllowedOriginsRequest{OrderId: orderId}).
+		Return(&billingext.GetProjectAllowedOriginsResponse{
+			Status: pkg.ResponseStatusOk,
+			Item:   &billingext.ProjectAllowedOrigins{Origins: []string{"https://shop.com", "https://*.game.com"}},
+		}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	cases := []struct {
+		referer string
+		origin  string
+	}{
+		{"https://shop.com/games/buy?item=1", "https://shop.com"},
+		{"https://store.game.com/", "https://store.game.com"},
+		{"https://evil.com/shop.com", ""},
+		{"http://shop.com/", ""},
+		{"not a url", ""},
+	}
+
+	for _, c := range cases {
+		res, err := suite.executeGetEmbeddedOrderForPaylinkRefererTest(uuid.New().String(), c.referer)
+
+		assert.NoError(suite.T(), err, c.referer)
+		assert.Equal(suite.T(), http.StatusOK, res.Code, c.referer)
+		assert.Contains(suite.T(), res.Body.String(), `var parentOrigin = "`+c.origin+`";`, c.referer)
+	}
+}
+
+func (suite *OrderTestSuite) Test_GetEmbeddedOrderForPaylink_ParentOriginBillingError() {
+	orderId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("GetProjectAllowedOrigins", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetEmbeddedOrderForPaylinkRefererTest(uuid.New().String(), "https://shop.com/")
 
-	url, err := res.Result().Location()
 	assert.NoError(suite.T(), err)
-	assert.Regexp(suite.T(), uuidRegExp, url.String())
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `var parentOrigin = "";`)
 }
 
-func (suite *OrderTestSuite) Test_GetOrderForPaylink_BillingReturnError() {
+func (suite *OrderTestSuite) Test_GetEmbeddedOrderForPaylink_BillingReturnError() {
 	id := uuid.New().String()
 
 	bill := &billMock.BillingService{}
 	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
-	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
-		Return(nil, errors.New("error"))
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
 	suite.router.dispatch.Services.Billing = bill
 
-	res, err := suite.executeGetOrderForPaylinkTest(id)
+	res, err := suite.executeGetEmbeddedOrderForPaylinkTest(id)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-func (suite *OrderTestSuite) Test_GetOrderForPaylink_BillingResponseStatusError() {
+func (suite *OrderTestSuite) Test_GetEmbeddedOrderForPaylink_BillingResponseStatusError() {
 	id := uuid.New().String()
 
 	bill := &billMock.BillingService{}
@@ -1257,34 +2441,401 @@ func (suite *OrderTestSuite) Test_GetOrderForPaylink_BillingResponseStatusError(
 		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusSystemError}, nil)
 	suite.router.dispatch.Services.Billing = bill
 
-	res, err := suite.executeGetOrderForPaylinkTest(id)
+	res, err := suite.executeGetEmbeddedOrderForPaylinkTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusInternalServerError, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetEmbeddedOrderForPaylink_InvalidFormUrl() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "%zz"}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetEmbeddedOrderForPaylinkTest(id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusInternalServerError, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) executeGetPaylinkQrCodeTest(path, id string, query url.Values) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, id).
+		Path(common.NoAuthGroupPath + path).
+		SetQueryParams(query).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetPaylinkQrCodePng_Ok() {
+	id := "ffffffffffffffffffffffff"
+	query := url.Values{common.QueryParameterNameUtmSource: []string{"poster"}, "size": []string{"128"}}
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkShortCode", mock2.Anything, &billingext.PaylinkRequest{Id: id}).
+		Return(&billingext.PaylinkShortCodeResponse{Status: pkg.ResponseStatusOk, Item: &billingext.PaylinkShortCode{Code: "abc", PaylinkId: id}}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkQrCodeTest(paylinkQrCodePngPath, id, query)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), "image/png", res.Header().Get(echo.HeaderContentType))
+
+	img, err := png.Decode(res.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 128, img.Bounds().Dx())
+}
+
+func (suite *OrderTestSuite) Test_GetPaylinkQrCodeSvg_Ok() {
+	id := "ffffffffffffffffffffffff"
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkShortCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PaylinkShortCodeResponse{Status: pkg.ResponseStatusOk, Item: &billingext.PaylinkShortCode{Code: "abc", PaylinkId: id}}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkQrCodeTest(paylinkQrCodeSvgPath, id, url.Values{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), "image/svg+xml", res.Header().Get(echo.HeaderContentType))
+	assert.Contains(suite.T(), res.Body.String(), fmt.Sprintf(`width="%d"`, suite.router.cfg.Get().PaylinkQrCodeSize))
+}
+
+func (suite *OrderTestSuite) Test_GetPaylinkQrCode_ValidationError() {
+	res, err := suite.executeGetPaylinkQrCodeTest(paylinkQrCodePngPath, "ffffffffffffffffffffffff", url.Values{"level": []string{"X"}})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetPaylinkQrCode_SizeTooLarge() {
+	query := url.Values{"size": []string{fmt.Sprintf("%d", suite.router.cfg.Get().PaylinkQrCodeMaxSize+1)}}
+	res, err := suite.executeGetPaylinkQrCodeTest(paylinkQrCodePngPath, "ffffffffffffffffffffffff", query)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorQrCodeSizeTooLarge, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetPaylinkQrCode_BillingReturnError() {
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkShortCode", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkQrCodeTest(paylinkQrCodePngPath, "ffffffffffffffffffffffff", url.Values{})
 
 	assert.Error(suite.T(), err)
 
 	httpErr, ok := err.(*echo.HTTPError)
 	assert.True(suite.T(), ok)
 	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetPaylinkQrCode_BillingResponseStatusError() {
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkShortCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PaylinkShortCodeResponse{Status: http.StatusNotFound, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetPaylinkQrCodeTest(paylinkQrCodeSvgPath, "ffffffffffffffffffffffff", url.Values{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) executeGetOrderForPaylinkShortLinkTest(code string, query url.Values) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterCode, code).
+		Path(paylinkShortLinkPath).
+		SetQueryParams(query).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylinkShortLink_Ok() {
+	id := "ffffffffffffffffffffffff"
+	query := url.Values{
+		common.QueryParameterNameUtmSource:   []string{"poster"},
+		common.QueryParameterNameUtmCampaign: []string{"event"},
+	}
+
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkByShortCode", mock2.Anything, &billingext.PaylinkShortCodeRequest{Code: "abc"}).
+		Return(&billingext.PaylinkShortCodeResponse{Status: pkg.ResponseStatusOk, Item: &billingext.PaylinkShortCode{Code: "abc", PaylinkId: id}}, nil)
+	ext.On("SetOrderAttribution", mock2.Anything, mock2.Anything).
+		Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	bill := &billMock.BillingService{}
+	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.MatchedBy(func(req *billing.OrderCreateByPaylink) bool {
+		return req.PaylinkId == id && req.UtmSource == "poster" && req.UtmCampaign == "event"
+	})).Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeGetOrderForPaylinkShortLinkTest("abc", query)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusFound, res.Code)
+
+	location, err := res.Result().Location()
+	assert.NoError(suite.T(), err)
+	assert.Regexp(suite.T(), uuidRegExp, location.String())
+	assert.Equal(suite.T(), "poster", location.Query().Get(common.QueryParameterNameUtmSource))
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylinkShortLink_BillingReturnError() {
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkByShortCode", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetOrderForPaylinkShortLinkTest("abc", url.Values{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylinkShortLink_NotFound() {
+	ext := &extMock.Service{}
+	ext.On("GetPaylinkByShortCode", mock2.Anything, mock2.Anything).
+		Return(&billingext.PaylinkShortCodeResponse{Status: http.StatusNotFound}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetOrderForPaylinkShortLinkTest("abc", url.Values{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, res.Code)
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
 
-func (suite *OrderTestSuite) Test_GetOrderForPaylink_InvalidFormUrlMask() {
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_Attribution() {
 	id := uuid.New().String()
+	orderId := "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"
+	query := url.Values{
+		common.QueryParameterNameUtmSource: []string{"stream"},
+		"utm_term":                         []string{"game"},
+		"gclid":                            []string{"click_id"},
+		"unknown":                          []string{"value"},
+	}
 
 	bill := &billMock.BillingService{}
 	bill.On("IncrPaylinkVisits", mock2.Anything, mock2.Anything).Return(nil, nil)
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.MatchedBy(func(req *billing.OrderCreateByPaylink) bool {
+		return req.UtmSource == "stream"
+	})).Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("SetOrderAttribution", mock2.Anything, mock2.MatchedBy(func(req *billingext.SetOrderAttributionRequest) bool {
+		return req.OrderId == orderId && req.Referrer == "https://streamer.tv/" && req.LandedAt > 0 &&
+			assert.ObjectsAreEqual(
+				map[string]string{common.QueryParameterNameUtmSource: "stream", "utm_term": "game", "gclid": "click_id"},
+				req.Parameters,
+			)
+	})).Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, id).
+		Path(common.NoAuthGroupPath+paylinkIdPath).
+		SetQueryParams(query).
+		AddHeader(common.HeaderReferer, "https://streamer.tv/").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusFound, res.Code)
+	ext.AssertExpectations(suite.T())
+
+	var cookie *http.Cookie
+
+	for _, c := range res.Result().Cookies() {
+		if c.Name == common.AttributionCookiesName {
+			cookie = c
+		}
+	}
+
+	assert.NotNil(suite.T(), cookie)
+
+	attribution := &helpers.Attribution{}
+	assert.NoError(suite.T(), suite.router.attrSigner.Verify(cookie.Value, attribution))
+	assert.Equal(suite.T(), "https://streamer.tv/", attribution.Referrer)
+	assert.Equal(suite.T(), "game", attribution.Get("utm_term"))
+}
+
+func (suite *OrderTestSuite) Test_CreateJson_AttributionFromCookie() {
+	orderId := uuid.New().String()
+	value, _ := suite.router.attrSigner.Sign(&helpers.Attribution{
+		Parameters: map[string]string{"fbclid": "click_id"},
+		Referrer:   "https://first.touch/",
+		LandedAt:   100,
+	}, time.Now().Add(time.Hour))
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("SetOrderAttribution", mock2.Anything, &billingext.SetOrderAttributionRequest{
+		OrderId:    orderId,
+		Parameters: map[string]string{"fbclid": "click_id"},
+		Referrer:   "https://first.touch/",
+		LandedAt:   100,
+	}).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath+orderPath).
+		Init(test.ReqInitJSON()).
+		AddHeader(common.HeaderReferer, "https://second.touch/").
+		AddCookie(&http.Cookie{Name: common.AttributionCookiesName, Value: value}).
+		BodyString("{}").
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	ext.AssertExpectations(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_CreateJson_AttributionInvalidCookie() {
+	orderId := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: orderId}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + orderPath).
+		Init(test.ReqInitJSON()).
+		AddCookie(&http.Cookie{Name: common.AttributionCookiesName, Value: "invalid"}).
+		BodyString("{}").
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	ext.AssertNotCalled(suite.T(), "SetOrderAttribution", mock2.Anything, mock2.Anything)
+}
+
+func (suite *OrderTestSuite) executeGetOrderForPaylinkWithUserAgentTest(id, userAgent string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterId, id).
+		Path(common.NoAuthGroupPath+paylinkIdPath).
+		Init(test.ReqInitJSON()).
+		AddHeader(echo.HeaderUserAgent, userAgent).
+		Exec(suite.T())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_VisitsCounting() {
+	id := uuid.New().String()
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0 Safari/537.36"
+
+	bill := &billMock.BillingService{}
 	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
-		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "uuid"}}, nil)
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
 	suite.router.dispatch.Services.Billing = bill
 
-	suite.router.cfg.OrderInlineFormUrlMask = string([]byte{0x7f})
+	ext := &extMock.Service{}
+	ext.On("IncrPaylinkVisitsBatch", mock2.Anything, &billingext.IncrPaylinkVisitsBatchRequest{
+		Visits: []*billingext.PaylinkVisits{{PaylinkId: id, Count: 1}},
+	}).Return(&billingext.EmptyResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
 
-	res, err := suite.executeGetOrderForPaylinkTest(id)
+	assert.NoError(suite.T(), suite.router.Start(context.Background()))
 
-	assert.Error(suite.T(), err)
+	for _, ua := range []string{userAgent, userAgent, "curl/7.64.1", "python-requests/2.22.0"} {
+		res, err := suite.executeGetOrderForPaylinkWithUserAgentTest(id, ua)
 
-	httpErr, ok := err.(*echo.HTTPError)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusFound, res.Code)
+	}
+
+	assert.Equal(suite.T(), map[string]int{id: 1}, suite.router.visits.Pending())
+
+	suite.router.Shutdown(context.Background())
+
+	assert.Empty(suite.T(), suite.router.visits.Pending())
+	ext.AssertExpectations(suite.T())
+	bill.AssertNotCalled(suite.T(), "IncrPaylinkVisits", mock2.Anything, mock2.Anything)
+}
+
+func (suite *OrderTestSuite) Test_Start_OrderStatusSubscription() {
+	b := memory.NewBroker()
+	defer b.Disconnect()
+
+	cfg := *suite.router.cfg.Get()
+	cfg.OrderStatusTopic = "order.status"
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+	suite.router.dispatch.Services.Broker = b
+
+	assert.NoError(suite.T(), suite.router.Start(context.Background()))
+
+	err = b.Publish("order.status", &broker.Message{Body: []byte(`{"order_id": "first", "status": "processed", "updated_at": 1}`)})
+	assert.NoError(suite.T(), err)
+
+	state, ok := suite.router.dispatch.OrderStates.Get("first")
 	assert.True(suite.T(), ok)
-	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
-	assert.Equal(suite.T(), common.ErrorUnknown, httpErr.Message)
-	assert.NotEmpty(suite.T(), res.Body.String())
+	assert.Equal(suite.T(), "processed", state.Status)
+
+	suite.router.Shutdown(context.Background())
+}
+
+func (suite *OrderTestSuite) Test_GetOrderForPaylink_VisitsSendingError() {
+	id := uuid.New().String()
+
+	bill := &billMock.BillingService{}
+	bill.On("OrderCreateByPaylink", mock2.Anything, mock2.Anything).
+		Return(&grpc.OrderCreateProcessResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: "fbd3036f-0f1c-4e98-b71c-d4cd61213f90"}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("IncrPaylinkVisitsBatch", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetOrderForPaylinkWithUserAgentTest(id, "Mozilla/5.0")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusFound, res.Code)
+
+	suite.router.Shutdown(context.Background())
+
+	assert.Equal(suite.T(), map[string]int{id: 1}, suite.router.visits.Pending())
 }
@@ -1,36 +1,142 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/labstack/echo/v4"
 	"github.com/paysuper/paysuper-billing-server/pkg"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/helpers"
+	"github.com/paysuper/paysuper-checkout/pkg/captcha"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	errPaymentReturnTooManyParameters = errors.New("too many payment return parameters")
+)
+
+const (
+	paymentPath       = "/payment"
+	paymentReturnPath = "/payment/return/:order_id"
+	paymentWalletPath = "/payment/wallet"
+)
+
+const (
+	captchaRuleIp     = "ip"
+	captchaRuleOrder  = "order"
+	captchaRuleCookie = "cookie"
 )
 
 const (
-	paymentPath = "/payment"
+	paymentResultTemplateName  = "payment_result.html"
+	paymentReturnMaxParameters = 50
+	paymentStatusQueryParam    = "payment_status"
 )
 
+type WalletPaymentRequest struct {
+	OrderId string          `json:"order_id" validate:"required,uuid"`
+	Wallet  string          `json:"wallet" validate:"required,oneof=apple_pay google_pay"`
+	Token   json.RawMessage `json:"token" validate:"required,max=16384"`
+	Email   string          `json:"email" validate:"omitempty,email"`
+}
+
+type PaymentResultTemplateData struct {
+	Status  string
+	FormUrl string
+}
+
 type PaymentRoute struct {
-	dispatch common.HandlerSet
-	cfg      *common.Config
+	dispatch  common.HandlerSet
+	cfg       *common.GlobalConfig
+	validator *common.PaymentDataValidator
+	// mx guards captcha verifier replaced on reload, captcha is disabled if verifier is nil
+	mx      sync.RWMutex
+	captcha captcha.Verifier
+	guard   *captcha.Guard
 	provider.LMT
 }
 
-func NewPaymentRoute(set common.HandlerSet, cfg *common.Config) *PaymentRoute {
+func NewPaymentRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *PaymentRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "PaymentRoute"})
-	return &PaymentRoute{
-		dispatch: set,
-		LMT:      &set.AwareSet,
-		cfg:      cfg,
+	cfg := globalCfg.Get()
+	route := &PaymentRoute{
+		dispatch:  set,
+		LMT:       &set.AwareSet,
+		cfg:       globalCfg,
+		validator: common.NewPaymentDataValidator(cfg.PaymentDataAllowedFields, set.Validate),
+		guard:     captcha.NewGuard(getCaptchaRules(cfg)...),
+	}
+	route.captcha = route.newCaptchaVerifier(cfg)
+
+	return route
+}
+
+// Reload applies allowed payment fields and captcha settings of the reloaded configuration,
+// failures counted by the captcha rules which are kept aren't forgotten
+func (h *PaymentRoute) Reload(_ context.Context) {
+	cfg := h.cfg.Get()
+	verifier := h.newCaptchaVerifier(cfg)
+
+	h.validator.SetAllowedFields(cfg.PaymentDataAllowedFields)
+	h.guard.SetRules(getCaptchaRules(cfg)...)
+
+	h.mx.Lock()
+	h.captcha = verifier
+	h.mx.Unlock()
+}
+
+// newCaptchaVerifier returns verifier of the configured provider or nil if captcha is disabled or provider is broken
+func (h *PaymentRoute) newCaptchaVerifier(cfg *common.Config) captcha.Verifier {
+	if cfg.CaptchaProvider == "" {
+		return nil
+	}
+
+	verifier, err := captcha.New(
+		cfg.CaptchaProvider,
+		cfg.CaptchaVerifyUrl,
+		cfg.CaptchaSecret,
+		cfg.CaptchaMinScore,
+		time.Duration(cfg.CaptchaTimeoutSeconds)*time.Second,
+	)
+
+	if err != nil {
+		h.L().Error("captcha verifier can't be created", logger.PairArgs("err", err.Error(), "provider", cfg.CaptchaProvider))
+		return nil
+	}
+
+	return verifier
+}
+
+func (h *PaymentRoute) getCaptchaVerifier() captcha.Verifier {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+	return h.captcha
+}
+
+func getCaptchaRules(cfg *common.Config) []captcha.Rule {
+	window := time.Duration(cfg.CaptchaFailuresWindowMinutes) * time.Minute
+	return []captcha.Rule{
+		{Name: captchaRuleIp, Limit: cfg.CaptchaFailuresPerIp, Window: window},
+		{Name: captchaRuleOrder, Limit: cfg.CaptchaFailuresPerOrder, Window: window},
+		{Name: captchaRuleCookie, Limit: cfg.CaptchaFailuresPerCookie, Window: window},
 	}
 }
 
 func (h *PaymentRoute) Route(groups *common.Groups) {
-	groups.Common.POST(paymentPath, h.processCreatePayment)
+	groups.Shared(func(group *echo.Group) {
+		group.POST(paymentPath, h.processCreatePayment, rejectInMaintenance(h.cfg))
+		group.POST(paymentWalletPath, h.processCreateWalletPayment, rejectInMaintenance(h.cfg))
+		group.GET(paymentReturnPath, h.processPaymentReturn, renderInMaintenance(h.cfg))
+		group.POST(paymentReturnPath, h.processPaymentReturn, renderInMaintenance(h.cfg))
+	})
 }
 
 func (h *PaymentRoute) processCreatePayment(ctx echo.Context) error {
@@ -41,6 +147,21 @@ func (h *PaymentRoute) processCreatePayment(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestDataInvalid)
 	}
 
+	keys := map[string]string{
+		captchaRuleIp:     ctx.RealIP(),
+		captchaRuleOrder:  data[common.RequestParameterOrderId],
+		captchaRuleCookie: helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
+	}
+
+	if err := h.checkCaptcha(ctx, keys); err != nil {
+		return err
+	}
+
+	if vErr := h.validator.Validate(data); vErr != nil {
+		h.failCaptcha(keys)
+		return echo.NewHTTPError(http.StatusBadRequest, vErr)
+	}
+
 	req := &grpc.PaymentCreateRequest{
 		Data:           data,
 		AcceptLanguage: ctx.Request().Header.Get(common.HeaderAcceptLanguage),
@@ -53,6 +174,85 @@ func (h *PaymentRoute) processCreatePayment(ctx echo.Context) error {
 		return h.dispatch.SrvCallHandler(req, err, pkg.ServiceName, "PaymentCreateProcess")
 	}
 
+	if res.Status != pkg.ResponseStatusOk {
+		h.failCaptcha(keys)
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	body := map[string]interface{}{
+		"redirect_url":  res.RedirectUrl,
+		"need_redirect": res.NeedRedirect,
+	}
+
+	return ctx.JSON(http.StatusOK, body)
+}
+
+// checkCaptcha requires solved challenge when failed payments of the customer trigger any risk rule
+func (h *PaymentRoute) checkCaptcha(ctx echo.Context, keys map[string]string) error {
+	cfg := h.cfg.Get()
+	verifier := h.getCaptchaVerifier()
+
+	if verifier == nil || !h.guard.Required(keys) {
+		return nil
+	}
+
+	token := ctx.Request().Header.Get(common.HeaderXCaptchaToken)
+
+	if token == "" {
+		rspErr := common.ErrorCaptchaRequired
+		rspErr.Details = cfg.CaptchaSiteKey
+		return echo.NewHTTPError(http.StatusForbidden, rspErr)
+	}
+
+	err := verifier.Verify(ctx.Request().Context(), token, ctx.RealIP())
+
+	if err == captcha.ErrInvalidToken {
+		return echo.NewHTTPError(http.StatusForbidden, common.ErrorCaptchaInvalid)
+	}
+
+	if err != nil {
+		h.L().Error("captcha verification failed", logger.PairArgs("err", err.Error(), "provider", cfg.CaptchaProvider))
+		return echo.NewHTTPError(http.StatusServiceUnavailable, common.ErrorCaptchaUnavailable)
+	}
+
+	h.guard.Reset(keys)
+	return nil
+}
+
+func (h *PaymentRoute) failCaptcha(keys map[string]string) {
+	if h.getCaptchaVerifier() != nil {
+		h.guard.Fail(keys)
+	}
+}
+
+// processCreateWalletPayment creates payment by the encrypted Apple Pay or Google Pay payment token
+func (h *PaymentRoute) processCreateWalletPayment(ctx echo.Context) error {
+	req := &WalletPaymentRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	if !json.Valid(req.Token) || req.Token[0] != '{' {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorIncorrectWalletToken)
+	}
+
+	walletReq := &billingext.WalletPaymentRequest{
+		OrderId:        req.OrderId,
+		Wallet:         req.Wallet,
+		Token:          string(req.Token),
+		Email:          req.Email,
+		Cookie:         helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
+		Ip:             ctx.RealIP(),
+		UserAgent:      ctx.Request().Header.Get(common.HeaderUserAgent),
+		AcceptLanguage: ctx.Request().Header.Get(common.HeaderAcceptLanguage),
+	}
+	res, err := h.dispatch.Services.BillingExt.PaymentCreateByWallet(ctx.Request().Context(), walletReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(walletReq, err, pkg.ServiceName, "PaymentCreateByWallet")
+	}
+
 	if res.Status != pkg.ResponseStatusOk {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
@@ -64,3 +264,83 @@ func (h *PaymentRoute) processCreatePayment(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, body)
 }
+
+// processPaymentReturn completes payment when customer is returned from 3-D Secure or alternative payment method page
+// and sends customer back to the payment form
+func (h *PaymentRoute) processPaymentReturn(ctx echo.Context) error {
+	cfg := h.cfg.Get()
+
+	orderId := ctx.Param(common.RequestParameterOrderId)
+
+	if err := h.dispatch.Validate.Var(orderId, "required,uuid"); err != nil {
+		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+	}
+
+	payload, err := h.getPaymentReturnPayload(ctx)
+
+	if err != nil {
+		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+	}
+
+	req := &billingext.PaymentReturnRequest{
+		OrderId:        orderId,
+		Method:         ctx.Request().Method,
+		Payload:        payload,
+		Cookie:         helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName),
+		Ip:             ctx.RealIP(),
+		UserAgent:      ctx.Request().Header.Get(common.HeaderUserAgent),
+		AcceptLanguage: ctx.Request().Header.Get(common.HeaderAcceptLanguage),
+	}
+	res, err := h.dispatch.Services.BillingExt.ProcessPaymentReturn(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, pkg.ServiceName, "ProcessPaymentReturn", req)
+		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return ctx.Render(int(res.Status), errorTemplateName, map[string]interface{}{})
+	}
+
+	// Customer cookie isn't restored here when browser loses it on the cross-site return from the provider page,
+	// anyone knowing the order id could get the customer session otherwise, payment form restores it with billing
+	formUrl := cfg.OrderInlineFormUrlMask + orderId + "?" + url.Values{paymentStatusQueryParam: []string{res.Item.Status}}.Encode()
+
+	if cfg.PaymentReturnRedirect {
+		return ctx.Redirect(http.StatusSeeOther, formUrl)
+	}
+
+	data := &PaymentResultTemplateData{
+		Status:  res.Item.Status,
+		FormUrl: formUrl,
+	}
+
+	return ctx.Render(http.StatusOK, paymentResultTemplateName, data)
+}
+
+// getPaymentReturnPayload returns query and form parameters sent by payment provider
+func (h *PaymentRoute) getPaymentReturnPayload(ctx echo.Context) (map[string]string, error) {
+	values := ctx.QueryParams()
+
+	if ctx.Request().Method == http.MethodPost {
+		form, err := ctx.FormParams()
+
+		if err != nil {
+			return nil, err
+		}
+
+		values = form
+	}
+
+	if len(values) > paymentReturnMaxParameters {
+		return nil, errPaymentReturnTooManyParameters
+	}
+
+	payload := make(map[string]string, len(values))
+
+	for name := range values {
+		payload[name] = values.Get(name)
+	}
+
+	return payload, nil
+}
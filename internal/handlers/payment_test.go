@@ -1,19 +1,27 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/paysuper/paysuper-billing-server/pkg"
 	billMock "github.com/paysuper/paysuper-billing-server/pkg/mocks"
 	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/captcha"
 	"github.com/stretchr/testify/assert"
 	mock2 "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 )
 
 type PaymentTestSuite struct {
@@ -57,11 +65,12 @@ func (suite *PaymentTestSuite) executeProcessCreatePaymentTest(body string) (*ht
 }
 
 func (suite *PaymentTestSuite) Test_ProcessCreatePayment_Ok() {
-	body := `{"test": "test", "one": true, "two": false}`
+	body := `{"order_id": "order_id", "pan": "4111 1111 1111 1111", "cvv": 123, "month": 12, "year": 2099, "store_data": true}`
 
 	bill := &billMock.BillingService{}
-	bill.On("PaymentCreateProcess", mock2.Anything, mock2.Anything).
-		Return(&grpc.PaymentCreateResponse{Status: pkg.ResponseStatusOk, RedirectUrl: "url", NeedRedirect: true}, nil)
+	bill.On("PaymentCreateProcess", mock2.Anything, mock2.MatchedBy(func(req *grpc.PaymentCreateRequest) bool {
+		return req.Data["pan"] == "4111111111111111" && req.Data["cvv"] == "123" && req.Data["store_data"] == "1"
+	})).Return(&grpc.PaymentCreateResponse{Status: pkg.ResponseStatusOk, RedirectUrl: "url", NeedRedirect: true}, nil)
 	suite.router.dispatch.Services.Billing = bill
 
 	res, err := suite.executeProcessCreatePaymentTest(body)
@@ -72,6 +81,148 @@ func (suite *PaymentTestSuite) Test_ProcessCreatePayment_Ok() {
 	assert.Regexp(suite.T(), "redirect_url", res.Body.String())
 }
 
+func (suite *PaymentTestSuite) executeProcessCreatePaymentWithCaptchaTest(body, token string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath+paymentPath).
+		Init(test.ReqInitJSON()).
+		AddHeader(common.HeaderXCaptchaToken, token).
+		BodyString(body).
+		Exec(suite.T())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_CaptchaChallenge() {
+	body := `{"order_id": "order_id", "pan": "4111 1111 1111 1111", "cvv": 123, "month": 12, "year": 2099}`
+	cfg := *suite.router.cfg.Get()
+	cfg.CaptchaSiteKey = "site_key"
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+	suite.router.captcha = captcha.NewFake("valid_token")
+	suite.router.guard = captcha.NewGuard(captcha.Rule{Name: captchaRuleOrder, Limit: 2, Window: time.Minute})
+
+	bill := &billMock.BillingService{}
+	bill.On("PaymentCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.PaymentCreateResponse{Status: pkg.ResponseStatusBadData, Message: &grpc.ResponseErrorMessage{}}, nil).Times(2)
+	bill.On("PaymentCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.PaymentCreateResponse{Status: pkg.ResponseStatusOk, RedirectUrl: "url"}, nil).Once()
+	suite.router.dispatch.Services.Billing = bill
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.executeProcessCreatePaymentTest(body)
+		assert.Error(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	}
+
+	_, err = suite.executeProcessCreatePaymentTest(body)
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCaptchaRequired.Code, httpErr.Message.(grpc.ResponseErrorMessage).Code)
+	assert.Equal(suite.T(), "site_key", httpErr.Message.(grpc.ResponseErrorMessage).Details)
+
+	_, err = suite.executeProcessCreatePaymentWithCaptchaTest(body, "invalid_token")
+	assert.Error(suite.T(), err)
+
+	httpErr, ok = err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCaptchaInvalid, httpErr.Message)
+
+	res, err := suite.executeProcessCreatePaymentWithCaptchaTest(body, "valid_token")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	bill.AssertNumberOfCalls(suite.T(), "PaymentCreateProcess", 3)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_CaptchaValidationFailures() {
+	body := `{"order_id": "order_id", "pan": "4111 1111 1111 1112"}`
+	suite.router.captcha = captcha.NewFake("valid_token")
+	suite.router.guard = captcha.NewGuard(captcha.Rule{Name: captchaRuleIp, Limit: 1, Window: time.Minute})
+
+	_, err := suite.executeProcessCreatePaymentTest(body)
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	_, err = suite.executeProcessCreatePaymentTest(`{"order_id": "other_order_id"}`)
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCaptchaRequired.Code, httpErr.Message.(grpc.ResponseErrorMessage).Code)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_CaptchaUnavailable() {
+	body := `{"order_id": "order_id"}`
+	suite.router.captcha = captcha.NewSiteVerifier("http://127.0.0.1:1", "secret", 0, time.Second)
+	suite.router.guard = captcha.NewGuard(captcha.Rule{Name: captchaRuleOrder, Limit: 1, Window: time.Minute})
+	suite.router.guard.Fail(map[string]string{captchaRuleOrder: "order_id"})
+
+	_, err := suite.executeProcessCreatePaymentWithCaptchaTest(body, "token")
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCaptchaUnavailable, httpErr.Message)
+}
+
+func (suite *PaymentTestSuite) Test_NewPaymentRoute_Captcha() {
+	cfg := *suite.router.cfg.Get()
+	cfg.CaptchaProvider = captcha.ProviderFake
+	cfg.CaptchaFailuresPerIp = 1
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+
+	route := NewPaymentRoute(suite.router.dispatch, globalCfg)
+	assert.NotNil(suite.T(), route.captcha)
+	assert.NotNil(suite.T(), route.guard)
+
+	cfg.CaptchaProvider = "unknown"
+	globalCfg, err = test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+
+	route = NewPaymentRoute(suite.router.dispatch, globalCfg)
+	assert.Nil(suite.T(), route.captcha)
+}
+
+func (suite *PaymentTestSuite) Test_Reload_Captcha() {
+	cfg := *suite.router.cfg.Get()
+	cfg.CaptchaProvider = ""
+	cfg.PaymentDataAllowedFields = ""
+	globalCfg, err := common.NewGlobalConfig(func(c *common.Config) error {
+		*c = cfg
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+
+	route := NewPaymentRoute(suite.router.dispatch, globalCfg)
+	assert.Nil(suite.T(), route.captcha)
+	assert.Nil(suite.T(), route.validator.Validate(map[string]string{"unknown": "value"}))
+
+	cfg.CaptchaProvider = captcha.ProviderFake
+	cfg.CaptchaFailuresPerOrder = 1
+	cfg.PaymentDataAllowedFields = "order_id"
+	assert.NoError(suite.T(), globalCfg.Reload())
+	route.Reload(context.Background())
+
+	assert.NotNil(suite.T(), route.captcha)
+	assert.NotNil(suite.T(), route.validator.Validate(map[string]string{"unknown": "value"}))
+
+	keys := map[string]string{captchaRuleOrder: "order_id"}
+	route.failCaptcha(keys)
+	assert.True(suite.T(), route.guard.Required(keys))
+
+	cfg.CaptchaProvider = ""
+	assert.NoError(suite.T(), globalCfg.Reload())
+	route.Reload(context.Background())
+
+	assert.Nil(suite.T(), route.captcha)
+}
+
 func (suite *PaymentTestSuite) Test_ProcessCreatePayment_BindError() {
 	body := `<some_string>`
 
@@ -124,3 +275,305 @@ func (suite *PaymentTestSuite) Test_ProcessCreatePayment_BillingResponseStatusEr
 	assert.Equal(suite.T(), msg, httpErr.Message)
 	assert.NotEmpty(suite.T(), res.Body.String())
 }
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_CardDataValidationError() {
+	body := `{"order_id": "order_id", "pan": "4111111111111112", "cvv": "1234", "month": 1, "year": 2019, "email": "email", "unknown": "value"}`
+
+	bill := &billMock.BillingService{}
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeProcessCreatePaymentTest(body)
+
+	assert.Error(suite.T(), err)
+	assert.NotEmpty(suite.T(), res.Body.String())
+	bill.AssertNotCalled(suite.T(), "PaymentCreateProcess", mock2.Anything, mock2.Anything)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+
+	vErr, ok := httpErr.Message.(*common.PaymentDataValidationError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorValidationFailed.Code, vErr.Code)
+	assert.Equal(suite.T(), []*common.PaymentFieldError{
+		{Field: "unknown", Code: common.ErrorPaymentFieldNotAllowed.Code, Message: common.ErrorPaymentFieldNotAllowed.Message},
+		{Field: "email", Code: common.ErrorIncorrectEmail.Code, Message: common.ErrorIncorrectEmail.Message},
+		{Field: "pan", Code: common.ErrorIncorrectCardNumber.Code, Message: common.ErrorIncorrectCardNumber.Message},
+		{Field: "month", Code: common.ErrorIncorrectCardExpiry.Code, Message: common.ErrorIncorrectCardExpiry.Message},
+		{Field: "cvv", Code: common.ErrorIncorrectCardCvv.Code, Message: common.ErrorIncorrectCardCvv.Message},
+	}, vErr.Fields)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_CvvLengthByBrand() {
+	body := `{"order_id": "order_id", "pan": "378282246310005", "cvv": "123", "month": 12, "year": 2099}`
+
+	_, err := suite.executeProcessCreatePaymentTest(body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+
+	vErr, ok := httpErr.Message.(*common.PaymentDataValidationError)
+	assert.True(suite.T(), ok)
+	assert.Len(suite.T(), vErr.Fields, 1)
+	assert.Equal(suite.T(), common.PaymentFieldCvv, vErr.Fields[0].Field)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_SavedCardCvv() {
+	body := `{"order_id": "order_id", "stored_card_id": "card_id", "cvv": "12"}`
+
+	_, err := suite.executeProcessCreatePaymentTest(body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+
+	vErr, ok := httpErr.Message.(*common.PaymentDataValidationError)
+	assert.True(suite.T(), ok)
+	assert.Len(suite.T(), vErr.Fields, 1)
+	assert.Equal(suite.T(), common.ErrorIncorrectCardCvv.Code, vErr.Fields[0].Code)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreatePayment_EwalletWithoutCardData() {
+	body := `{"order_id": "order_id", "payment_method_id": "id", "ewallet": "wallet", "email": "test@unit.test"}`
+
+	bill := &billMock.BillingService{}
+	bill.On("PaymentCreateProcess", mock2.Anything, mock2.Anything).
+		Return(&grpc.PaymentCreateResponse{Status: pkg.ResponseStatusOk, RedirectUrl: "url"}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	res, err := suite.executeProcessCreatePaymentTest(body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+}
+
+// Test ProcessPaymentReturn route
+func (suite *PaymentTestSuite) executeProcessPaymentReturnTest(method, orderId string, form url.Values, cookie *http.Cookie) (*httptest.ResponseRecorder, error) {
+	builder := suite.caller.Builder().
+		Method(method).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + paymentReturnPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		})
+
+	if method == http.MethodGet {
+		builder.SetQueryParams(form)
+	} else {
+		builder.BodyString(form.Encode())
+	}
+
+	if cookie != nil {
+		builder.AddCookie(cookie)
+	}
+
+	return builder.Exec(suite.T())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_Post_Ok() {
+	orderId := uuid.New().String()
+	form := url.Values{"PaRes": []string{"pares"}, "MD": []string{"md"}}
+
+	ext := &extMock.Service{}
+	ext.On("ProcessPaymentReturn", mock2.Anything, mock2.MatchedBy(func(req *billingext.PaymentReturnRequest) bool {
+		return req.OrderId == orderId && req.Method == http.MethodPost && req.Payload["PaRes"] == "pares" &&
+			req.Payload["MD"] == "md" && req.Cookie == ""
+	})).Return(&billingext.PaymentReturnResponse{
+		Status: pkg.ResponseStatusOk,
+		Item:   &billingext.PaymentReturnResult{Status: billingext.PaymentReturnStatusProcessed},
+	}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodPost, orderId, form, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusSeeOther, res.Code)
+	assert.Equal(suite.T(), suite.router.cfg.Get().OrderInlineFormUrlMask+orderId+"?payment_status=processed", res.Header().Get(echo.HeaderLocation))
+
+	// customer cookie isn't issued to anyone knowing the order id
+	assert.Empty(suite.T(), res.Result().Cookies())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_Get_KeepCookie() {
+	orderId := uuid.New().String()
+	cookie := &http.Cookie{Name: common.CustomerTokenCookiesName, Value: "customer_token"}
+
+	ext := &extMock.Service{}
+	ext.On("ProcessPaymentReturn", mock2.Anything, mock2.MatchedBy(func(req *billingext.PaymentReturnRequest) bool {
+		return req.Method == http.MethodGet && req.Payload["cres"] == "cres" && req.Cookie == "customer_token"
+	})).Return(&billingext.PaymentReturnResponse{
+		Status: pkg.ResponseStatusOk,
+		Item:   &billingext.PaymentReturnResult{Status: billingext.PaymentReturnStatusFailed},
+	}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodGet, orderId, url.Values{"cres": []string{"cres"}}, cookie)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusSeeOther, res.Code)
+	assert.Contains(suite.T(), res.Header().Get(echo.HeaderLocation), "payment_status=failed")
+	assert.Empty(suite.T(), res.Result().Cookies())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_ResultPage() {
+	orderId := uuid.New().String()
+	cfg := *suite.router.cfg.Get()
+	cfg.PaymentReturnRedirect = false
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+
+	ext := &extMock.Service{}
+	ext.On("ProcessPaymentReturn", mock2.Anything, mock2.Anything).
+		Return(&billingext.PaymentReturnResponse{
+			Status: pkg.ResponseStatusOk,
+			Item:   &billingext.PaymentReturnResult{Status: billingext.PaymentReturnStatusPending},
+		}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodPost, orderId, url.Values{"PaRes": []string{"pares"}}, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), "Payment is processing")
+	assert.Contains(suite.T(), res.Body.String(), orderId+"?payment_status=pending")
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_OrderIdValidationError() {
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodGet, "order_id", url.Values{}, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_TooManyParameters() {
+	form := url.Values{}
+
+	for i := 0; i <= paymentReturnMaxParameters; i++ {
+		form.Set(fmt.Sprintf("param%d", i), "value")
+	}
+
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodPost, uuid.New().String(), form, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_BillingReturnError() {
+	ext := &extMock.Service{}
+	ext.On("ProcessPaymentReturn", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodPost, uuid.New().String(), url.Values{}, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessPaymentReturn_BillingResponseStatusError() {
+	ext := &extMock.Service{}
+	ext.On("ProcessPaymentReturn", mock2.Anything, mock2.Anything).
+		Return(&billingext.PaymentReturnResponse{Status: http.StatusNotFound}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessPaymentReturnTest(http.MethodGet, uuid.New().String(), url.Values{}, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, res.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+// Test ProcessCreateWalletPayment route
+func (suite *PaymentTestSuite) executeProcessCreateWalletPaymentTest(body string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + paymentWalletPath).
+		Init(test.ReqInitJSON()).
+		BodyString(body).
+		Exec(suite.T())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreateWalletPayment_Ok() {
+	orderId := uuid.New().String()
+	body := `{"order_id": "` + orderId + `", "wallet": "apple_pay", "token": {"paymentData": {"data": "encrypted"}}}`
+
+	ext := &extMock.Service{}
+	ext.On("PaymentCreateByWallet", mock2.Anything, mock2.MatchedBy(func(req *billingext.WalletPaymentRequest) bool {
+		return req.OrderId == orderId && req.Wallet == billingext.WalletApplePay &&
+			req.Token == `{"paymentData": {"data": "encrypted"}}`
+	})).Return(&billingext.WalletPaymentResponse{Status: pkg.ResponseStatusOk, RedirectUrl: "url", NeedRedirect: true}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeProcessCreateWalletPaymentTest(body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.JSONEq(suite.T(), `{"redirect_url": "url", "need_redirect": true}`, res.Body.String())
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreateWalletPayment_WalletValidationError() {
+	body := `{"order_id": "` + uuid.New().String() + `", "wallet": "samsung_pay", "token": {}}`
+
+	_, err := suite.executeProcessCreateWalletPaymentTest(body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreateWalletPayment_IncorrectToken() {
+	body := `{"order_id": "` + uuid.New().String() + `", "wallet": "google_pay", "token": "token"}`
+
+	_, err := suite.executeProcessCreateWalletPaymentTest(body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectWalletToken, httpErr.Message)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreateWalletPayment_BillingReturnError() {
+	body := `{"order_id": "` + uuid.New().String() + `", "wallet": "google_pay", "token": {"signature": "signature"}}`
+
+	ext := &extMock.Service{}
+	ext.On("PaymentCreateByWallet", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeProcessCreateWalletPaymentTest(body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+}
+
+func (suite *PaymentTestSuite) Test_ProcessCreateWalletPayment_BillingResponseStatusError() {
+	body := `{"order_id": "` + uuid.New().String() + `", "wallet": "google_pay", "token": {"signature": "signature"}}`
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("PaymentCreateByWallet", mock2.Anything, mock2.Anything).
+		Return(&billingext.WalletPaymentResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	_, err := suite.executeProcessCreateWalletPaymentTest(body)
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+}
@@ -4,7 +4,9 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/config"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
 	"gopkg.in/go-playground/validator.v9"
+	"time"
 )
 
 func ProviderHandlers(
@@ -12,19 +14,26 @@ func ProviderHandlers(
 	srv common.Services,
 	validator *validator.Validate,
 	set provider.AwareSet,
-	cfg *common.Config,
+	globalCfg *common.GlobalConfig,
 ) (common.Handlers, func(), error) {
+	cfg := globalCfg.Get()
 	hSet := common.HandlerSet{
-		Services: srv,
-		Validate: validator,
-		AwareSet: set,
+		Services:    srv,
+		Validate:    validator,
+		AwareSet:    set,
+		OrderStates: orderstate.NewStore(time.Duration(cfg.OrderStatusTtlSeconds)*time.Second, cfg.OrderStatusMaxOrders),
 	}
-	copyCfg := *cfg
 
 	return []common.Handler{
-		NewCountryRoute(hSet, &copyCfg),
-		NewOrderRoute(hSet, &copyCfg),
-		NewPaymentRoute(hSet, &copyCfg),
-		NewRecurringRoute(hSet, &copyCfg),
+		NewBinRoute(hSet, globalCfg),
+		NewCountryRoute(hSet, globalCfg),
+		NewDeviceRoute(hSet, globalCfg),
+		NewEventRoute(hSet, globalCfg),
+		NewMerchantRoute(hSet, globalCfg),
+		NewOrderRoute(hSet, globalCfg),
+		NewPaymentRoute(hSet, globalCfg),
+		NewQuoteRoute(hSet, globalCfg),
+		NewRecurringRoute(hSet, globalCfg),
+		NewWalletRoute(hSet, globalCfg),
 	}, func() {}, nil
 }
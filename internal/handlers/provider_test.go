@@ -6,18 +6,22 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/go-playground/validator.v9"
 	"testing"
 )
 
 func Test_Provider_Ok(t *testing.T) {
+	globalCfg, err := test.NewGlobalConfig(&common.Config{CookieDomain: "localhost", OrderInlineFormUrlMask: "http://localhost"})
+	assert.NoError(t, err)
+
 	handlers, fn, err := ProviderHandlers(
 		config.Initial{},
 		common.Services{},
 		&validator.Validate{},
 		provider.AwareSet{Logger: logger.NewMock(context.Background(), &logger.Config{}, true)},
-		&common.Config{},
+		globalCfg,
 	)
 
 	asserts := assert.New(t)
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/helpers"
+	"github.com/paysuper/paysuper-checkout/pkg/token"
+	"net/http"
+	"time"
+)
+
+const (
+	quotePath = "/quote"
+
+	// quoteTokenPurpose distinguishes price quote tokens from other tokens signed with the same secret
+	quoteTokenPurpose = "quote"
+)
+
+type QuoteLocationRequest struct {
+	Ip      string `json:"ip" validate:"omitempty,ip"`
+	Country string `json:"country" validate:"omitempty,len=2,alpha"`
+}
+
+type QuoteResponse struct {
+	Amount      float64              `json:"amount"`
+	Tax         float64              `json:"tax"`
+	TaxRate     float64              `json:"tax_rate"`
+	TotalAmount float64              `json:"total_amount"`
+	Currency    string               `json:"currency"`
+	Country     string               `json:"country"`
+	Items       []*billing.OrderItem `json:"items"`
+	QuoteToken  string               `json:"quote_token"`
+	ExpiresAt   int64                `json:"expires_at"`
+}
+
+// quoteTokenClaims is a content of the price quote token accepted by the order creation route
+type quoteTokenClaims struct {
+	QuoteId   string `json:"quote_id"`
+	ProjectId string `json:"project_id"`
+}
+
+type QuoteRoute struct {
+	dispatch common.HandlerSet
+	cfg      *common.GlobalConfig
+	signer   *token.Signer
+	provider.LMT
+}
+
+func NewQuoteRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *QuoteRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "QuoteRoute"})
+	cfg := globalCfg.Get()
+	return &QuoteRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      globalCfg,
+		signer:   token.NewSigner(quoteTokenPurpose, cfg.SigningSecret),
+	}
+}
+
+// Reload applies signing secret of the reloaded configuration
+func (h *QuoteRoute) Reload(_ context.Context) {
+	h.signer.SetSecret(h.cfg.Get().SigningSecret)
+}
+
+func (h *QuoteRoute) Route(groups *common.Groups) {
+	groups.Shared(func(group *echo.Group) {
+		group.POST(quotePath, h.getQuote)
+	})
+}
+
+func (h *QuoteRoute) getQuote(ctx echo.Context) error {
+	if h.cfg.Get().QuoteTokenLifetimeMinutes <= 0 {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorQuotesDisabled)
+	}
+
+	req := &billing.OrderCreateRequest{}
+
+	if err := (&common.OrderJsonBinder{}).Bind(req, ctx); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	location := &QuoteLocationRequest{}
+
+	if req.RawBody != "" {
+		if err := json.Unmarshal([]byte(req.RawBody), location); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+		}
+	}
+
+	req.Cookie = helpers.GetRequestCookie(ctx, common.CustomerTokenCookiesName)
+	req.IssuerUrl = ctx.Request().Header.Get(common.HeaderReferer)
+
+	if req.ProjectId == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, common.NewValidationError(fmt.Sprintf(common.ErrorMessageMask, "ProjectId", "required")))
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	if err := h.dispatch.Validate.Struct(location); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+	}
+
+	// Location of the customer and user object are trusted only in the signed requests,
+	// otherwise price can be locked for a country chosen by the customer
+	if req.User != nil || ctx.Request().Header.Get(common.HeaderXApiSignatureHeader) != "" {
+		httpErr := common.CheckProjectAuthRequestSignature(h.dispatch, ctx, req.ProjectId)
+
+		if httpErr != nil {
+			return httpErr
+		}
+	} else {
+		location.Ip = ""
+		location.Country = ""
+	}
+
+	if location.Ip == "" {
+		location.Ip = ctx.RealIP()
+	}
+
+	quoteReq := &billingext.OrderPriceQuoteRequest{
+		Order:   req,
+		Ip:      location.Ip,
+		Country: location.Country,
+	}
+	res, err := h.dispatch.Services.BillingExt.GetOrderPriceQuote(ctx.Request().Context(), quoteReq)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(quoteReq, err, pkg.ServiceName, "GetOrderPriceQuote")
+	}
+
+	if res.Status != pkg.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	quote := res.Item
+	expires := time.Now().Add(time.Duration(h.cfg.Get().QuoteTokenLifetimeMinutes) * time.Minute)
+
+	if quote.ExpiresAt > 0 && quote.ExpiresAt < expires.Unix() {
+		expires = time.Unix(quote.ExpiresAt, 0)
+	}
+
+	quoteToken, err := h.signer.Sign(&quoteTokenClaims{QuoteId: quote.Id, ProjectId: req.ProjectId}, expires)
+
+	if err != nil {
+		h.L().Error("quote token signing failed", logger.PairArgs("err", err.Error()))
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	response := &QuoteResponse{
+		Amount:      quote.Amount,
+		Tax:         quote.Tax,
+		TaxRate:     quote.TaxRate,
+		TotalAmount: quote.TotalAmount,
+		Currency:    quote.Currency,
+		Country:     quote.Country,
+		Items:       quote.Items,
+		QuoteToken:  quoteToken,
+		ExpiresAt:   expires.Unix(),
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	billMock "github.com/paysuper/paysuper-billing-server/pkg/mocks"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/billingext"
+	extMock "github.com/paysuper/paysuper-checkout/internal/billingext/mocks"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type QuoteTestSuite struct {
+	suite.Suite
+	router *QuoteRoute
+	caller *test.EchoReqResCaller
+}
+
+func Test_Quote(t *testing.T) {
+	suite.Run(t, new(QuoteTestSuite))
+}
+
+func (suite *QuoteTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewQuoteRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *QuoteTestSuite) TearDownTest() {}
+
+func (suite *QuoteTestSuite) executeGetQuoteTest(body string, headers map[string]string) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + quotePath).
+		Init(test.ReqInitJSON()).
+		SetHeaders(headers).
+		BodyString(body).
+		Exec(suite.T())
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_Disabled() {
+	cfg := *suite.router.cfg.Get()
+	cfg.QuoteTokenLifetimeMinutes = 0
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+
+	_, err = suite.executeGetQuoteTest(`{"project": "ffffffffffffffffffffffff", "amount": 10, "currency": "USD"}`, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorQuotesDisabled, httpErr.Message)
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_Ok() {
+	body := `{"project": "ffffffffffffffffffffffff", "amount": 10, "currency": "USD", "country": "DE"}`
+	quote := &billingext.OrderPriceQuote{
+		Id:          "quote_id",
+		Amount:      10,
+		Tax:         1.9,
+		TotalAmount: 11.9,
+		Currency:    "USD",
+		Country:     "US",
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+	}
+
+	ext := &extMock.Service{}
+	ext.On("GetOrderPriceQuote", mock2.Anything, mock2.MatchedBy(func(req *billingext.OrderPriceQuoteRequest) bool {
+		return req.Country == "" && req.Ip != "" && req.Order.ProjectId == "ffffffffffffffffffffffff"
+	})).Return(&billingext.OrderPriceQuoteResponse{Status: pkg.ResponseStatusOk, Item: quote}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetQuoteTest(body, map[string]string{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `"quote_token"`)
+	assert.Contains(suite.T(), res.Body.String(), fmt.Sprintf(`"expires_at":%d`, quote.ExpiresAt))
+
+	rsp := &QuoteResponse{}
+	assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	assert.Equal(suite.T(), quote.TotalAmount, rsp.TotalAmount)
+
+	claims := &quoteTokenClaims{}
+	assert.NoError(suite.T(), suite.router.signer.Verify(rsp.QuoteToken, claims))
+	assert.Equal(suite.T(), "quote_id", claims.QuoteId)
+	assert.Equal(suite.T(), "ffffffffffffffffffffffff", claims.ProjectId)
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_Ok_SignedRequestLocation() {
+	body := `{"project": "ffffffffffffffffffffffff", "amount": 10, "currency": "USD", "ip": "127.0.0.2", "country": "DE"}`
+	headers := map[string]string{common.HeaderXApiSignatureHeader: "signature"}
+
+	bill := &billMock.BillingService{}
+	bill.On("CheckProjectRequestSignature", mock2.Anything, mock2.Anything).
+		Return(&grpc.CheckProjectRequestSignatureResponse{Status: pkg.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Billing = bill
+
+	ext := &extMock.Service{}
+	ext.On("GetOrderPriceQuote", mock2.Anything, mock2.MatchedBy(func(req *billingext.OrderPriceQuoteRequest) bool {
+		return req.Country == "DE" && req.Ip == "127.0.0.2"
+	})).Return(&billingext.OrderPriceQuoteResponse{Status: pkg.ResponseStatusOk, Item: &billingext.OrderPriceQuote{Id: "quote_id"}}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetQuoteTest(body, headers)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), `"quote_token"`)
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_BindingError() {
+	res, err := suite.executeGetQuoteTest(`{"project": 1}`, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorRequestParamsIncorrect, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_ProjectRequiredError() {
+	res, err := suite.executeGetQuoteTest(`{"amount": 10, "currency": "USD"}`, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), "ProjectId", httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_LocationValidationError() {
+	body := `{"project": "ffffffffffffffffffffffff", "ip": "not_ip"}`
+	res, err := suite.executeGetQuoteTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_UserWithoutSignatureHeader() {
+	body := `{"project": "ffffffffffffffffffffffff", "user": {"id": "1"}}`
+	res, err := suite.executeGetQuoteTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorMessageSignatureHeaderIsEmpty, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_BillingReturnError() {
+	body := `{"project": "ffffffffffffffffffffffff"}`
+
+	ext := &extMock.Service{}
+	ext.On("GetOrderPriceQuote", mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("error"))
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetQuoteTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorInternal, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
+
+func (suite *QuoteTestSuite) Test_GetQuote_BillingResponseStatusError() {
+	body := `{"project": "ffffffffffffffffffffffff"}`
+	msg := &grpc.ResponseErrorMessage{Message: "error", Code: "code"}
+
+	ext := &extMock.Service{}
+	ext.On("GetOrderPriceQuote", mock2.Anything, mock2.Anything).
+		Return(&billingext.OrderPriceQuoteResponse{Status: pkg.ResponseStatusBadData, Message: msg}, nil)
+	suite.router.dispatch.Services.BillingExt = ext
+
+	res, err := suite.executeGetQuoteTest(body, map[string]string{})
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), msg, httpErr.Message)
+	assert.NotEmpty(suite.T(), res.Body.String())
+}
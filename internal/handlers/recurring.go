@@ -17,21 +17,23 @@ const (
 
 type RecurringRoute struct {
 	dispatch common.HandlerSet
-	cfg      *common.Config
+	cfg      *common.GlobalConfig
 	provider.LMT
 }
 
-func NewRecurringRoute(set common.HandlerSet, cfg *common.Config) *RecurringRoute {
+func NewRecurringRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *RecurringRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "RecurringRoute"})
 	return &RecurringRoute{
 		dispatch: set,
 		LMT:      &set.AwareSet,
-		cfg:      cfg,
+		cfg:      globalCfg,
 	}
 }
 
 func (h *RecurringRoute) Route(groups *common.Groups) {
-	groups.Common.DELETE(removeSavedCardPath, h.removeSavedCard)
+	groups.Shared(func(group *echo.Group) {
+		group.DELETE(removeSavedCardPath, h.removeSavedCard, rejectInMaintenance(h.cfg))
+	})
 }
 
 func (h *RecurringRoute) removeSavedCard(ctx echo.Context) error {
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/paysuper/paysuper-billing-server/pkg"
+	billMock "github.com/paysuper/paysuper-billing-server/pkg/mocks"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/grpc"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/token"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	reloadFirstMask  = "https://first.checkout.pay.super.com/pay/order/"
+	reloadSecondMask = "https://second.checkout.pay.super.com/pay/order/"
+)
+
+type ReloadTestSuite struct {
+	suite.Suite
+	router    *OrderRoute
+	caller    *test.EchoReqResCaller
+	globalCfg *common.GlobalConfig
+	base      common.Config
+	next      atomic.Value
+}
+
+func Test_Reload(t *testing.T) {
+	suite.Run(t, new(ReloadTestSuite))
+}
+
+func (suite *ReloadTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.base = *set.GlobalConfig.Get()
+		suite.setNext(reloadFirstMask, suite.base.CookieDomain)
+
+		suite.globalCfg, e = common.NewGlobalConfig(func(cfg *common.Config) error {
+			*cfg = *suite.next.Load().(*common.Config)
+			return nil
+		})
+
+		if e != nil {
+			panic(e)
+		}
+
+		suite.router = NewOrderRoute(set.HandlerSet, suite.globalCfg)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+
+	bill := &billMock.BillingService{}
+	bill.On("IsOrderCanBePaying", mock2.Anything, mock2.Anything).
+		Return(&grpc.IsOrderCanBePayingResponse{Status: pkg.ResponseStatusOk, Item: &billing.Order{Uuid: uuid.New().String()}}, nil)
+	suite.router.dispatch.Services.Billing = bill
+}
+
+func (suite *ReloadTestSuite) setNext(mask, cookieDomain string) {
+	cfg := suite.base
+	cfg.OrderInlineFormUrlMask = mask
+	cfg.CookieDomain = cookieDomain
+	suite.next.Store(&cfg)
+}
+
+func (suite *ReloadTestSuite) getPaymentFormUrl() string {
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + orderPath).
+		Init(test.ReqInitJSON()).
+		BodyString(fmt.Sprintf(`{"order": "%s"}`, uuid.New().String())).
+		Exec(suite.T())
+
+	if !assert.NoError(suite.T(), err) || !assert.Equal(suite.T(), http.StatusOK, res.Code) {
+		return ""
+	}
+
+	rsp := &CreateOrderJsonProjectResponse{}
+	assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+
+	return rsp.PaymentFormUrl
+}
+
+func (suite *ReloadTestSuite) Test_Reload_Ok() {
+	assert.True(suite.T(), strings.HasPrefix(suite.getPaymentFormUrl(), reloadFirstMask))
+
+	suite.setNext(reloadSecondMask, suite.base.CookieDomain)
+	assert.NoError(suite.T(), suite.globalCfg.Reload())
+
+	assert.Equal(suite.T(), reloadSecondMask, suite.globalCfg.Get().OrderInlineFormUrlMask)
+	assert.True(suite.T(), strings.HasPrefix(suite.getPaymentFormUrl(), reloadSecondMask))
+}
+
+func (suite *ReloadTestSuite) Test_Reload_InvalidConfigRejected() {
+	previous := suite.globalCfg.Get()
+
+	suite.setNext(reloadSecondMask, "")
+	assert.Error(suite.T(), suite.globalCfg.Reload())
+
+	suite.setNext("/pay/order/", suite.base.CookieDomain)
+	assert.Error(suite.T(), suite.globalCfg.Reload())
+
+	assert.Equal(suite.T(), previous, suite.globalCfg.Get())
+	assert.True(suite.T(), strings.HasPrefix(suite.getPaymentFormUrl(), reloadFirstMask))
+}
+
+func (suite *ReloadTestSuite) Test_Reload_SigningSecretRequired() {
+	cfg := suite.base
+	cfg.SigningSecret = ""
+	suite.next.Store(&cfg)
+
+	assert.Error(suite.T(), suite.globalCfg.Reload())
+
+	cfg.QuoteTokenLifetimeMinutes = 0
+	cfg.AttributionParameters = ""
+	suite.next.Store(&cfg)
+
+	assert.NoError(suite.T(), suite.globalCfg.Reload())
+}
+
+func (suite *ReloadTestSuite) Test_Reload_RouteSettings() {
+	tkn, err := suite.router.signer.Sign(&quoteTokenClaims{QuoteId: "quote"}, time.Now().Add(time.Minute))
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), suite.router.crawler.IsCrawler("Crawler/1.0"))
+
+	cfg := suite.base
+	cfg.SigningSecret = "another"
+	cfg.PromoCodeAttemptsLimit = 1
+	cfg.PaylinkCrawlerUserAgents = "crawler"
+	suite.next.Store(&cfg)
+
+	assert.NoError(suite.T(), suite.globalCfg.Reload())
+	suite.router.Reload(context.Background())
+
+	assert.Equal(suite.T(), token.ErrInvalid, suite.router.signer.Verify(tkn, &quoteTokenClaims{}))
+	assert.True(suite.T(), suite.router.promoLimiter.Allow("order"))
+	assert.False(suite.T(), suite.router.promoLimiter.Allow("order"))
+	assert.True(suite.T(), suite.router.crawler.IsCrawler("Crawler/1.0"))
+}
+
+func (suite *ReloadTestSuite) Test_Reload_ConcurrentRequests() {
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	reloaded := make(chan struct{})
+
+	go func() {
+		defer close(reloaded)
+
+		masks := []string{reloadSecondMask, "", reloadFirstMask}
+
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			mask := masks[i%len(masks)]
+			suite.setNext(mask, suite.base.CookieDomain)
+			err := suite.globalCfg.Reload()
+
+			if mask == "" {
+				assert.Error(suite.T(), err)
+			} else {
+				assert.NoError(suite.T(), err)
+			}
+		}
+	}()
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				formUrl := suite.getPaymentFormUrl()
+				assert.True(
+					suite.T(),
+					strings.HasPrefix(formUrl, reloadFirstMask) || strings.HasPrefix(formUrl, reloadSecondMask),
+					formUrl,
+				)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	<-reloaded
+
+	mask := suite.globalCfg.Get().OrderInlineFormUrlMask
+	assert.True(suite.T(), mask == reloadFirstMask || mask == reloadSecondMask, mask)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	versionSunsetDate   = "2021-06-30"
+	versionSunsetHeader = "Wed, 30 Jun 2021 00:00:00 GMT"
+)
+
+type VersionTestSuite struct {
+	suite.Suite
+	router *OrderRoute
+	caller *test.EchoReqResCaller
+}
+
+func Test_Version(t *testing.T) {
+	suite.Run(t, new(VersionTestSuite))
+}
+
+func (suite *VersionTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	global := settings["dispatcher"].(map[string]interface{})["global"].(map[string]interface{})
+	global["apiV1SunsetDate"] = versionSunsetDate
+
+	suite.caller, e = test.SetUp(settings, common.Services{}, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewOrderRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *VersionTestSuite) executeOrderStatus(groupPath string) *httptest.ResponseRecorder {
+	orderId := uuid.New().String()
+	suite.router.dispatch.OrderStates.Set(orderstate.State{OrderId: orderId, Status: "created"})
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(groupPath + orderStatusPath).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	return res
+}
+
+func (suite *VersionTestSuite) Test_V1_Deprecated() {
+	res := suite.executeOrderStatus(common.NoAuthGroupPath)
+
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), "true", res.Header().Get(common.HeaderDeprecation))
+	assert.Equal(suite.T(), versionSunsetHeader, res.Header().Get(common.HeaderSunset))
+}
+
+func (suite *VersionTestSuite) Test_V2_Served() {
+	res := suite.executeOrderStatus(common.ApiV2GroupPath)
+
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderDeprecation))
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderSunset))
+}
+
+func (suite *VersionTestSuite) Test_V1_NotDeprecatedWithoutSunset() {
+	var e error
+	suite.caller, e = test.SetUp(test.DefaultSettings(), common.Services{}, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewOrderRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	assert.NoError(suite.T(), e)
+
+	res := suite.executeOrderStatus(common.NoAuthGroupPath)
+
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderDeprecation))
+}
+
+func (suite *VersionTestSuite) Test_GetApiVersion() {
+	assert.Equal(suite.T(), common.ApiV1, common.GetApiVersion(common.NoAuthGroupPath+orderStatusPath))
+	assert.Equal(suite.T(), common.ApiV2, common.GetApiVersion(common.ApiV2GroupPath+orderStatusPath))
+	assert.Equal(suite.T(), common.ApiV1, common.GetApiVersion(common.NoAuthGroupPath))
+	assert.Empty(suite.T(), common.GetApiVersion("/api/v10/orders"))
+	assert.Empty(suite.T(), common.GetApiVersion("/.well-known/apple-developer-merchantid-domain-association"))
+}
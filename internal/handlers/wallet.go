@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/pkg/applepay"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	applePaySessionPath           = "/orders/:order_id/wallet/apple_pay/session"
+	applePayDomainAssociationPath = "/.well-known/apple-developer-merchantid-domain-association"
+
+	applePaySessionTimeout = 30 * time.Second
+)
+
+type ApplePaySessionRequest struct {
+	OrderId       string `json:"-" param:"order_id" validate:"required,uuid"`
+	ValidationUrl string `json:"validation_url" validate:"required,url,max=2048"`
+}
+
+type WalletRoute struct {
+	dispatch common.HandlerSet
+	cfg      *common.GlobalConfig
+	// mx guards Apple Pay client replaced on reload
+	mx             sync.RWMutex
+	applePay       *applepay.Client
+	sessionLimiter *ratelimit.Limiter
+	provider.LMT
+}
+
+func NewWalletRoute(set common.HandlerSet, globalCfg *common.GlobalConfig) *WalletRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "WalletRoute"})
+	cfg := globalCfg.Get()
+
+	route := &WalletRoute{
+		dispatch:       set,
+		LMT:            &set.AwareSet,
+		cfg:            globalCfg,
+		sessionLimiter: ratelimit.New(cfg.ApplePaySessionLimit, time.Minute),
+	}
+	applePay, err := route.newApplePayClient(cfg)
+
+	// payment form must work without apple pay if merchant identity is broken
+	if err != nil {
+		applePay = applepay.NewWithHttpClient(getApplePayConfig(cfg), nil)
+	}
+
+	route.applePay = applePay
+
+	return route
+}
+
+// Reload applies Apple Pay session limit and merchant identity of the reloaded configuration,
+// merchant identity certificate is loaded again to pick up the rotated files, the previous one is kept if it's broken
+func (h *WalletRoute) Reload(_ context.Context) {
+	cfg := h.cfg.Get()
+	h.sessionLimiter.SetLimit(cfg.ApplePaySessionLimit, time.Minute)
+
+	applePay, err := h.newApplePayClient(cfg)
+
+	if err != nil {
+		return
+	}
+
+	h.mx.Lock()
+	h.applePay = applePay
+	h.mx.Unlock()
+}
+
+// newApplePayClient returns Apple Pay client with merchant identity certificate loaded from the configured files
+func (h *WalletRoute) newApplePayClient(cfg *common.Config) (*applepay.Client, error) {
+	applePay, err := applepay.New(getApplePayConfig(cfg))
+
+	if err != nil {
+		h.L().Error(
+			"apple pay merchant identity can't be loaded",
+			logger.PairArgs("err", err.Error(), "cert_file", cfg.ApplePayMerchantCertFile),
+		)
+		return nil, err
+	}
+
+	return applePay, nil
+}
+
+func (h *WalletRoute) getApplePay() *applepay.Client {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+	return h.applePay
+}
+
+func getApplePayConfig(cfg *common.Config) applepay.Config {
+	return applepay.Config{
+		MerchantIdentifier: cfg.ApplePayMerchantIdentifier,
+		DisplayName:        cfg.ApplePayDisplayName,
+		Domain:             cfg.ApplePayDomain,
+		CertFile:           cfg.ApplePayMerchantCertFile,
+		KeyFile:            cfg.ApplePayMerchantKeyFile,
+		AllowedHosts:       strings.Split(cfg.ApplePayValidationHosts, ","),
+		Timeout:            applePaySessionTimeout,
+	}
+}
+
+func (h *WalletRoute) Route(groups *common.Groups) {
+	groups.Shared(func(group *echo.Group) {
+		group.POST(applePaySessionPath, h.createApplePaySession, rejectInMaintenance(h.cfg))
+	})
+	groups.Root.GET(applePayDomainAssociationPath, h.getApplePayDomainAssociation)
+}
+
+// createApplePaySession validates merchant in Apple Pay and returns payment session to the payment form
+func (h *WalletRoute) createApplePaySession(ctx echo.Context) error {
+	req := &ApplePaySessionRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	applePay := h.getApplePay()
+
+	if !applePay.IsConfigured() {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, common.ErrorApplePayNotConfigured)
+	}
+
+	if !h.sessionLimiter.Allow(req.OrderId) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorTooManyRequests)
+	}
+
+	session, err := applePay.ValidateMerchant(ctx.Request().Context(), req.ValidationUrl)
+
+	if err != nil {
+		if err == applepay.ErrValidationUrlNotAllowed {
+			return echo.NewHTTPError(http.StatusBadRequest, common.ErrorApplePayValidationUrlInvalid)
+		}
+
+		h.L().Error(
+			"apple pay merchant validation failed",
+			logger.PairArgs("err", err.Error(), common.RequestParameterOrderId, req.OrderId),
+		)
+		return echo.NewHTTPError(http.StatusBadGateway, common.ErrorApplePaySessionFailed)
+	}
+
+	return ctx.JSONBlob(http.StatusOK, session)
+}
+
+// getApplePayDomainAssociation returns domain association file required by Apple Pay domain verification
+func (h *WalletRoute) getApplePayDomainAssociation(ctx echo.Context) error {
+	cfg := h.cfg.Get()
+
+	if cfg.ApplePayDomainAssociationFile == "" {
+		return echo.ErrNotFound
+	}
+
+	if _, err := os.Stat(cfg.ApplePayDomainAssociationFile); err != nil {
+		h.L().Error(
+			"apple pay domain association file isn't available",
+			logger.PairArgs("err", err.Error(), "file", cfg.ApplePayDomainAssociationFile),
+		)
+		return echo.ErrNotFound
+	}
+
+	return ctx.File(cfg.ApplePayDomainAssociationFile)
+}
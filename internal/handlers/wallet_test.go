@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-checkout/internal/test"
+	"github.com/paysuper/paysuper-checkout/pkg/applepay"
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+type WalletTestSuite struct {
+	suite.Suite
+	router *WalletRoute
+	caller *test.EchoReqResCaller
+	stub   *httptest.Server
+}
+
+func Test_Wallet(t *testing.T) {
+	suite.Run(t, new(WalletTestSuite))
+}
+
+func (suite *WalletTestSuite) SetupTest() {
+	var e error
+
+	settings := test.DefaultSettings()
+	srv := common.Services{}
+
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		suite.router = NewWalletRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+
+	if e != nil {
+		panic(e)
+	}
+
+	suite.stub = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/paymentservices/startSession" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"merchantSessionIdentifier":"session","signature":"signature"}`))
+	}))
+
+	u, _ := url.Parse(suite.stub.URL)
+	cfg := applepay.Config{
+		MerchantIdentifier: "merchant.com.pay.super",
+		DisplayName:        "PaySuper",
+		Domain:             "checkout.pay.super.com",
+		AllowedHosts:       []string{u.Hostname()},
+	}
+	suite.router.applePay = applepay.NewWithHttpClient(cfg, suite.stub.Client())
+}
+
+func (suite *WalletTestSuite) TearDownTest() {
+	suite.stub.Close()
+}
+
+// Test CreateApplePaySession route
+func (suite *WalletTestSuite) executeCreateApplePaySessionTest(orderId, validationUrl string) (*httptest.ResponseRecorder, error) {
+	body, _ := json.Marshal(map[string]string{"validation_url": validationUrl})
+
+	return suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterOrderId, orderId).
+		Path(common.NoAuthGroupPath + applePaySessionPath).
+		Init(test.ReqInitJSON()).
+		BodyBytes(body).
+		Exec(suite.T())
+}
+
+func (suite *WalletTestSuite) Test_CreateApplePaySession_Ok() {
+	res, err := suite.executeCreateApplePaySessionTest(uuid.New().String(), suite.stub.URL+"/paymentservices/startSession")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.JSONEq(suite.T(), `{"merchantSessionIdentifier":"session","signature":"signature"}`, res.Body.String())
+}
+
+func (suite *WalletTestSuite) Test_CreateApplePaySession_OrderIdValidationError() {
+	_, err := suite.executeCreateApplePaySessionTest("order_id", suite.stub.URL+"/paymentservices/startSession")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIncorrectOrderId, httpErr.Message)
+}
+
+func (suite *WalletTestSuite) Test_CreateApplePaySession_NotConfigured() {
+	suite.router.applePay = applepay.NewWithHttpClient(applepay.Config{}, nil)
+
+	_, err := suite.executeCreateApplePaySessionTest(uuid.New().String(), suite.stub.URL+"/paymentservices/startSession")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorApplePayNotConfigured, httpErr.Message)
+}
+
+func (suite *WalletTestSuite) reloadApplePay(merchantIdentifier, certFile string) {
+	cfg := *suite.router.cfg.Get()
+	cfg.ApplePayMerchantIdentifier = merchantIdentifier
+	cfg.ApplePayMerchantCertFile = certFile
+	cfg.ApplePayMerchantKeyFile = certFile
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+
+	suite.router.Reload(context.Background())
+}
+
+func (suite *WalletTestSuite) Test_Reload_MerchantIdentityRemoved() {
+	suite.reloadApplePay("", "")
+
+	_, err := suite.executeCreateApplePaySessionTest(uuid.New().String(), suite.stub.URL+"/paymentservices/startSession")
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, httpErr.Code)
+}
+
+func (suite *WalletTestSuite) Test_Reload_BrokenMerchantIdentityKept() {
+	suite.reloadApplePay("merchant.com.pay.super", "not_exists.pem")
+
+	res, err := suite.executeCreateApplePaySessionTest(uuid.New().String(), suite.stub.URL+"/paymentservices/startSession")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+}
+
+func (suite *WalletTestSuite) Test_CreateApplePaySession_ValidationUrlNotAllowed() {
+	_, err := suite.executeCreateApplePaySessionTest(uuid.New().String(), "https://attacker.com/paymentservices/startSession")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorApplePayValidationUrlInvalid, httpErr.Message)
+}
+
+func (suite *WalletTestSuite) Test_CreateApplePaySession_ValidationFailed() {
+	_, err := suite.executeCreateApplePaySessionTest(uuid.New().String(), suite.stub.URL+"/unknown")
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadGateway, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorApplePaySessionFailed, httpErr.Message)
+}
+
+func (suite *WalletTestSuite) Test_CreateApplePaySession_TooManyRequests() {
+	orderId := uuid.New().String()
+	suite.router.sessionLimiter = ratelimit.New(1, time.Hour)
+
+	res, err := suite.executeCreateApplePaySessionTest(orderId, suite.stub.URL+"/paymentservices/startSession")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	_, err = suite.executeCreateApplePaySessionTest(orderId, suite.stub.URL+"/paymentservices/startSession")
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorTooManyRequests, httpErr.Message)
+}
+
+// Test GetApplePayDomainAssociation route
+func (suite *WalletTestSuite) executeGetApplePayDomainAssociationTest() (*httptest.ResponseRecorder, error) {
+	return suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(applePayDomainAssociationPath).
+		Exec(suite.T())
+}
+
+func (suite *WalletTestSuite) Test_GetApplePayDomainAssociation_Ok() {
+	file, err := ioutil.TempFile("", "apple-developer-merchantid-domain-association")
+	assert.NoError(suite.T(), err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("association")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), file.Close())
+
+	cfg := *suite.router.cfg.Get()
+	cfg.ApplePayDomainAssociationFile = file.Name()
+	suite.router.cfg, err = test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+
+	res, err := suite.executeGetApplePayDomainAssociationTest()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), "association", res.Body.String())
+}
+
+func (suite *WalletTestSuite) Test_GetApplePayDomainAssociation_NotConfigured() {
+	cfg := *suite.router.cfg.Get()
+	cfg.ApplePayDomainAssociationFile = ""
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+
+	_, err = suite.executeGetApplePayDomainAssociationTest()
+
+	assert.Equal(suite.T(), echo.ErrNotFound, err)
+}
+
+func (suite *WalletTestSuite) Test_GetApplePayDomainAssociation_FileNotFound() {
+	cfg := *suite.router.cfg.Get()
+	cfg.ApplePayDomainAssociationFile = "/unknown/apple-developer-merchantid-domain-association"
+	globalCfg, err := test.NewGlobalConfig(&cfg)
+	assert.NoError(suite.T(), err)
+	suite.router.cfg = globalCfg
+
+	_, err = suite.executeGetApplePayDomainAssociationTest()
+
+	assert.Equal(suite.T(), echo.ErrNotFound, err)
+}
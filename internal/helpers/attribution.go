@@ -0,0 +1,156 @@
+package helpers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-checkout/pkg/token"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	attributionValueMaxLength = 255
+
+	// AttributionTokenPurpose distinguishes attribution cookies from other tokens signed with the same secret
+	AttributionTokenPurpose = "attribution"
+)
+
+// Attribution contains marketing parameters of the customer visit and the referrer of the first visit
+type Attribution struct {
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Referrer   string            `json:"referrer,omitempty"`
+	LandedAt   int64             `json:"landed_at,omitempty"`
+}
+
+// IsEmpty
+func (a *Attribution) IsEmpty() bool {
+	return a == nil || (len(a.Parameters) == 0 && a.Referrer == "")
+}
+
+// Get returns parameter value by name or empty string
+func (a *Attribution) Get(name string) string {
+	if a == nil {
+		return ""
+	}
+
+	return a.Parameters[name]
+}
+
+// AttributionExtractor extracts attribution from the request query and keeps it in the signed cookie
+type AttributionExtractor struct {
+	settings   atomic.Value
+	signer     *token.Signer
+	cookieName string
+}
+
+type attributionSettings struct {
+	parameters []string
+	lifetime   time.Duration
+}
+
+// NewAttributionExtractor returns extractor for the comma separated list of allowed query parameters
+func NewAttributionExtractor(
+	parameters string,
+	signer *token.Signer,
+	cookieName string,
+	lifetime time.Duration,
+) *AttributionExtractor {
+	e := &AttributionExtractor{
+		signer:     signer,
+		cookieName: cookieName,
+	}
+	e.Set(parameters, lifetime)
+	return e
+}
+
+// Set replaces the comma separated list of allowed query parameters and the cookie lifetime,
+// attribution is disabled if the list is empty
+func (e *AttributionExtractor) Set(parameters string, lifetime time.Duration) {
+	settings := &attributionSettings{lifetime: lifetime}
+
+	for _, parameter := range strings.Split(parameters, ",") {
+		parameter = strings.TrimSpace(parameter)
+
+		if parameter != "" {
+			settings.parameters = append(settings.parameters, parameter)
+		}
+	}
+
+	e.settings.Store(settings)
+}
+
+// Extract returns attribution of the request. Parameters from the request query replace parameters saved in the cookie,
+// referrer of the first visit is kept
+func (e *AttributionExtractor) Extract(ctx echo.Context) *Attribution {
+	settings := e.settings.Load().(*attributionSettings)
+	attribution := &Attribution{}
+
+	if len(settings.parameters) == 0 {
+		return attribution
+	}
+
+	if value := GetRequestCookie(ctx, e.cookieName); value != "" {
+		if err := e.signer.Verify(value, attribution); err != nil {
+			attribution = &Attribution{}
+		}
+	}
+
+	query := ctx.QueryParams()
+	parameters := make(map[string]string)
+
+	for _, name := range settings.parameters {
+		if value := truncate(query.Get(name)); value != "" {
+			parameters[name] = value
+		}
+	}
+
+	if len(parameters) > 0 {
+		attribution.Parameters = parameters
+	}
+
+	if attribution.Referrer == "" {
+		attribution.Referrer = truncate(ctx.Request().Referer())
+	}
+
+	if attribution.LandedAt == 0 && !attribution.IsEmpty() {
+		attribution.LandedAt = time.Now().Unix()
+	}
+
+	return attribution
+}
+
+// Save keeps attribution in the signed cookie of the domain to pass it through redirects
+func (e *AttributionExtractor) Save(ctx echo.Context, attribution *Attribution, domain string) error {
+	if attribution.IsEmpty() {
+		return nil
+	}
+
+	expires := time.Now().Add(e.settings.Load().(*attributionSettings).lifetime)
+	value, err := e.signer.Sign(attribution, expires)
+
+	if err != nil {
+		return err
+	}
+
+	SetResponseCookie(ctx, e.cookieName, value, domain, expires)
+	return nil
+}
+
+func truncate(value string) string {
+	if len(value) <= attributionValueMaxLength {
+		return value
+	}
+
+	// cut on the character boundary
+	cut := 0
+
+	for i := range value {
+		if i > attributionValueMaxLength {
+			break
+		}
+
+		cut = i
+	}
+
+	return value[:cut]
+}
@@ -0,0 +1,50 @@
+package helpers
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// CrawlerDetector detects link preview crawlers and bots by the user agent
+type CrawlerDetector struct {
+	agents atomic.Value
+}
+
+// NewCrawlerDetector returns detector for the comma separated list of user agent substrings
+func NewCrawlerDetector(agents string) *CrawlerDetector {
+	d := &CrawlerDetector{}
+	d.Set(agents)
+	return d
+}
+
+// Set replaces the comma separated list of user agent substrings
+func (d *CrawlerDetector) Set(agents string) {
+	var list []string
+
+	for _, agent := range strings.Split(agents, ",") {
+		agent = strings.ToLower(strings.TrimSpace(agent))
+
+		if agent != "" {
+			list = append(list, agent)
+		}
+	}
+
+	d.agents.Store(list)
+}
+
+// IsCrawler reports whether the user agent contains one of the known crawler names, case insensitive
+func (d *CrawlerDetector) IsCrawler(userAgent string) bool {
+	if userAgent == "" {
+		return false
+	}
+
+	userAgent = strings.ToLower(userAgent)
+
+	for _, agent := range d.agents.Load().([]string) {
+		if strings.Contains(userAgent, agent) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package management
+
+import (
+	"context"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/micro/go-micro/errors"
+	"github.com/paysuper/paysuper-checkout/cmd/version"
+	"runtime"
+	"time"
+)
+
+const (
+	Prefix = "internal.management"
+
+	// ServiceName is used in errors returned to the operations tooling
+	ServiceName = "checkout.management"
+)
+
+// Operator is implemented by the http dispatcher which owns handlers, templates and maintenance mode
+type Operator interface {
+	InvalidateCaches(ctx context.Context) []string
+	ReloadTemplates() error
+	SetMaintenance(enabled bool)
+	Maintenance() bool
+}
+
+// Management is an internal micro service handler used by the operations tooling to manage checkout instances.
+// Handler doesn't authenticate callers, so micro service of the checkout must be reachable from the internal network only.
+type Management struct {
+	operator  Operator
+	startedAt time.Time
+	provider.LMT
+}
+
+// InvalidateCachesRequest
+type InvalidateCachesRequest struct{}
+
+// InvalidateCachesResponse
+type InvalidateCachesResponse struct {
+	Caches []string `json:"caches"`
+}
+
+// ReloadTemplatesRequest
+type ReloadTemplatesRequest struct{}
+
+// ReloadTemplatesResponse
+type ReloadTemplatesResponse struct{}
+
+// SetMaintenanceRequest
+type SetMaintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetMaintenanceResponse
+type SetMaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// VersionRequest
+type VersionRequest struct{}
+
+// VersionResponse
+type VersionResponse struct {
+	Version     string `json:"version"`
+	GoVersion   string `json:"go_version"`
+	StartedAt   int64  `json:"started_at"`
+	Maintenance bool   `json:"maintenance"`
+}
+
+// New
+func New(set provider.AwareSet, operator Operator) *Management {
+	set.Logger = set.Logger.WithFields(logger.Fields{"service": Prefix})
+
+	return &Management{
+		operator:  operator,
+		startedAt: time.Now(),
+		LMT:       &set,
+	}
+}
+
+// InvalidateCaches purges in-memory caches of the handlers, caches are filled again by the next requests
+func (m *Management) InvalidateCaches(ctx context.Context, _ *InvalidateCachesRequest, rsp *InvalidateCachesResponse) error {
+	rsp.Caches = m.operator.InvalidateCaches(ctx)
+	m.L().Info("caches invalidated", logger.PairArgs("caches", rsp.Caches))
+	return nil
+}
+
+// ReloadTemplates parses html templates again, previous templates are kept if the new ones are broken
+func (m *Management) ReloadTemplates(_ context.Context, _ *ReloadTemplatesRequest, _ *ReloadTemplatesResponse) error {
+	if err := m.operator.ReloadTemplates(); err != nil {
+		m.L().Error("templates reload failed", logger.PairArgs("err", err.Error()))
+		return errors.InternalServerError(ServiceName, "templates reload failed: %s", err.Error())
+	}
+
+	m.L().Info("templates reloaded")
+	return nil
+}
+
+// SetMaintenance enables or disables maintenance mode
+func (m *Management) SetMaintenance(_ context.Context, req *SetMaintenanceRequest, rsp *SetMaintenanceResponse) error {
+	m.operator.SetMaintenance(req.Enabled)
+	rsp.Enabled = m.operator.Maintenance()
+	m.L().Info("maintenance mode changed", logger.PairArgs("enabled", rsp.Enabled))
+	return nil
+}
+
+// Version returns build version of the instance
+func (m *Management) Version(_ context.Context, _ *VersionRequest, rsp *VersionResponse) error {
+	rsp.Version = version.Version()
+	rsp.GoVersion = runtime.Version()
+	rsp.StartedAt = m.startedAt.Unix()
+	rsp.Maintenance = m.operator.Maintenance()
+	return nil
+}
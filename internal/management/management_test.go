@@ -0,0 +1,103 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	microErrors "github.com/micro/go-micro/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type operatorStub struct {
+	caches      []string
+	reloadErr   error
+	reloads     int
+	maintenance bool
+}
+
+func (o *operatorStub) InvalidateCaches(_ context.Context) []string {
+	return o.caches
+}
+
+func (o *operatorStub) ReloadTemplates() error {
+	o.reloads++
+	return o.reloadErr
+}
+
+func (o *operatorStub) SetMaintenance(enabled bool) {
+	o.maintenance = enabled
+}
+
+func (o *operatorStub) Maintenance() bool {
+	return o.maintenance
+}
+
+type ManagementTestSuite struct {
+	suite.Suite
+	operator   *operatorStub
+	management *Management
+}
+
+func Test_Management(t *testing.T) {
+	suite.Run(t, new(ManagementTestSuite))
+}
+
+func (suite *ManagementTestSuite) SetupTest() {
+	suite.operator = &operatorStub{caches: []string{"bin", "order_status"}}
+	set := provider.AwareSet{Logger: logger.NewMock(context.Background(), &logger.Config{}, true)}
+	suite.management = New(set, suite.operator)
+}
+
+func (suite *ManagementTestSuite) Test_InvalidateCaches_Ok() {
+	rsp := &InvalidateCachesResponse{}
+	err := suite.management.InvalidateCaches(context.Background(), &InvalidateCachesRequest{}, rsp)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"bin", "order_status"}, rsp.Caches)
+}
+
+func (suite *ManagementTestSuite) Test_ReloadTemplates_Ok() {
+	err := suite.management.ReloadTemplates(context.Background(), &ReloadTemplatesRequest{}, &ReloadTemplatesResponse{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, suite.operator.reloads)
+}
+
+func (suite *ManagementTestSuite) Test_ReloadTemplates_Error() {
+	suite.operator.reloadErr = errors.New("template: payment.html:1: unexpected EOF")
+
+	err := suite.management.ReloadTemplates(context.Background(), &ReloadTemplatesRequest{}, &ReloadTemplatesResponse{})
+
+	assert.Error(suite.T(), err)
+	assert.EqualValues(suite.T(), http.StatusInternalServerError, microErrors.Parse(err.Error()).Code)
+}
+
+func (suite *ManagementTestSuite) Test_SetMaintenance_Ok() {
+	rsp := &SetMaintenanceResponse{}
+
+	err := suite.management.SetMaintenance(context.Background(), &SetMaintenanceRequest{Enabled: true}, rsp)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), rsp.Enabled)
+	assert.True(suite.T(), suite.operator.maintenance)
+
+	err = suite.management.SetMaintenance(context.Background(), &SetMaintenanceRequest{Enabled: false}, rsp)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), rsp.Enabled)
+}
+
+func (suite *ManagementTestSuite) Test_Version_Ok() {
+	suite.operator.maintenance = true
+	rsp := &VersionResponse{}
+
+	err := suite.management.Version(context.Background(), &VersionRequest{}, rsp)
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), rsp.Version)
+	assert.NotEmpty(suite.T(), rsp.GoVersion)
+	assert.True(suite.T(), rsp.StartedAt > 0)
+	assert.True(suite.T(), rsp.Maintenance)
+}
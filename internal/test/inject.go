@@ -15,20 +15,23 @@ import (
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-checkout/internal/validators"
 	"github.com/paysuper/paysuper-checkout/pkg/micro"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
 	"gopkg.in/go-playground/validator.v9"
 	"os"
+	"time"
 )
 
 type TestSet struct {
 	AwareSet     provider.AwareSet
 	Configurator config.Configurator
-	GlobalConfig *common.Config
+	GlobalConfig *common.GlobalConfig
 	HandlerSet   common.HandlerSet
 	Initial      config.Initial
 }
 
 // ProviderTestSet
-func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv common.Services, configurator config.Configurator, globalConfig *common.Config, validate *validator.Validate) (*TestSet, func(), error) {
+func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv common.Services, configurator config.Configurator, globalConfig *common.GlobalConfig, validate *validator.Validate) (*TestSet, func(), error) {
+	cfg := globalConfig.Get()
 	t := &TestSet{
 		AwareSet:     awareSet,
 		Configurator: configurator,
@@ -37,6 +40,10 @@ func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv com
 			AwareSet: awareSet,
 			Validate: validate,
 			Services: srv,
+			OrderStates: orderstate.NewStore(
+				time.Duration(cfg.OrderStatusTtlSeconds)*time.Second,
+				cfg.OrderStatusMaxOrders,
+			),
 		},
 		Initial: initial,
 	}
@@ -64,6 +71,7 @@ func BuildTestSet(ctx context.Context, settings config.Settings, srv common.Serv
 			tracing.WireTestSet,
 			wire.Struct(new(provider.AwareSet), "*"),
 			validators.WireSet,
+			dispatcher.ProviderCfg,
 			dispatcher.ProviderGlobalCfg,
 			dispatcher.ProviderValidators,
 		),
@@ -270,6 +270,7 @@ func DefaultSettings() map[string]interface{} {
 				"customerTokenCookiesLifetime": "2592000s",
 				"CookieDomain":                 "localhost",
 				"orderInlineFormUrlMask":       "http://localhost",
+				"signingSecret":                "secret",
 			},
 		},
 	}
@@ -300,6 +301,14 @@ func SetUp(settings map[string]interface{}, services common.Services, setUp func
 	return NewTestRequest(d, middlewareSetUp), e
 }
 
+// NewGlobalConfig returns holder of the global configuration which always loads a copy of cfg
+func NewGlobalConfig(cfg *common.Config) (*common.GlobalConfig, error) {
+	return common.NewGlobalConfig(func(c *common.Config) error {
+		*c = *cfg
+		return nil
+	})
+}
+
 // ReqInitJSON
 func ReqInitJSON() func(request *http.Request, middleware Middleware) {
 	return func(request *http.Request, middleware Middleware) {
@@ -307,6 +316,18 @@ func ReqInitJSON() func(request *http.Request, middleware Middleware) {
 	}
 }
 
+// ReqInitIpCountry sets country of the customer ip address like it's resolved by GeoIP
+func ReqInitIpCountry(country string) func(request *http.Request, middleware Middleware) {
+	return func(request *http.Request, middleware Middleware) {
+		middleware.Post(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(ctx echo.Context) error {
+				common.SetIpCountryContext(ctx, country)
+				return next(ctx)
+			}
+		})
+	}
+}
+
 // ReqInitMultipartForm
 func ReqInitMultipartForm() func(request *http.Request, middleware Middleware) {
 	return func(request *http.Request, middleware Middleware) {
@@ -17,8 +17,10 @@ import (
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-checkout/internal/validators"
 	"github.com/paysuper/paysuper-checkout/pkg/micro"
+	"github.com/paysuper/paysuper-checkout/pkg/orderstate"
 	"gopkg.in/go-playground/validator.v9"
 	"os"
+	"time"
 )
 
 // Injectors from inject.go:
@@ -60,7 +62,7 @@ func BuildTestSet(ctx context.Context, settings config.Settings, srv common.Serv
 		Metric: scope,
 		Tracer: tracer,
 	}
-	commonConfig, cleanup6, err := dispatcher.ProviderGlobalCfg(configurator)
+	dispatcherConfig, cleanup6, err := dispatcher.ProviderCfg(configurator)
 	if err != nil {
 		cleanup5()
 		cleanup4()
@@ -69,7 +71,7 @@ func BuildTestSet(ctx context.Context, settings config.Settings, srv common.Serv
 		cleanup()
 		return nil, nil, err
 	}
-	validatorSet, cleanup7, err := validators.Provider(srv, awareSet)
+	globalConfig, cleanup7, err := dispatcher.ProviderGlobalCfg(configurator, dispatcherConfig, awareSet)
 	if err != nil {
 		cleanup6()
 		cleanup5()
@@ -79,7 +81,7 @@ func BuildTestSet(ctx context.Context, settings config.Settings, srv common.Serv
 		cleanup()
 		return nil, nil, err
 	}
-	validate, cleanup8, err := dispatcher.ProviderValidators(validatorSet)
+	validatorSet, cleanup8, err := validators.Provider(srv, awareSet)
 	if err != nil {
 		cleanup7()
 		cleanup6()
@@ -90,7 +92,7 @@ func BuildTestSet(ctx context.Context, settings config.Settings, srv common.Serv
 		cleanup()
 		return nil, nil, err
 	}
-	testSet, cleanup9, err := ProviderTestSet(initial, awareSet, srv, configurator, commonConfig, validate)
+	validate, cleanup9, err := dispatcher.ProviderValidators(validatorSet)
 	if err != nil {
 		cleanup8()
 		cleanup7()
@@ -102,7 +104,21 @@ func BuildTestSet(ctx context.Context, settings config.Settings, srv common.Serv
 		cleanup()
 		return nil, nil, err
 	}
+	testSet, cleanup10, err := ProviderTestSet(initial, awareSet, srv, configurator, globalConfig, validate)
+	if err != nil {
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	return testSet, func() {
+		cleanup10()
 		cleanup9()
 		cleanup8()
 		cleanup7()
@@ -152,7 +168,7 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		Metric: scope,
 		Tracer: tracer,
 	}
-	commonConfig, cleanup6, err := dispatcher.ProviderGlobalCfg(configurator)
+	dispatcherConfig, cleanup6, err := dispatcher.ProviderCfg(configurator)
 	if err != nil {
 		cleanup5()
 		cleanup4()
@@ -161,11 +177,7 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
-	appSet := dispatcher.AppSet{
-		Handlers: handlers,
-		Services: srv,
-	}
-	dispatcherConfig, cleanup7, err := dispatcher.ProviderCfg(configurator)
+	globalConfig, cleanup7, err := dispatcher.ProviderGlobalCfg(configurator, dispatcherConfig, awareSet)
 	if err != nil {
 		cleanup6()
 		cleanup5()
@@ -175,6 +187,10 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
+	appSet := dispatcher.AppSet{
+		Handlers: handlers,
+		Services: srv,
+	}
 	microConfig, cleanup8, err := micro.CfgTest()
 	if err != nil {
 		cleanup7()
@@ -198,7 +214,7 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
-	dispatcherDispatcher, cleanup10, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, commonConfig, microMicro)
+	dispatcherDispatcher, cleanup10, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, globalConfig, microMicro)
 	if err != nil {
 		cleanup9()
 		cleanup8()
@@ -230,13 +246,14 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 type TestSet struct {
 	AwareSet     provider.AwareSet
 	Configurator config.Configurator
-	GlobalConfig *common.Config
+	GlobalConfig *common.GlobalConfig
 	HandlerSet   common.HandlerSet
 	Initial      config.Initial
 }
 
 // ProviderTestSet
-func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv common.Services, configurator config.Configurator, globalConfig *common.Config, validate *validator.Validate) (*TestSet, func(), error) {
+func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv common.Services, configurator config.Configurator, globalConfig *common.GlobalConfig, validate *validator.Validate) (*TestSet, func(), error) {
+	cfg := globalConfig.Get()
 	t := &TestSet{
 		AwareSet:     awareSet,
 		Configurator: configurator,
@@ -245,6 +262,10 @@ func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv com
 			AwareSet: awareSet,
 			Validate: validate,
 			Services: srv,
+			OrderStates: orderstate.NewStore(
+				time.Duration(cfg.OrderStatusTtlSeconds)*time.Second,
+				cfg.OrderStatusMaxOrders,
+			),
 		},
 		Initial: initial,
 	}
@@ -0,0 +1,55 @@
+package validators
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	countryUs = "US"
+)
+
+var (
+	postalCodeRegexps = map[string]*regexp.Regexp{
+		"US": zipUsaRegexp,
+		"GB": regexp.MustCompile("^(GIR ?0AA|[A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2})$"),
+		"CA": regexp.MustCompile("^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] ?[0-9][ABCEGHJ-NPRSTV-Z][0-9]$"),
+		"DE": regexp.MustCompile("^[0-9]{5}$"),
+		"FR": regexp.MustCompile("^[0-9]{5}$"),
+		"IT": regexp.MustCompile("^[0-9]{5}$"),
+		"ES": regexp.MustCompile("^[0-9]{5}$"),
+		"FI": regexp.MustCompile("^[0-9]{5}$"),
+		"MX": regexp.MustCompile("^[0-9]{5}$"),
+		"KR": regexp.MustCompile("^[0-9]{5}$"),
+		"UA": regexp.MustCompile("^[0-9]{5}$"),
+		"BR": regexp.MustCompile("^[0-9]{5}-?[0-9]{3}$"),
+		"JP": regexp.MustCompile("^[0-9]{3}-?[0-9]{4}$"),
+		"RU": regexp.MustCompile("^[0-9]{6}$"),
+		"CN": regexp.MustCompile("^[0-9]{6}$"),
+		"IN": regexp.MustCompile("^[0-9]{6}$"),
+		"NL": regexp.MustCompile("^[1-9][0-9]{3} ?[A-Z]{2}$"),
+		"AU": regexp.MustCompile("^[0-9]{4}$"),
+		"AT": regexp.MustCompile("^[0-9]{4}$"),
+		"BE": regexp.MustCompile("^[0-9]{4}$"),
+		"CH": regexp.MustCompile("^[0-9]{4}$"),
+		"DK": regexp.MustCompile("^[0-9]{4}$"),
+		"NO": regexp.MustCompile("^[0-9]{4}$"),
+		"SE": regexp.MustCompile("^[0-9]{3} ?[0-9]{2}$"),
+		"PL": regexp.MustCompile("^[0-9]{2}-[0-9]{3}$"),
+		"PT": regexp.MustCompile("^[0-9]{4}-[0-9]{3}$"),
+	}
+	// postalCodeDefaultRegexp is used for countries without known postal code format
+	postalCodeDefaultRegexp = regexp.MustCompile("^[A-Z0-9][A-Z0-9 \\-]{1,9}$")
+)
+
+// isPostalCode checks postal code format of the country, letters case and surrounding spaces are ignored
+func isPostalCode(country, code string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	re, ok := postalCodeRegexps[strings.ToUpper(country)]
+
+	if !ok {
+		re = postalCodeDefaultRegexp
+	}
+
+	return re.MatchString(code)
+}
@@ -4,10 +4,14 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/google/uuid"
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
 	"github.com/paysuper/paysuper-checkout/internal/dispatcher/common"
 	"github.com/ttacon/libphonenumber"
 	"gopkg.in/go-playground/validator.v9"
+	"reflect"
 	"regexp"
+	"strings"
+	"unicode"
 )
 
 type ValidatorSet struct {
@@ -20,12 +24,18 @@ var (
 	nameRegexp   = regexp.MustCompile("^[\\p{L}\\p{M} \\-\\']+$")
 	cityRegexp   = regexp.MustCompile("^[\\p{L}\\p{M} \\-\\.]+$")
 	localeRegexp = regexp.MustCompile("^[a-z]{2}-[A-Z]{2,10}$")
+	promoRegexp  = regexp.MustCompile("^[A-Za-z0-9_\\-]{3,64}$")
 )
 
-// PhoneValidator
+// PhoneValidator checks phone number in the region of the country field set by the tag parameter like phone=Country.
+// Number without the country field is only parsed as US number
 func (v *ValidatorSet) PhoneValidator(fl validator.FieldLevel) bool {
-	_, err := libphonenumber.Parse(fl.Field().String(), "US")
-	return err == nil
+	if fl.Param() == "" {
+		_, err := libphonenumber.Parse(fl.Field().String(), countryUs)
+		return err == nil
+	}
+
+	return isPhone(structStringField(fl.Parent(), fl.Param()), fl.Field().String())
 }
 
 // UuidValidator
@@ -39,6 +49,29 @@ func (v *ValidatorSet) ZipUsaValidator(fl validator.FieldLevel) bool {
 	return zipUsaRegexp.MatchString(fl.Field().String())
 }
 
+// PostalCodeValidator checks postal code by the rules of the country field set by the tag parameter like postal_code=Country
+func (v *ValidatorSet) PostalCodeValidator(fl validator.FieldLevel) bool {
+	return isPostalCode(structStringField(fl.Parent(), fl.Param()), fl.Field().String())
+}
+
+// OrderUserValidator checks phone and postal code of the order customer by the country of the customer address,
+// they aren't checked by the country if the country is unknown
+func (v *ValidatorSet) OrderUserValidator(sl validator.StructLevel) {
+	user, ok := sl.Current().Interface().(billing.OrderUser)
+
+	if !ok || user.Address == nil || user.Address.Country == "" {
+		return
+	}
+
+	if user.Phone != "" && !isPhone(user.Address.Country, user.Phone) {
+		sl.ReportError(user.Phone, "Phone", "Phone", common.RequestParameterPhone, "")
+	}
+
+	if user.Address.PostalCode != "" && !isPostalCode(user.Address.Country, user.Address.PostalCode) {
+		sl.ReportError(user.Address.PostalCode, "PostalCode", "PostalCode", common.RequestParameterPostalCode, "")
+	}
+}
+
 // NameValidator
 func (v *ValidatorSet) NameValidator(fl validator.FieldLevel) bool {
 	return nameRegexp.MatchString(fl.Field().String())
@@ -54,6 +87,68 @@ func (v *ValidatorSet) UserLocaleValidator(fl validator.FieldLevel) bool {
 	return localeRegexp.MatchString(fl.Field().String())
 }
 
+// PromoCodeValidator
+func (v *ValidatorSet) PromoCodeValidator(fl validator.FieldLevel) bool {
+	return promoRegexp.MatchString(fl.Field().String())
+}
+
+// FreeTextValidator checks customer's free text doesn't contain control characters (except line breaks and tabs)
+// and html tags brackets
+func (v *ValidatorSet) FreeTextValidator(fl validator.FieldLevel) bool {
+	for _, r := range fl.Field().String() {
+		if r == '<' || r == '>' || r == unicode.ReplacementChar {
+			return false
+		}
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// isPhone checks phone number in the region of the country, US is used if country is unknown
+func isPhone(country, phone string) bool {
+	if country == "" {
+		country = countryUs
+	}
+
+	num, err := libphonenumber.Parse(phone, strings.ToUpper(country))
+
+	if err != nil {
+		return false
+	}
+
+	return libphonenumber.IsValidNumber(num)
+}
+
+// structStringField returns value of the string field of the struct, nested field can be set like Address.Country
+func structStringField(value reflect.Value, path string) string {
+	for _, name := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return ""
+			}
+			value = value.Elem()
+		}
+
+		if value.Kind() != reflect.Struct {
+			return ""
+		}
+
+		value = value.FieldByName(name)
+
+		if !value.IsValid() {
+			return ""
+		}
+	}
+
+	if value.Kind() != reflect.String {
+		return ""
+	}
+
+	return strings.TrimSpace(value.String())
+}
+
 // New
 func New(services common.Services, set provider.AwareSet) *ValidatorSet {
 	set.Logger = set.Logger.WithFields(logger.Fields{"service": Prefix})
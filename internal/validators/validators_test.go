@@ -0,0 +1,131 @@
+package validators_test
+
+import (
+	"github.com/paysuper/paysuper-billing-server/pkg/proto/billing"
+	"github.com/paysuper/paysuper-checkout/internal/dispatcher"
+	"github.com/paysuper/paysuper-checkout/internal/validators"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+	"testing"
+)
+
+type testAddress struct {
+	Country string
+	Phone   string `validate:"omitempty,phone=Country"`
+	Zip     string `validate:"omitempty,postal_code=Country"`
+}
+
+type testZipUsa struct {
+	Country string
+	Zip     string `validate:"zip_usa"`
+}
+
+func newValidate(t *testing.T) *validator.Validate {
+	validate, _, err := dispatcher.ProviderValidators(&validators.ValidatorSet{})
+	assert.NoError(t, err)
+	return validate
+}
+
+func Test_PhoneValidator(t *testing.T) {
+	validate := newValidate(t)
+	cases := []struct {
+		country string
+		phone   string
+		valid   bool
+	}{
+		{"US", "+1 650 253 0000", true},
+		{"US", "(650) 253-0000", true},
+		{"GB", "020 7031 3000", true},
+		{"GB", "+44 20 7031 3000", true},
+		{"DE", "+1 650 253 0000", true},
+		{"US", "020 7031 3000", false},
+		{"GB", "12", false},
+		{"DE", "not a phone", false},
+		{"", "(650) 253-0000", true},
+	}
+
+	for _, c := range cases {
+		err := validate.Struct(&testAddress{Country: c.country, Phone: c.phone})
+		assert.Equal(t, c.valid, err == nil, c.country+" "+c.phone)
+	}
+}
+
+func Test_PostalCodeValidator(t *testing.T) {
+	validate := newValidate(t)
+	cases := []struct {
+		country string
+		zip     string
+		valid   bool
+	}{
+		{"US", "98001", true},
+		{"US", "98001-1234", true},
+		{"GB", "SW1A 1AA", true},
+		{"gb", "sw1a1aa", true},
+		{"CA", "K1A 0B1", true},
+		{"DE", "10115", true},
+		{"BR", "01310-100", true},
+		{"JP", "100-0001", true},
+		{"KZ", "050000", true},
+		{"US", "SW1A 1AA", false},
+		{"GB", "12345", false},
+		{"CA", "K1A0B", false},
+		{"DE", "1011", false},
+		{"BR", "01310", false},
+		{"JP", "1000001-1", false},
+		{"KZ", "!", false},
+	}
+
+	for _, c := range cases {
+		err := validate.Struct(&testAddress{Country: c.country, Zip: c.zip})
+		assert.Equal(t, c.valid, err == nil, c.country+" "+c.zip)
+	}
+}
+
+func Test_ZipUsaValidator_UsOnly(t *testing.T) {
+	validate := newValidate(t)
+	cases := []struct {
+		country string
+		zip     string
+		valid   bool
+	}{
+		{"US", "98001", true},
+		{"US", "98001-1234", true},
+		{"", "98001", true},
+		{"US", "980", false},
+		{"GB", "SW1A 1AA", false},
+		{"DE", "10115", true},
+		{"JP", "100-0001", false},
+	}
+
+	for _, c := range cases {
+		err := validate.Struct(&testZipUsa{Country: c.country, Zip: c.zip})
+		assert.Equal(t, c.valid, err == nil, c.country+" "+c.zip)
+	}
+}
+
+func Test_OrderUserValidator(t *testing.T) {
+	validate := newValidate(t)
+	cases := []struct {
+		user  *billing.OrderUser
+		valid bool
+		tag   string
+	}{
+		{&billing.OrderUser{Phone: "020 7031 3000", Address: &billing.OrderBillingAddress{Country: "GB"}}, true, ""},
+		{&billing.OrderUser{Phone: "020 7031 3000"}, true, ""},
+		{&billing.OrderUser{Phone: "020 7031 3000", Address: &billing.OrderBillingAddress{Country: "US"}}, false, "phone"},
+		{&billing.OrderUser{Address: &billing.OrderBillingAddress{Country: "GB", PostalCode: "!"}}, false, ""},
+	}
+
+	for i, c := range cases {
+		err := validate.Struct(c.user)
+
+		if c.valid {
+			assert.NoError(t, err, i)
+			continue
+		}
+
+		if assert.Error(t, err, i) && c.tag != "" {
+			assert.Equal(t, c.tag, err.(validator.ValidationErrors)[0].Tag(), i)
+		}
+	}
+}
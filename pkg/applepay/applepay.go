@@ -0,0 +1,175 @@
+package applepay
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	initiativeWeb = "web"
+
+	responseMaxSize = 1 << 16
+	redirectsMax    = 3
+)
+
+var (
+	ErrNotConfigured            = errors.New("apple pay merchant identity isn't configured")
+	ErrValidationUrlNotAllowed  = errors.New("apple pay merchant validation url isn't allowed")
+	ErrMerchantValidationFailed = errors.New("apple pay merchant validation failed")
+)
+
+// Config
+type Config struct {
+	MerchantIdentifier string
+	DisplayName        string
+	// Domain is a domain of the payment form registered in Apple Pay
+	Domain string
+	// CertFile and KeyFile are PEM files of the merchant identity certificate
+	CertFile string
+	KeyFile  string
+	// AllowedHosts is a list of hosts which merchant validation url can point to, subdomains are allowed
+	AllowedHosts []string
+	Timeout      time.Duration
+}
+
+type sessionRequest struct {
+	MerchantIdentifier string `json:"merchantIdentifier"`
+	DisplayName        string `json:"displayName"`
+	Initiative         string `json:"initiative"`
+	InitiativeContext  string `json:"initiativeContext"`
+}
+
+// Client requests Apple Pay payment sessions with the merchant identity certificate
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New returns client which uses merchant identity certificate from the config
+func New(cfg Config) (*Client, error) {
+	if cfg.MerchantIdentifier == "" || cfg.CertFile == "" || cfg.KeyFile == "" {
+		return &Client{cfg: cfg}, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+
+	return NewWithHttpClient(cfg, &http.Client{Transport: transport, Timeout: cfg.Timeout}), nil
+}
+
+// NewWithHttpClient returns client which uses http client configured by caller,
+// redirects of the client are followed only to the allowed hosts
+func NewWithHttpClient(cfg Config, client *http.Client) *Client {
+	c := &Client{cfg: cfg}
+
+	if client != nil {
+		redirecting := *client
+		redirecting.CheckRedirect = c.checkRedirect
+		c.http = &redirecting
+	}
+
+	return c
+}
+
+// IsConfigured reports whether merchant identity is configured
+func (c *Client) IsConfigured() bool {
+	return c.http != nil && c.cfg.MerchantIdentifier != ""
+}
+
+// ValidateMerchant requests payment session from Apple Pay server by the validation url received by the payment form,
+// opaque session object is returned
+func (c *Client) ValidateMerchant(ctx context.Context, validationUrl string) (json.RawMessage, error) {
+	if !c.IsConfigured() {
+		return nil, ErrNotConfigured
+	}
+
+	if !c.isAllowed(validationUrl) {
+		return nil, ErrValidationUrlNotAllowed
+	}
+
+	body, err := json.Marshal(&sessionRequest{
+		MerchantIdentifier: c.cfg.MerchantIdentifier,
+		DisplayName:        c.cfg.DisplayName,
+		Initiative:         initiativeWeb,
+		InitiativeContext:  c.cfg.Domain,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, validationUrl, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := c.http.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	session, err := ioutil.ReadAll(io.LimitReader(rsp.Body, responseMaxSize))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.StatusCode != http.StatusOK || !json.Valid(session) {
+		return nil, ErrMerchantValidationFailed
+	}
+
+	return session, nil
+}
+
+// checkRedirect stops on the redirect to the host which isn't allowed, the redirect response fails validation then
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= redirectsMax || !c.isAllowed(req.URL.String()) {
+		return http.ErrUseLastResponse
+	}
+
+	return nil
+}
+
+func (c *Client) isAllowed(validationUrl string) bool {
+	u, err := url.Parse(validationUrl)
+
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+
+	for _, allowed := range c.cfg.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+
+		if allowed != "" && (host == allowed || strings.HasSuffix(host, "."+allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
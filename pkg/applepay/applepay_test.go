@@ -0,0 +1,96 @@
+package applepay
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newStub(t *testing.T, status int) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &sessionRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		assert.Equal(t, "merchant.com.pay.super", req.MerchantIdentifier)
+		assert.Equal(t, "web", req.Initiative)
+		assert.Equal(t, "checkout.pay.super.com", req.InitiativeContext)
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"merchantSessionIdentifier":"session"}`))
+	}))
+}
+
+func newClient(server *httptest.Server) *Client {
+	u, _ := url.Parse(server.URL)
+	cfg := Config{
+		MerchantIdentifier: "merchant.com.pay.super",
+		DisplayName:        "PaySuper",
+		Domain:             "checkout.pay.super.com",
+		AllowedHosts:       []string{u.Hostname()},
+	}
+	return NewWithHttpClient(cfg, server.Client())
+}
+
+func TestClient_ValidateMerchant(t *testing.T) {
+	server := newStub(t, http.StatusOK)
+	defer server.Close()
+
+	session, err := newClient(server).ValidateMerchant(context.Background(), server.URL+"/paymentservices/startSession")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"merchantSessionIdentifier":"session"}`, string(session))
+}
+
+func TestClient_ValidateMerchant_Failed(t *testing.T) {
+	server := newStub(t, http.StatusBadRequest)
+	defer server.Close()
+
+	_, err := newClient(server).ValidateMerchant(context.Background(), server.URL+"/paymentservices/startSession")
+	assert.Equal(t, ErrMerchantValidationFailed, err)
+}
+
+func TestClient_ValidateMerchant_NotAllowedUrl(t *testing.T) {
+	c := NewWithHttpClient(Config{MerchantIdentifier: "merchant", AllowedHosts: []string{"apple.com"}}, http.DefaultClient)
+
+	for _, u := range []string{
+		"http://apple-pay-gateway.apple.com/paymentservices/startSession",
+		"https://apple.com.attacker.com/paymentservices/startSession",
+		"https://attacker.com/?apple.com",
+		"://",
+	} {
+		_, err := c.ValidateMerchant(context.Background(), u)
+		assert.Equal(t, ErrValidationUrlNotAllowed, err, u)
+	}
+
+	assert.True(t, c.isAllowed("https://apple-pay-gateway-cert.apple.com/paymentservices/startSession"))
+}
+
+func TestClient_ValidateMerchant_Redirect(t *testing.T) {
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect to the host which isn't allowed is followed")
+	}))
+	defer target.Close()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	_, err := newClient(server).ValidateMerchant(context.Background(), server.URL+"/paymentservices/startSession")
+	assert.Equal(t, ErrMerchantValidationFailed, err)
+}
+
+func TestClient_NotConfigured(t *testing.T) {
+	c, err := New(Config{})
+	assert.NoError(t, err)
+	assert.False(t, c.IsConfigured())
+
+	_, err = c.ValidateMerchant(context.Background(), "https://apple-pay-gateway.apple.com/")
+	assert.Equal(t, ErrNotConfigured, err)
+
+	_, err = New(Config{MerchantIdentifier: "merchant", CertFile: "not_exists.pem", KeyFile: "not_exists.key"})
+	assert.Error(t, err)
+}
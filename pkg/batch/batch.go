@@ -0,0 +1,149 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pending accumulates items between flushes, its methods are called under the lock of the batcher
+type Pending interface {
+	// Take returns accumulated batch and resets pending items, nil is returned if there are no items
+	Take() interface{}
+	// Restore returns batch which failed to flush back to pending items and returns count of dropped items
+	// which don't fit into the limit of pending items
+	Restore(batch interface{}) int
+}
+
+// FlushFunc writes batch taken from pending items
+type FlushFunc func(ctx context.Context, batch interface{}) error
+
+// Batcher flushes pending items periodically or as soon as they reach the limit.
+// Items are accumulated by the caller inside Do, so pending items aren't changed during Take and Restore.
+type Batcher struct {
+	mx       sync.Mutex
+	pending  Pending
+	flush    FlushFunc
+	interval time.Duration
+	dropped  int
+
+	flushMx sync.Mutex
+	running int32
+	full    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// DefaultInterval is used if interval between flushes isn't set
+const DefaultInterval = 5 * time.Second
+
+// New returns batcher, Run must be called to start periodic flushes
+func New(pending Pending, flush FlushFunc, interval time.Duration) *Batcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Batcher{
+		pending:  pending,
+		flush:    flush,
+		interval: interval,
+		full:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Do calls fn under the lock of pending items, fn returns count of items dropped because of the limit
+// and the flush is triggered then
+func (b *Batcher) Do(fn func() (dropped int)) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if dropped := fn(); dropped > 0 {
+		b.dropped += dropped
+
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Dropped returns count of items dropped because of the limit
+func (b *Batcher) Dropped() int {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.dropped
+}
+
+// Flush writes pending items, items are returned back to pending if writing fails
+func (b *Batcher) Flush(ctx context.Context) error {
+	b.flushMx.Lock()
+	defer b.flushMx.Unlock()
+
+	b.mx.Lock()
+	batch := b.pending.Take()
+	b.mx.Unlock()
+
+	if batch == nil {
+		return nil
+	}
+
+	err := b.flush(ctx, batch)
+
+	if err == nil {
+		return nil
+	}
+
+	b.mx.Lock()
+	b.dropped += b.pending.Restore(batch)
+	b.mx.Unlock()
+
+	return err
+}
+
+// Run flushes items periodically until Close is called or context is cancelled, errors are passed to onError
+func (b *Batcher) Run(ctx context.Context, onError func(err error)) {
+	if !atomic.CompareAndSwapInt32(&b.running, 0, 1) {
+		return
+	}
+
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.full:
+		case <-b.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		if err := b.Flush(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Close stops periodic flushes and writes remaining items
+func (b *Batcher) Close(ctx context.Context) error {
+	b.once.Do(func() {
+		close(b.stop)
+	})
+
+	if atomic.LoadInt32(&b.running) == 1 {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return b.Flush(ctx)
+}
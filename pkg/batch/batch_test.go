@@ -0,0 +1,147 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testPending struct {
+	max   int
+	items []int
+}
+
+func (p *testPending) Take() interface{} {
+	if len(p.items) == 0 {
+		return nil
+	}
+
+	items := p.items
+	p.items = nil
+	return items
+}
+
+func (p *testPending) Restore(b interface{}) int {
+	items := b.([]int)
+	free := len(items)
+
+	if len(p.items)+free > p.max {
+		free = p.max - len(p.items)
+	}
+
+	p.items = append(items[:free:free], p.items...)
+	return len(items) - free
+}
+
+type testSink struct {
+	mx      sync.Mutex
+	err     error
+	batches [][]int
+}
+
+func (s *testSink) flush(_ context.Context, b interface{}) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.batches = append(s.batches, b.([]int))
+	return nil
+}
+
+func (s *testSink) count() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.batches)
+}
+
+// add puts item to pending, it's dropped if pending is full
+func add(b *Batcher, p *testPending, item int) {
+	b.Do(func() int {
+		if len(p.items) >= p.max {
+			return 1
+		}
+
+		p.items = append(p.items, item)
+		return 0
+	})
+}
+
+func TestBatcher_Flush(t *testing.T) {
+	sink := &testSink{}
+	pending := &testPending{max: 2}
+	b := New(pending, sink.flush, time.Hour)
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, 0, sink.count())
+
+	add(b, pending, 1)
+	add(b, pending, 2)
+	add(b, pending, 3)
+	assert.Equal(t, 1, b.Dropped())
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, [][]int{{1, 2}}, sink.batches)
+}
+
+func TestBatcher_FlushError(t *testing.T) {
+	sink := &testSink{err: errors.New("sink error")}
+	pending := &testPending{max: 2}
+	b := New(pending, sink.flush, time.Hour)
+
+	add(b, pending, 1)
+	assert.Error(t, b.Flush(context.Background()))
+	assert.Equal(t, []int{1}, pending.items)
+
+	add(b, pending, 2)
+	add(b, pending, 3)
+	assert.Equal(t, 1, b.Dropped())
+
+	sink.err = nil
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, [][]int{{1, 2}}, sink.batches)
+}
+
+func TestBatcher_FlushWhenFull(t *testing.T) {
+	sink := &testSink{}
+	pending := &testPending{max: 1}
+	b := New(pending, sink.flush, time.Hour)
+
+	go b.Run(context.Background(), nil)
+	defer b.Close(context.Background())
+
+	add(b, pending, 1)
+	add(b, pending, 2)
+	waitFor(func() bool { return sink.count() == 1 })
+	assert.Equal(t, 1, sink.count())
+}
+
+func TestBatcher_RunAndClose(t *testing.T) {
+	sink := &testSink{}
+	pending := &testPending{max: 10}
+	b := New(pending, sink.flush, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go b.Run(ctx, nil)
+
+	add(b, pending, 1)
+	waitFor(func() bool { return sink.count() == 1 })
+	assert.Equal(t, 1, sink.count())
+
+	cancel()
+	add(b, pending, 2)
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, [][]int{{1}, {2}}, sink.batches)
+}
+
+// waitFor polls condition up to a second
+func waitFor(condition func() bool) {
+	for i := 0; i < 200 && !condition(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,78 @@
+package bin
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	TypeDebit   = "debit"
+	TypeCredit  = "credit"
+	TypePrepaid = "prepaid"
+
+	MinLength = 6
+	MaxLength = 8
+)
+
+var (
+	ErrNotFound = errors.New("bin not found")
+)
+
+// Info describes bank card by its first digits
+type Info struct {
+	Bin      string `json:"bin"`
+	Brand    string `json:"brand"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Bank     string `json:"bank"`
+	Country  string `json:"country"`
+}
+
+// Source looks up card information by the first 6-8 digits of the card number.
+// ErrNotFound is returned if source has no information about the bin.
+type Source interface {
+	Lookup(ctx context.Context, bin string) (*Info, error)
+}
+
+// SourceFunc adapts function to the Source interface
+type SourceFunc func(ctx context.Context, bin string) (*Info, error)
+
+// Lookup
+func (f SourceFunc) Lookup(ctx context.Context, bin string) (*Info, error) {
+	return f(ctx, bin)
+}
+
+type chain []Source
+
+// Chain returns source which asks sources in order until one of them knows the bin.
+// Error of the last failed source is returned if none of them found the bin.
+func Chain(sources ...Source) Source {
+	var c chain
+
+	for _, s := range sources {
+		if s != nil {
+			c = append(c, s)
+		}
+	}
+
+	return c
+}
+
+// Lookup
+func (c chain) Lookup(ctx context.Context, bin string) (*Info, error) {
+	err := ErrNotFound
+
+	for _, s := range c {
+		info, e := s.Lookup(ctx, bin)
+
+		if e == nil {
+			return info, nil
+		}
+
+		if e != ErrNotFound {
+			err = e
+		}
+	}
+
+	return nil, err
+}
@@ -0,0 +1,159 @@
+package bin
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+const testCsv = `bin,brand,type,category,bank,country
+411111,VISA,Credit,Classic,Test Bank,us
+41111122,VISA,Debit,Platinum,Other Bank,ca
+555555,MASTERCARD,debit,Standard,Test Bank,GB
+`
+
+func writeCsv(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "bins*.csv")
+	assert.NoError(t, err)
+
+	_, err = f.WriteString(content)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	return f.Name()
+}
+
+func TestCsvSource_Lookup(t *testing.T) {
+	path := writeCsv(t, testCsv)
+	defer os.Remove(path)
+
+	s, err := NewCsvSource(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.Len())
+
+	info, err := s.Lookup(context.Background(), "41111111")
+	assert.NoError(t, err)
+	assert.Equal(t, &Info{Bin: "411111", Brand: "visa", Type: TypeCredit, Category: "Classic", Bank: "Test Bank", Country: "US"}, info)
+
+	info, err = s.Lookup(context.Background(), "41111122")
+	assert.NoError(t, err)
+	assert.Equal(t, "41111122", info.Bin)
+	assert.Equal(t, TypeDebit, info.Type)
+
+	_, err = s.Lookup(context.Background(), "400000")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestCsvSource_Reload(t *testing.T) {
+	path := writeCsv(t, testCsv)
+	defer os.Remove(path)
+
+	s, err := NewCsvSource(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("400000,visa,debit,,Bank,DE\n"), 0644))
+	assert.NoError(t, s.Reload())
+	assert.Equal(t, 1, s.Len())
+
+	info, err := s.Lookup(context.Background(), "400000")
+	assert.NoError(t, err)
+	assert.Equal(t, "DE", info.Country)
+
+	// broken file keeps previously loaded bins
+	assert.NoError(t, ioutil.WriteFile(path, []byte("400000,visa\n"), 0644))
+	assert.Error(t, s.Reload())
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestNewCsvSource_Error(t *testing.T) {
+	_, err := NewCsvSource("/unknown/bins.csv")
+	assert.Error(t, err)
+
+	path := writeCsv(t, "bin,brand,type,category,bank,country\n4111,visa,debit,,Bank,US\n")
+	defer os.Remove(path)
+
+	_, err = NewCsvSource(path)
+	assert.Error(t, err)
+}
+
+func TestChain(t *testing.T) {
+	errSource := errors.New("source error")
+	calls := 0
+
+	notFound := SourceFunc(func(ctx context.Context, bin string) (*Info, error) {
+		calls++
+		return nil, ErrNotFound
+	})
+	failed := SourceFunc(func(ctx context.Context, bin string) (*Info, error) {
+		calls++
+		return nil, errSource
+	})
+	found := SourceFunc(func(ctx context.Context, bin string) (*Info, error) {
+		calls++
+		return &Info{Bin: bin}, nil
+	})
+
+	info, err := Chain(notFound, nil, failed, found).Lookup(context.Background(), "411111")
+	assert.NoError(t, err)
+	assert.Equal(t, "411111", info.Bin)
+	assert.Equal(t, 3, calls)
+
+	_, err = Chain(failed, notFound).Lookup(context.Background(), "411111")
+	assert.Equal(t, errSource, err)
+
+	_, err = Chain(notFound).Lookup(context.Background(), "411111")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = Chain().Lookup(context.Background(), "411111")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestCache(t *testing.T) {
+	calls := 0
+	var err error
+
+	source := SourceFunc(func(ctx context.Context, bin string) (*Info, error) {
+		calls++
+
+		if err != nil {
+			return nil, err
+		}
+
+		if bin == "400000" {
+			return nil, ErrNotFound
+		}
+
+		return &Info{Bin: bin}, nil
+	})
+
+	now := time.Now()
+	c := NewCache(source, 2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		info, e := c.Lookup(context.Background(), "411111")
+		assert.NoError(t, e)
+		assert.Equal(t, "411111", info.Bin)
+
+		_, e = c.Lookup(context.Background(), "400000")
+		assert.Equal(t, ErrNotFound, e)
+	}
+
+	assert.Equal(t, 2, calls)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = c.Lookup(context.Background(), "411111")
+	assert.Equal(t, 3, calls)
+
+	c.Purge()
+	err = errors.New("source error")
+
+	_, e := c.Lookup(context.Background(), "411111")
+	assert.Equal(t, err, e)
+	_, _ = c.Lookup(context.Background(), "411111")
+	assert.Equal(t, 5, calls)
+}
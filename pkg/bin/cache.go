@@ -0,0 +1,57 @@
+package bin
+
+import (
+	"context"
+	"github.com/paysuper/paysuper-checkout/pkg/ttlcache"
+	"time"
+)
+
+// Cache keeps lookup results of the source including unknown bins, so repeated digits typed by customers
+// don't reach the source. Failed lookups aren't cached.
+type Cache struct {
+	source Source
+	items  *ttlcache.Cache
+	now    func() time.Time
+}
+
+// NewCache returns cache of the source limited by size, items live for ttl
+func NewCache(source Source, size int, ttl time.Duration) *Cache {
+	c := &Cache{source: source, now: time.Now}
+	c.items = ttlcache.NewWithClock(size, ttl, func() time.Time { return c.now() })
+	return c
+}
+
+// Lookup
+func (c *Cache) Lookup(ctx context.Context, bin string) (*Info, error) {
+	if item, ok := c.items.Get(bin); ok {
+		if item == nil {
+			return nil, ErrNotFound
+		}
+		return item.(*Info), nil
+	}
+
+	info, err := c.source.Lookup(ctx, bin)
+
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	c.set(bin, info)
+
+	return info, err
+}
+
+// Purge removes all cached items
+func (c *Cache) Purge() {
+	c.items.Purge()
+}
+
+// set saves info of the bin, unknown bin is saved as untyped nil to be told apart from the cached info
+func (c *Cache) set(bin string, info *Info) {
+	if info == nil {
+		c.items.Set(bin, nil)
+		return
+	}
+
+	c.items.Set(bin, info)
+}
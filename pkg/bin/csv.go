@@ -0,0 +1,145 @@
+package bin
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	csvColumnBin = iota
+	csvColumnBrand
+	csvColumnType
+	csvColumnCategory
+	csvColumnBank
+	csvColumnCountry
+	csvColumns
+)
+
+// CsvSource looks up bins in the csv file with columns bin,brand,type,category,bank,country.
+// Header row is optional. Longest matching bin wins, so 8 digit ranges can refine 6 digit ones.
+type CsvSource struct {
+	path string
+	mx   sync.RWMutex
+	bins map[string]*Info
+}
+
+// NewCsvSource loads bins from the csv file
+func NewCsvSource(path string) (*CsvSource, error) {
+	s := &CsvSource{path: path}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload reads the file again and replaces loaded bins, previous bins are kept if file is broken
+func (s *CsvSource) Reload() error {
+	f, err := os.Open(s.path)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	bins, err := readCsv(f)
+
+	if err != nil {
+		return fmt.Errorf("%s: %s", s.path, err.Error())
+	}
+
+	s.mx.Lock()
+	s.bins = bins
+	s.mx.Unlock()
+
+	return nil
+}
+
+// Len returns count of loaded bins
+func (s *CsvSource) Len() int {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	return len(s.bins)
+}
+
+// Lookup
+func (s *CsvSource) Lookup(_ context.Context, bin string) (*Info, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if len(bin) > MaxLength {
+		bin = bin[:MaxLength]
+	}
+
+	for l := len(bin); l >= MinLength; l-- {
+		if info, ok := s.bins[bin[:l]]; ok {
+			return info, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func readCsv(r io.Reader) (map[string]*Info, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = csvColumns
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	bins := make(map[string]*Info)
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		bin := record[csvColumnBin]
+
+		if line == 1 && !isDigits(bin) {
+			continue
+		}
+
+		if !isDigits(bin) || len(bin) < MinLength || len(bin) > MaxLength {
+			return nil, fmt.Errorf("line %d: incorrect bin %q", line, bin)
+		}
+
+		bins[bin] = &Info{
+			Bin:      bin,
+			Brand:    strings.ToLower(record[csvColumnBrand]),
+			Type:     strings.ToLower(record[csvColumnType]),
+			Category: record[csvColumnCategory],
+			Bank:     record[csvColumnBank],
+			Country:  strings.ToUpper(record[csvColumnCountry]),
+		}
+	}
+
+	return bins, nil
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
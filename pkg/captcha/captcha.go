@@ -0,0 +1,80 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderReCaptcha = "recaptcha"
+	ProviderTurnstile = "turnstile"
+	ProviderFake      = "fake"
+
+	HCaptchaVerifyUrl  = "https://api.hcaptcha.com/siteverify"
+	ReCaptchaVerifyUrl = "https://www.google.com/recaptcha/api/siteverify"
+	TurnstileVerifyUrl = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+var (
+	ErrInvalidToken    = errors.New("captcha token is invalid")
+	ErrUnavailable     = errors.New("captcha verification service is unavailable")
+	ErrUnknownProvider = errors.New("captcha provider is unknown")
+)
+
+// Verifier checks token of the solved challenge
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIp string) error
+}
+
+// New returns verifier of the provider, default verification url of the provider is used if verifyUrl is empty.
+// Fake verifier accepts secret as the only valid token.
+func New(provider, verifyUrl, secret string, minScore float64, timeout time.Duration) (Verifier, error) {
+	urls := map[string]string{
+		ProviderHCaptcha:  HCaptchaVerifyUrl,
+		ProviderReCaptcha: ReCaptchaVerifyUrl,
+		ProviderTurnstile: TurnstileVerifyUrl,
+	}
+
+	if provider == ProviderFake {
+		return NewFake(secret), nil
+	}
+
+	defaultUrl, ok := urls[provider]
+
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	if verifyUrl == "" {
+		verifyUrl = defaultUrl
+	}
+
+	return NewSiteVerifier(verifyUrl, secret, minScore, timeout), nil
+}
+
+// Fake accepts predefined tokens, it's used in tests and local environments
+type Fake struct {
+	tokens map[string]bool
+}
+
+// NewFake
+func NewFake(tokens ...string) *Fake {
+	f := &Fake{tokens: make(map[string]bool, len(tokens))}
+
+	for _, token := range tokens {
+		f.tokens[token] = true
+	}
+
+	return f
+}
+
+// Verify
+func (f *Fake) Verify(_ context.Context, token, _ string) error {
+	if !f.tokens[token] {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
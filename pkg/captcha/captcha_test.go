@@ -0,0 +1,120 @@
+package captcha
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSiteVerifyStub(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+
+		switch r.PostForm.Get("response") {
+		case "valid":
+			assert.Equal(t, "127.0.0.1", r.PostForm.Get("remoteip"))
+			_, _ = w.Write([]byte(`{"success":true}`))
+		case "high_score":
+			_, _ = w.Write([]byte(`{"success":true,"score":0.9}`))
+		case "low_score":
+			_, _ = w.Write([]byte(`{"success":true,"score":0.1}`))
+		case "broken":
+			_, _ = w.Write([]byte(`not json`))
+		case "error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		}
+	}))
+}
+
+func TestSiteVerifier_Verify(t *testing.T) {
+	server := newSiteVerifyStub(t)
+	defer server.Close()
+
+	v := NewSiteVerifier(server.URL, "secret", 0.5, time.Second)
+	ctx := context.Background()
+
+	assert.NoError(t, v.Verify(ctx, "valid", "127.0.0.1"))
+	assert.NoError(t, v.Verify(ctx, "high_score", ""))
+	assert.Equal(t, ErrInvalidToken, v.Verify(ctx, "low_score", ""))
+	assert.Equal(t, ErrInvalidToken, v.Verify(ctx, "invalid", ""))
+	assert.Equal(t, ErrInvalidToken, v.Verify(ctx, "", ""))
+	assert.Equal(t, ErrUnavailable, v.Verify(ctx, "broken", ""))
+	assert.Equal(t, ErrUnavailable, v.Verify(ctx, "error", ""))
+
+	server.Close()
+	assert.Equal(t, ErrUnavailable, v.Verify(ctx, "valid", ""))
+}
+
+func TestNew(t *testing.T) {
+	for _, provider := range []string{ProviderHCaptcha, ProviderReCaptcha, ProviderTurnstile} {
+		v, err := New(provider, "", "secret", 0.5, time.Second)
+		assert.NoError(t, err)
+		assert.IsType(t, &SiteVerifier{}, v)
+	}
+
+	v, err := New(ProviderTurnstile, "", "secret", 0, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, TurnstileVerifyUrl, v.(*SiteVerifier).url)
+
+	v, err = New(ProviderFake, "", "token", 0, time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, v.Verify(context.Background(), "token", ""))
+	assert.Equal(t, ErrInvalidToken, v.Verify(context.Background(), "other", ""))
+
+	_, err = New("unknown", "", "secret", 0, time.Second)
+	assert.Equal(t, ErrUnknownProvider, err)
+}
+
+func TestGuard(t *testing.T) {
+	g := NewGuard(
+		Rule{Name: "ip", Limit: 3, Window: time.Minute},
+		Rule{Name: "order", Limit: 2, Window: time.Minute},
+		Rule{Name: "cookie", Limit: 0, Window: time.Minute},
+	)
+	keys := map[string]string{"ip": "127.0.0.1", "order": "order1", "cookie": "cookie1"}
+
+	assert.False(t, g.Required(keys))
+
+	g.Fail(keys)
+	assert.False(t, g.Required(keys))
+
+	g.Fail(keys)
+	assert.True(t, g.Required(keys))
+	assert.True(t, g.Required(map[string]string{"order": "order1"}))
+	assert.False(t, g.Required(map[string]string{"ip": "127.0.0.1", "order": "order2"}))
+
+	g.Fail(map[string]string{"ip": "127.0.0.1", "order": "order2"})
+	assert.True(t, g.Required(map[string]string{"ip": "127.0.0.1", "order": "order3"}))
+
+	g.Reset(keys)
+	assert.False(t, g.Required(keys))
+	assert.False(t, g.Required(map[string]string{"ip": "", "order": ""}))
+}
+
+func TestGuard_SetRules(t *testing.T) {
+	g := NewGuard(
+		Rule{Name: "ip", Limit: 2, Window: time.Minute},
+		Rule{Name: "order", Limit: 1, Window: time.Minute},
+	)
+	keys := map[string]string{"ip": "127.0.0.1", "order": "order1", "cookie": "cookie1"}
+
+	g.Fail(keys)
+	assert.True(t, g.Required(keys))
+
+	g.SetRules(
+		Rule{Name: "ip", Limit: 1, Window: time.Minute},
+		Rule{Name: "cookie", Limit: 2, Window: time.Minute},
+	)
+	assert.True(t, g.Required(map[string]string{"ip": "127.0.0.1"}))
+	assert.False(t, g.Required(map[string]string{"order": "order1", "cookie": "cookie1"}))
+
+	g.Fail(keys)
+	g.Fail(keys)
+	assert.True(t, g.Required(map[string]string{"cookie": "cookie1"}))
+}
@@ -0,0 +1,87 @@
+package captcha
+
+import (
+	"github.com/paysuper/paysuper-checkout/pkg/ratelimit"
+	"sync"
+	"time"
+)
+
+// Rule requires challenge when count of failures of the key reaches the limit inside the window, zero limit disables rule
+type Rule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Guard counts failures by the rules, keys are passed as map of the rule name to the key like ip address
+type Guard struct {
+	mx       sync.RWMutex
+	limiters map[string]*ratelimit.Limiter
+}
+
+// NewGuard
+func NewGuard(rules ...Rule) *Guard {
+	g := &Guard{limiters: make(map[string]*ratelimit.Limiter)}
+	g.SetRules(rules...)
+	return g
+}
+
+// SetRules replaces rules of the guard, failures counted by the rules which are kept aren't forgotten
+func (g *Guard) SetRules(rules ...Rule) {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	limiters := make(map[string]*ratelimit.Limiter)
+
+	for _, rule := range rules {
+		if rule.Limit <= 0 {
+			continue
+		}
+
+		if l, ok := g.limiters[rule.Name]; ok {
+			l.SetLimit(rule.Limit, rule.Window)
+			limiters[rule.Name] = l
+		} else {
+			limiters[rule.Name] = ratelimit.New(rule.Limit, rule.Window)
+		}
+	}
+
+	g.limiters = limiters
+}
+
+func (g *Guard) limiter(name string) (*ratelimit.Limiter, bool) {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	l, ok := g.limiters[name]
+	return l, ok
+}
+
+// Required reports whether any rule is triggered by the keys
+func (g *Guard) Required(keys map[string]string) bool {
+	for name, key := range keys {
+		if l, ok := g.limiter(name); ok && key != "" && l.Exceeded(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fail registers failure for all keys
+func (g *Guard) Fail(keys map[string]string) {
+	for name, key := range keys {
+		if l, ok := g.limiter(name); ok && key != "" {
+			l.Hit(key)
+		}
+	}
+}
+
+// Reset forgets failures of the keys after challenge is solved
+func (g *Guard) Reset(keys map[string]string) {
+	for name, key := range keys {
+		if l, ok := g.limiter(name); ok && key != "" {
+			l.Reset(key)
+		}
+	}
+}
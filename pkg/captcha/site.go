@@ -0,0 +1,90 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	siteVerifyResponseMaxSize = 1 << 16
+)
+
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// SiteVerifier uses siteverify API which is the same for hCaptcha, reCAPTCHA and Turnstile
+type SiteVerifier struct {
+	url      string
+	secret   string
+	minScore float64
+	http     *http.Client
+}
+
+// NewSiteVerifier returns verifier, minScore is checked only if provider returns score like reCAPTCHA v3
+func NewSiteVerifier(verifyUrl, secret string, minScore float64, timeout time.Duration) *SiteVerifier {
+	return &SiteVerifier{
+		url:      verifyUrl,
+		secret:   secret,
+		minScore: minScore,
+		http:     &http.Client{Timeout: timeout},
+	}
+}
+
+// Verify returns ErrInvalidToken if challenge isn't solved and ErrUnavailable if provider doesn't answer
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIp string) error {
+	if token == "" {
+		return ErrInvalidToken
+	}
+
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+
+	if remoteIp != "" {
+		form.Set("remoteip", remoteIp)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, v.url, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp, err := v.http.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return ErrUnavailable
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return ErrUnavailable
+	}
+
+	body := &siteVerifyResponse{}
+
+	if err := json.NewDecoder(io.LimitReader(rsp.Body, siteVerifyResponseMaxSize)).Decode(body); err != nil {
+		return ErrUnavailable
+	}
+
+	if !body.Success {
+		return ErrInvalidToken
+	}
+
+	if body.Score != nil && *body.Score < v.minScore {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
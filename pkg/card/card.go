@@ -0,0 +1,253 @@
+package card
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BrandUnknown    = ""
+	BrandVisa       = "visa"
+	BrandMastercard = "mastercard"
+	BrandAmex       = "amex"
+	BrandMaestro    = "maestro"
+	BrandMir        = "mir"
+	BrandJcb        = "jcb"
+	BrandUnionPay   = "unionpay"
+	BrandDiscover   = "discover"
+	BrandDiners     = "diners"
+
+	minLength = 12
+	maxLength = 19
+)
+
+type prefixRange struct {
+	from, to int
+	digits   int
+}
+
+type brand struct {
+	name     string
+	prefixes []prefixRange
+	lengths  []int
+	cvv      int
+	// luhn is false for brands which issue cards without check digit
+	luhn bool
+}
+
+// brands are ordered from the most specific prefixes to the most generic ones
+var brands = []*brand{
+	{
+		name:     BrandMir,
+		prefixes: []prefixRange{{2200, 2204, 4}},
+		lengths:  []int{16, 17, 18, 19},
+		cvv:      3,
+		luhn:     true,
+	},
+	{
+		name:     BrandMastercard,
+		prefixes: []prefixRange{{51, 55, 2}, {2221, 2720, 4}},
+		lengths:  []int{16},
+		cvv:      3,
+		luhn:     true,
+	},
+	{
+		name:     BrandVisa,
+		prefixes: []prefixRange{{4, 4, 1}},
+		lengths:  []int{13, 16, 19},
+		cvv:      3,
+		luhn:     true,
+	},
+	{
+		name:     BrandAmex,
+		prefixes: []prefixRange{{34, 34, 2}, {37, 37, 2}},
+		lengths:  []int{15},
+		cvv:      4,
+		luhn:     true,
+	},
+	{
+		name:     BrandDiners,
+		prefixes: []prefixRange{{300, 305, 3}, {36, 36, 2}, {38, 39, 2}},
+		lengths:  []int{14, 15, 16, 17, 18, 19},
+		cvv:      3,
+		luhn:     true,
+	},
+	{
+		name:     BrandDiscover,
+		prefixes: []prefixRange{{6011, 6011, 4}, {644, 649, 3}, {65, 65, 2}},
+		lengths:  []int{16, 17, 18, 19},
+		cvv:      3,
+		luhn:     true,
+	},
+	{
+		name:     BrandJcb,
+		prefixes: []prefixRange{{3528, 3589, 4}},
+		lengths:  []int{16, 17, 18, 19},
+		cvv:      3,
+		luhn:     true,
+	},
+	{
+		name:     BrandUnionPay,
+		prefixes: []prefixRange{{62, 62, 2}},
+		lengths:  []int{16, 17, 18, 19},
+		cvv:      3,
+		luhn:     false,
+	},
+	{
+		name:     BrandMaestro,
+		prefixes: []prefixRange{{50, 50, 2}, {56, 58, 2}, {6, 6, 1}},
+		lengths:  []int{12, 13, 14, 15, 16, 17, 18, 19},
+		cvv:      3,
+		luhn:     true,
+	},
+}
+
+// Normalize removes spaces and dashes which customers use to group card number digits
+func Normalize(pan string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(pan)
+}
+
+// Brand detects card brand by the card number prefix, BrandUnknown is returned when brand isn't recognized
+func Brand(pan string) string {
+	if b := detect(pan); b != nil {
+		return b.name
+	}
+
+	return BrandUnknown
+}
+
+// Luhn reports whether card number has valid check digit
+func Luhn(pan string) bool {
+	if pan == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+
+	for i := len(pan) - 1; i >= 0; i-- {
+		d := int(pan[i] - '0')
+
+		if d < 0 || d > 9 {
+			return false
+		}
+
+		if double {
+			d *= 2
+
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// ValidNumber reports whether card number consists of digits, has length allowed for its brand and valid check digit
+func ValidNumber(pan string) bool {
+	if !isDigits(pan) || len(pan) < minLength || len(pan) > maxLength {
+		return false
+	}
+
+	b := detect(pan)
+
+	if b == nil {
+		return Luhn(pan)
+	}
+
+	if !containsInt(b.lengths, len(pan)) {
+		return false
+	}
+
+	return !b.luhn || Luhn(pan)
+}
+
+// ValidCvv reports whether cvv has length required by the card brand, for unknown brand 3 or 4 digits are allowed
+func ValidCvv(brand, cvv string) bool {
+	if !isDigits(cvv) {
+		return false
+	}
+
+	for _, b := range brands {
+		if b.name == brand {
+			return len(cvv) == b.cvv
+		}
+	}
+
+	return len(cvv) == 3 || len(cvv) == 4
+}
+
+// ValidExpiry reports whether card isn't expired at the moment, year can contain 2 or 4 digits.
+// Card is valid until the end of the expiration month.
+func ValidExpiry(month, year string, now time.Time) bool {
+	m, err := strconv.Atoi(month)
+
+	if err != nil || m < 1 || m > 12 {
+		return false
+	}
+
+	if !isDigits(year) || (len(year) != 2 && len(year) != 4) {
+		return false
+	}
+
+	y, _ := strconv.Atoi(year)
+
+	if len(year) == 2 {
+		y += now.Year() / 100 * 100
+	}
+
+	expires := time.Date(y, time.Month(m)+1, 1, 0, 0, 0, 0, time.UTC)
+
+	return now.UTC().Before(expires)
+}
+
+func detect(pan string) *brand {
+	for _, b := range brands {
+		for _, p := range b.prefixes {
+			if len(pan) < p.digits {
+				continue
+			}
+
+			prefix, err := strconv.Atoi(pan[:p.digits])
+
+			if err != nil {
+				return nil
+			}
+
+			if prefix >= p.from && prefix <= p.to {
+				return b
+			}
+		}
+	}
+
+	return nil
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package card
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBrand(t *testing.T) {
+	cases := map[string]string{
+		"4111111111111111":    BrandVisa,
+		"5555555555554444":    BrandMastercard,
+		"2223003122003222":    BrandMastercard,
+		"378282246310005":     BrandAmex,
+		"2200000000000004":    BrandMir,
+		"3530111333300000":    BrandJcb,
+		"6011111111111117":    BrandDiscover,
+		"30569309025904":      BrandDiners,
+		"6200000000000005":    BrandUnionPay,
+		"6759649826438453":    BrandMaestro,
+		"9999999999999995":    BrandUnknown,
+		"":                    BrandUnknown,
+		"4111 1111 1111 1111": BrandVisa,
+	}
+
+	for pan, brand := range cases {
+		assert.Equal(t, brand, Brand(pan), pan)
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	assert.True(t, Luhn("4111111111111111"))
+	assert.True(t, Luhn("378282246310005"))
+	assert.False(t, Luhn("4111111111111112"))
+	assert.False(t, Luhn("41111111111a1111"))
+	assert.False(t, Luhn(""))
+}
+
+func TestValidNumber(t *testing.T) {
+	assert.True(t, ValidNumber("4111111111111111"))
+	assert.True(t, ValidNumber("378282246310005"))
+	assert.True(t, ValidNumber("6200000000000001"))
+	assert.False(t, ValidNumber("4111111111111112"))
+	assert.False(t, ValidNumber("37828224631000"))
+	assert.False(t, ValidNumber("55555555555544440"))
+	assert.False(t, ValidNumber("4111 1111 1111 1111"))
+	assert.False(t, ValidNumber("41111111"))
+	assert.True(t, ValidNumber(Normalize("4111 1111-1111 1111")))
+}
+
+func TestValidCvv(t *testing.T) {
+	assert.True(t, ValidCvv(BrandVisa, "123"))
+	assert.False(t, ValidCvv(BrandVisa, "1234"))
+	assert.True(t, ValidCvv(BrandAmex, "1234"))
+	assert.False(t, ValidCvv(BrandAmex, "123"))
+	assert.True(t, ValidCvv(BrandUnknown, "1234"))
+	assert.False(t, ValidCvv(BrandUnknown, "12"))
+	assert.False(t, ValidCvv(BrandVisa, "12a"))
+}
+
+func TestValidExpiry(t *testing.T) {
+	now := time.Date(2020, time.March, 31, 23, 0, 0, 0, time.UTC)
+
+	assert.True(t, ValidExpiry("3", "2020", now))
+	assert.True(t, ValidExpiry("03", "20", now))
+	assert.True(t, ValidExpiry("12", "2030", now))
+	assert.False(t, ValidExpiry("2", "2020", now))
+	assert.False(t, ValidExpiry("12", "19", now))
+	assert.False(t, ValidExpiry("13", "2021", now))
+	assert.False(t, ValidExpiry("0", "2021", now))
+	assert.False(t, ValidExpiry("1", "202", now))
+	assert.False(t, ValidExpiry("1", "", now))
+	assert.False(t, ValidExpiry("", "2021", now))
+}
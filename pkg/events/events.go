@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"github.com/paysuper/paysuper-checkout/pkg/batch"
+	"time"
+)
+
+// Event is a client event of the payment form enriched on the server side
+type Event struct {
+	Type       string            `json:"type"`
+	OrderId    string            `json:"order_id"`
+	Time       int64             `json:"time"`
+	ReceivedAt int64             `json:"received_at"`
+	IpCountry  string            `json:"ip_country,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+// Sink writes batch of events to the storage
+type Sink interface {
+	Write(ctx context.Context, events []*Event) error
+}
+
+// Options
+type Options struct {
+	// Interval between flushes
+	Interval time.Duration
+	// MaxPending is a max count of events waiting for flush, new events are dropped when it's reached
+	MaxPending int
+}
+
+// Buffer accumulates events in memory and writes them to the sink in batches
+type Buffer struct {
+	opts    Options
+	pending *pendingEvents
+	batcher *batch.Batcher
+}
+
+// pendingEvents are guarded by the batcher
+type pendingEvents struct {
+	max    int
+	events []*Event
+}
+
+// Take
+func (p *pendingEvents) Take() interface{} {
+	if len(p.events) == 0 {
+		return nil
+	}
+
+	events := p.events
+	p.events = nil
+	return events
+}
+
+// Restore puts events which failed to flush before the events added during the flush
+func (p *pendingEvents) Restore(b interface{}) int {
+	events := b.([]*Event)
+	free := len(events)
+
+	if p.max > 0 && len(p.events)+free > p.max {
+		free = p.max - len(p.events)
+	}
+
+	p.events = append(events[:free:free], p.events...)
+	return len(events) - free
+}
+
+// NewBuffer returns buffer, Run must be called to start periodic flushes
+func NewBuffer(sink Sink, opts Options) *Buffer {
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+
+	pending := &pendingEvents{max: opts.MaxPending}
+	flush := func(ctx context.Context, b interface{}) error {
+		return sink.Write(ctx, b.([]*Event))
+	}
+
+	return &Buffer{
+		opts:    opts,
+		pending: pending,
+		batcher: batch.New(pending, flush, opts.Interval),
+	}
+}
+
+// Add puts events to the buffer and returns count of accepted events, the rest are dropped because buffer is full
+func (b *Buffer) Add(events ...*Event) int {
+	accepted := len(events)
+
+	b.batcher.Do(func() int {
+		if b.opts.MaxPending > 0 && len(b.pending.events)+accepted > b.opts.MaxPending {
+			accepted = b.opts.MaxPending - len(b.pending.events)
+		}
+
+		b.pending.events = append(b.pending.events, events[:accepted]...)
+		return len(events) - accepted
+	})
+
+	return accepted
+}
+
+// Pending returns count of events waiting for flush
+func (b *Buffer) Pending() int {
+	pending := 0
+
+	b.batcher.Do(func() int {
+		pending = len(b.pending.events)
+		return 0
+	})
+
+	return pending
+}
+
+// Dropped returns count of events dropped because of MaxPending limit
+func (b *Buffer) Dropped() int {
+	return b.batcher.Dropped()
+}
+
+// Flush writes pending events, events are returned back to the buffer if writing fails and there is a free space
+func (b *Buffer) Flush(ctx context.Context) error {
+	return b.batcher.Flush(ctx)
+}
+
+// Run flushes events periodically until Close is called or context is cancelled, errors are passed to onError
+func (b *Buffer) Run(ctx context.Context, onError func(err error)) {
+	b.batcher.Run(ctx, onError)
+}
+
+// Close stops periodic flushes and writes remaining events
+func (b *Buffer) Close(ctx context.Context) error {
+	return b.batcher.Close(ctx)
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSink struct {
+	mx      sync.Mutex
+	err     error
+	batches [][]*Event
+}
+
+func (s *testSink) Write(_ context.Context, events []*Event) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.batches = append(s.batches, events)
+	return nil
+}
+
+func (s *testSink) count() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	count := 0
+
+	for _, batch := range s.batches {
+		count += len(batch)
+	}
+
+	return count
+}
+
+func TestBuffer_Flush(t *testing.T) {
+	sink := &testSink{}
+	b := NewBuffer(sink, Options{MaxPending: 3})
+
+	assert.Equal(t, 2, b.Add(&Event{Type: "form_opened"}, &Event{Type: "method_selected"}))
+	assert.Equal(t, 1, b.Add(&Event{Type: "country_changed"}, &Event{Type: "payment_submitted"}))
+	assert.Equal(t, 3, b.Pending())
+	assert.Equal(t, 1, b.Dropped())
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, 0, b.Pending())
+	assert.Len(t, sink.batches, 1)
+	assert.Equal(t, "country_changed", sink.batches[0][2].Type)
+
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Len(t, sink.batches, 1)
+}
+
+func TestBuffer_FlushError(t *testing.T) {
+	sink := &testSink{err: errors.New("sink error")}
+	b := NewBuffer(sink, Options{MaxPending: 3})
+
+	b.Add(&Event{Type: "form_opened"}, &Event{Type: "method_selected"})
+	assert.Error(t, b.Flush(context.Background()))
+	assert.Equal(t, 2, b.Pending())
+
+	b.Add(&Event{Type: "error_shown"})
+	sink.err = nil
+	assert.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, []string{"form_opened", "method_selected", "error_shown"}, []string{
+		sink.batches[0][0].Type, sink.batches[0][1].Type, sink.batches[0][2].Type,
+	})
+}
+
+func TestBuffer_RunAndClose(t *testing.T) {
+	sink := &testSink{}
+	b := NewBuffer(sink, Options{Interval: 10 * time.Millisecond})
+
+	go b.Run(context.Background(), nil)
+
+	b.Add(&Event{Type: "form_opened"})
+
+	waitFor(func() bool { return sink.count() == 1 })
+	assert.Equal(t, 1, sink.count())
+
+	b.Add(&Event{Type: "payment_submitted"})
+	assert.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, 2, sink.count())
+}
+
+// waitFor polls condition up to a second
+func waitFor(condition func() bool) {
+	for i := 0; i < 200 && !condition(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/micro/go-micro/broker"
+	"os"
+	"sync"
+)
+
+const (
+	SinkLog    = "log"
+	SinkFile   = "file"
+	SinkBroker = "broker"
+)
+
+// LogSink writes events to the structured log
+type LogSink struct {
+	log logger.Logger
+}
+
+// NewLogSink
+func NewLogSink(log logger.Logger) *LogSink {
+	return &LogSink{log: log}
+}
+
+// Write
+func (s *LogSink) Write(_ context.Context, events []*Event) error {
+	for _, e := range events {
+		s.log.Info("payment form event", logger.WithFields(logger.Fields{"event": e}))
+	}
+
+	return nil
+}
+
+// FileSink appends events to the file as JSON lines
+type FileSink struct {
+	mx   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileSink opens file for appending, the file is created if it doesn't exist
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{path: path, file: file}, nil
+}
+
+// Write writes batch by the single call to keep lines of concurrent writers whole
+func (s *FileSink) Write(_ context.Context, events []*Event) error {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	_, err := s.file.Write(buf.Bytes())
+	return err
+}
+
+// Reopen opens file again after it's moved by the log rotation
+func (s *FileSink) Reopen() error {
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	old := s.file
+	s.file = file
+	s.mx.Unlock()
+
+	return old.Close()
+}
+
+// Close
+func (s *FileSink) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.file.Close()
+}
+
+// BrokerSink publishes batch of events as JSON array to the message broker topic
+type BrokerSink struct {
+	broker broker.Broker
+	topic  string
+}
+
+// NewBrokerSink
+func NewBrokerSink(b broker.Broker, topic string) *BrokerSink {
+	return &BrokerSink{broker: b, topic: topic}
+}
+
+// Write
+func (s *BrokerSink) Write(_ context.Context, events []*Event) error {
+	body, err := json.Marshal(events)
+
+	if err != nil {
+		return err
+	}
+
+	msg := &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   body,
+	}
+
+	return s.broker.Publish(s.topic, msg)
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/broker/memory"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFileSink_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := dir + "/events.log"
+	sink, err := NewFileSink(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, sink.Write(context.Background(), []*Event{{Type: "form_opened", OrderId: "order"}}))
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, sink.Reopen())
+	assert.NoError(t, sink.Write(context.Background(), []*Event{{Type: "error_shown"}, {Type: "payment_submitted"}}))
+	assert.NoError(t, sink.Close())
+
+	rotated, err := ioutil.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"form_opened","order_id":"order","time":0,"received_at":0}`, string(rotated))
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		e := &Event{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), e))
+		types = append(types, e.Type)
+	}
+
+	assert.Equal(t, []string{"error_shown", "payment_submitted"}, types)
+}
+
+func TestBrokerSink_Write(t *testing.T) {
+	b := memory.NewBroker()
+	assert.NoError(t, b.Connect())
+	defer b.Disconnect()
+
+	received := make(chan *broker.Message, 1)
+	_, err := b.Subscribe("checkout.events", func(p broker.Publication) error {
+		received <- p.Message()
+		return nil
+	})
+	assert.NoError(t, err)
+
+	sink := NewBrokerSink(b, "checkout.events")
+	assert.NoError(t, sink.Write(context.Background(), []*Event{{Type: "form_opened"}, {Type: "method_selected"}}))
+
+	msg := <-received
+	var events []*Event
+	assert.NoError(t, json.Unmarshal(msg.Body, &events))
+	assert.Len(t, events, 2)
+	assert.Equal(t, "application/json", msg.Header["Content-Type"])
+}
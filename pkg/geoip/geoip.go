@@ -0,0 +1,87 @@
+package geoip
+
+import (
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Resolver returns ISO country code of the ip address, empty string is returned if the country is unknown
+type Resolver interface {
+	Country(ip net.IP) string
+}
+
+type record struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Database resolves countries by the MaxMind format database file like GeoLite2-Country or GeoIP2-City
+type Database struct {
+	path   string
+	mx     sync.RWMutex
+	reader *maxminddb.Reader
+}
+
+// Open opens database file, the file is memory mapped and must not be changed in place, replace it and call Reload
+func Open(path string) (*Database, error) {
+	reader, err := maxminddb.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{path: path, reader: reader}, nil
+}
+
+// Reload opens database file again, previous database is kept if the new one can't be opened
+func (d *Database) Reload() error {
+	reader, err := maxminddb.Open(d.path)
+
+	if err != nil {
+		return err
+	}
+
+	d.mx.Lock()
+	old := d.reader
+	d.reader = reader
+	d.mx.Unlock()
+
+	return old.Close()
+}
+
+// Country returns country of the ip address, registered country is used for the addresses without location like anycast
+func (d *Database) Country(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+
+	rec := &record{}
+
+	d.mx.RLock()
+	err := d.reader.Lookup(ip, rec)
+	d.mx.RUnlock()
+
+	if err != nil {
+		return ""
+	}
+
+	if rec.Country.IsoCode != "" {
+		return strings.ToUpper(rec.Country.IsoCode)
+	}
+
+	return strings.ToUpper(rec.RegisteredCountry.IsoCode)
+}
+
+// Close releases database file
+func (d *Database) Close() error {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	return d.reader.Close()
+}
@@ -0,0 +1,160 @@
+package geoip
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+)
+
+type testNetwork struct {
+	cidr    string
+	field   string
+	country string
+}
+
+type testRecord struct {
+	kind  int
+	value int
+}
+
+const (
+	recordEmpty = iota
+	recordNode
+	recordData
+)
+
+// writeDatabase writes IPv4 database in the MaxMind DB format with 24 bit records
+func writeDatabase(t *testing.T, networks []testNetwork) string {
+	nodes := [][2]testRecord{{}}
+	data := &bytes.Buffer{}
+
+	for _, n := range networks {
+		_, ipNet, err := net.ParseCIDR(n.cidr)
+		assert.NoError(t, err)
+
+		offset := data.Len()
+		writeMapHeader(data, 1)
+		writeString(data, n.field)
+		writeMapHeader(data, 1)
+		writeString(data, "iso_code")
+		writeString(data, n.country)
+
+		ones, _ := ipNet.Mask.Size()
+		ip := ipNet.IP.To4()
+		node := 0
+
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> uint(7-i%8)) & 1
+
+			if i == ones-1 {
+				nodes[node][bit] = testRecord{kind: recordData, value: offset}
+				break
+			}
+
+			if nodes[node][bit].kind != recordNode {
+				nodes = append(nodes, [2]testRecord{})
+				nodes[node][bit] = testRecord{kind: recordNode, value: len(nodes) - 1}
+			}
+
+			node = nodes[node][bit].value
+		}
+	}
+
+	db := &bytes.Buffer{}
+	count := len(nodes)
+
+	for _, node := range nodes {
+		for _, r := range node {
+			v := count
+
+			switch r.kind {
+			case recordNode:
+				v = r.value
+			case recordData:
+				v = count + 16 + r.value
+			}
+
+			db.Write([]byte{byte(v >> 16), byte(v >> 8), byte(v)})
+		}
+	}
+
+	db.Write(make([]byte, 16))
+	db.Write(data.Bytes())
+	db.WriteString("\xAB\xCD\xEFMaxMind.com")
+
+	writeMapHeader(db, 5)
+	writeString(db, "node_count")
+	db.Write([]byte{0xC4, byte(count >> 24), byte(count >> 16), byte(count >> 8), byte(count)})
+	writeString(db, "record_size")
+	db.Write([]byte{0xA2, 0, 24})
+	writeString(db, "ip_version")
+	db.Write([]byte{0xA2, 0, 4})
+	writeString(db, "binary_format_major_version")
+	db.Write([]byte{0xA2, 0, 2})
+	writeString(db, "database_type")
+	writeString(db, "Test-Country")
+
+	file, err := ioutil.TempFile("", "geoip*.mmdb")
+	assert.NoError(t, err)
+
+	_, err = file.Write(db.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	return file.Name()
+}
+
+func writeMapHeader(b *bytes.Buffer, size int) {
+	b.WriteByte(0xE0 | byte(size))
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	b.WriteByte(0x40 | byte(len(s)))
+	b.WriteString(s)
+}
+
+func TestDatabase_Country(t *testing.T) {
+	path := writeDatabase(t, []testNetwork{
+		{cidr: "81.2.69.0/24", field: "country", country: "GB"},
+		{cidr: "89.160.20.0/22", field: "country", country: "se"},
+		{cidr: "1.1.1.0/24", field: "registered_country", country: "AU"},
+	})
+	defer os.Remove(path)
+
+	db, err := Open(path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, "GB", db.Country(net.ParseIP("81.2.69.160")))
+	assert.Equal(t, "SE", db.Country(net.ParseIP("89.160.23.1")))
+	assert.Equal(t, "AU", db.Country(net.ParseIP("1.1.1.1")))
+	assert.Equal(t, "", db.Country(net.ParseIP("10.0.0.1")))
+	assert.Equal(t, "", db.Country(nil))
+}
+
+func TestDatabase_Reload(t *testing.T) {
+	path := writeDatabase(t, []testNetwork{{cidr: "81.2.69.0/24", field: "country", country: "GB"}})
+	defer os.Remove(path)
+
+	db, err := Open(path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	updated := writeDatabase(t, []testNetwork{{cidr: "81.2.69.0/24", field: "country", country: "IE"}})
+	assert.NoError(t, os.Rename(updated, path))
+	assert.NoError(t, db.Reload())
+	assert.Equal(t, "IE", db.Country(net.ParseIP("81.2.69.1")))
+
+	assert.NoError(t, ioutil.WriteFile(path+".new", []byte("broken"), 0644))
+	assert.NoError(t, os.Rename(path+".new", path))
+	assert.Error(t, db.Reload())
+	assert.Equal(t, "IE", db.Country(net.ParseIP("81.2.69.1")))
+}
+
+func TestOpen_Error(t *testing.T) {
+	_, err := Open("/not/existing/file.mmdb")
+	assert.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package http
 
-import "github.com/labstack/echo/v4"
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+)
 
 const (
 	Prefix           = "internal.http"
@@ -12,3 +15,13 @@ const (
 type Dispatcher interface {
 	Dispatch(http *echo.Echo) error
 }
+
+// Starter is implemented by dispatchers which run background work while the server is running
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Shutdowner is implemented by dispatchers which must finish background work after the server is stopped
+type Shutdowner interface {
+	Shutdown(ctx context.Context)
+}
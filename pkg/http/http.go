@@ -29,19 +29,32 @@ func (h *HTTP) ListenAndServe() (err error) {
 		return err
 	}
 
+	if s, ok := h.dispatcher.(Starter); ok {
+		if err := s.Start(h.ctx); err != nil {
+			return err
+		}
+	}
+
 	h.L().Info("start listen and serve http at %v", logger.Args(h.cfg.Bind))
 
+	shutdown := make(chan struct{})
+
 	go func() {
+		defer close(shutdown)
 		<-h.ctx.Done()
 		h.L().Info("context cancelled, shutdown is raised")
 		if e := server.Shutdown(context.Background()); e != nil {
 			h.L().Error("graceful shutdown error, %v", logger.Args(e))
 		}
+		if s, ok := h.dispatcher.(Shutdowner); ok {
+			s.Shutdown(context.Background())
+		}
 	}()
 
 	if err = server.Start(h.cfg.Bind); err != nil {
 		if err == http.ErrServerClosed {
 			err = nil
+			<-shutdown
 		} else {
 			return err
 		}
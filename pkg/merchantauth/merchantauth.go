@@ -0,0 +1,146 @@
+package merchantauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/paysuper/paysuper-checkout/pkg/ttlcache"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("api key not found")
+	ErrInvalidSecret    = errors.New("api key secret is invalid")
+	ErrInvalidSignature = errors.New("request signature is invalid")
+	ErrExpired          = errors.New("request timestamp is out of the allowed window")
+)
+
+// Key is an api key of the merchant backend, requests are authenticated by the key secret or signed with it
+type Key struct {
+	Id         string
+	MerchantId string
+	ProjectId  string
+	Secret     string
+	// RateLimit is a max count of requests with the key inside a minute, zero means default limit
+	RateLimit int
+}
+
+// CheckSecret compares secret sent with the request to the key secret in constant time
+func (k *Key) CheckSecret(secret string) error {
+	if k.Secret == "" || subtle.ConstantTimeCompare([]byte(k.Secret), []byte(secret)) != 1 {
+		return ErrInvalidSecret
+	}
+	return nil
+}
+
+// Verify checks signature of the request signed at timestamp (unix seconds).
+// Requests signed earlier or later than skew from now are rejected to prevent replays of intercepted requests.
+func (k *Key) Verify(signature string, timestamp int64, method, uri string, body []byte, now time.Time, skew time.Duration) error {
+	signedAt := time.Unix(timestamp, 0)
+
+	if signedAt.Before(now.Add(-skew)) || signedAt.After(now.Add(skew)) {
+		return ErrExpired
+	}
+
+	actual, err := hex.DecodeString(signature)
+
+	if k.Secret == "" || err != nil || !hmac.Equal(sign(k.Secret, timestamp, method, uri, body), actual) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Sign returns hex encoded HMAC-SHA256 of the request timestamp, method, uri with query and body separated by new lines
+func Sign(secret string, timestamp int64, method, uri string, body []byte) string {
+	return hex.EncodeToString(sign(secret, timestamp, method, uri, body))
+}
+
+func sign(secret string, timestamp int64, method, uri string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n" + method + "\n" + uri + "\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Source returns api key by its id, ErrNotFound is returned if the key is unknown or revoked
+type Source interface {
+	Lookup(ctx context.Context, id string) (*Key, error)
+}
+
+// SourceFunc adapts function to the Source interface
+type SourceFunc func(ctx context.Context, id string) (*Key, error)
+
+// Lookup
+func (f SourceFunc) Lookup(ctx context.Context, id string) (*Key, error) {
+	return f(ctx, id)
+}
+
+// Cache keeps lookup results of the source including unknown keys, failed lookups aren't cached.
+// Revoked keys are accepted until they expire in the cache.
+type Cache struct {
+	source Source
+	items  *ttlcache.Cache
+	now    func() time.Time
+}
+
+// NewCache returns cache of the source limited by size, items live for ttl
+func NewCache(source Source, size int, ttl time.Duration) *Cache {
+	c := &Cache{source: source, now: time.Now}
+	c.items = ttlcache.NewWithClock(size, ttl, func() time.Time { return c.now() })
+	return c
+}
+
+// Lookup
+func (c *Cache) Lookup(ctx context.Context, id string) (*Key, error) {
+	if item, ok := c.items.Get(id); ok {
+		if item == nil {
+			return nil, ErrNotFound
+		}
+		return item.(*Key), nil
+	}
+
+	key, err := c.source.Lookup(ctx, id)
+
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	if key == nil {
+		c.items.Set(id, nil)
+		return nil, err
+	}
+
+	c.items.Set(id, key)
+	return key, err
+}
+
+// Purge removes all cached keys
+func (c *Cache) Purge() {
+	c.items.Purge()
+}
+
+// Replays remembers signatures of the accepted requests while their timestamps are inside the allowed window,
+// so intercepted requests can't be sent again. The oldest signatures are dropped when it's full.
+type Replays struct {
+	items *ttlcache.Cache
+	now   func() time.Time
+}
+
+// NewReplays returns replays which keep size signatures at most
+func NewReplays(size int) *Replays {
+	r := &Replays{now: time.Now}
+	r.items = ttlcache.NewWithClock(size, 0, func() time.Time { return r.now() })
+	return r
+}
+
+// Seen registers signature of the request signed inside skew and reports whether it was registered before.
+// Signature is kept for double skew because the request signed at the end of the window can be sent until its end.
+func (r *Replays) Seen(signature string, skew time.Duration) bool {
+	r.items.SetTtl(2 * skew)
+	return !r.items.Add(signature, nil)
+}
@@ -0,0 +1,154 @@
+package merchantauth
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type sourceStub struct {
+	keys  map[string]*Key
+	err   error
+	calls int
+}
+
+func (s *sourceStub) Lookup(_ context.Context, id string) (*Key, error) {
+	s.calls++
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	key, ok := s.keys[id]
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return key, nil
+}
+
+func newSourceStub() *sourceStub {
+	return &sourceStub{
+		keys: map[string]*Key{
+			"key": {Id: "key", ProjectId: "project", Secret: "secret"},
+		},
+	}
+}
+
+func TestKey_CheckSecret(t *testing.T) {
+	key := &Key{Id: "key", Secret: "secret"}
+
+	assert.NoError(t, key.CheckSecret("secret"))
+	assert.Equal(t, ErrInvalidSecret, key.CheckSecret("secret2"))
+	assert.Equal(t, ErrInvalidSecret, key.CheckSecret(""))
+	assert.Equal(t, ErrInvalidSecret, (&Key{Id: "key"}).CheckSecret(""))
+}
+
+func TestKey_Verify(t *testing.T) {
+	now := time.Unix(1577836800, 0)
+	key := &Key{Id: "key", Secret: "secret"}
+	body := []byte(`{"project":"project"}`)
+	signature := Sign(key.Secret, now.Unix(), http.MethodPost, "/api/v2/merchant/orders", body)
+
+	assert.NoError(t, key.Verify(signature, now.Unix(), http.MethodPost, "/api/v2/merchant/orders", body, now, time.Minute))
+	assert.NoError(t, key.Verify(signature, now.Unix(), http.MethodPost, "/api/v2/merchant/orders", body, now.Add(time.Minute), time.Minute))
+
+	err := key.Verify(signature, now.Unix(), http.MethodPost, "/api/v2/merchant/orders", body, now.Add(time.Minute+time.Second), time.Minute)
+	assert.Equal(t, ErrExpired, err)
+
+	err = key.Verify(signature, now.Unix(), http.MethodPost, "/api/v2/merchant/orders", body, now.Add(-time.Minute-time.Second), time.Minute)
+	assert.Equal(t, ErrExpired, err)
+
+	err = key.Verify(signature, now.Unix(), http.MethodPost, "/api/v2/merchant/orders", []byte(`{}`), now, time.Minute)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	err = key.Verify(signature, now.Unix(), http.MethodGet, "/api/v2/merchant/orders", body, now, time.Minute)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	err = key.Verify("not-a-hex", now.Unix(), http.MethodPost, "/api/v2/merchant/orders", body, now, time.Minute)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	err = (&Key{Id: "key"}).Verify(Sign("", now.Unix(), http.MethodPost, "/", nil), now.Unix(), http.MethodPost, "/", nil, now, time.Minute)
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+func TestReplays_Seen(t *testing.T) {
+	now := time.Now()
+	r := NewReplays(2)
+	r.now = func() time.Time { return now }
+
+	assert.False(t, r.Seen("first", time.Minute))
+	assert.True(t, r.Seen("first", time.Minute))
+	assert.False(t, r.Seen("second", time.Minute))
+
+	// signature is kept until the request signed at the end of the window expires
+	now = now.Add(2*time.Minute - time.Second)
+	assert.True(t, r.Seen("first", time.Minute))
+
+	now = now.Add(time.Second)
+	assert.False(t, r.Seen("first", time.Minute))
+
+	// the oldest signature is dropped when replays are full
+	assert.False(t, r.Seen("third", time.Minute))
+	assert.False(t, r.Seen("fourth", time.Minute))
+	assert.Equal(t, 2, r.items.Len())
+	assert.True(t, r.Seen("third", time.Minute))
+	assert.False(t, r.Seen("first", time.Minute))
+}
+
+func TestCache_Lookup(t *testing.T) {
+	source := newSourceStub()
+	c := NewCache(source, 10, time.Minute)
+
+	key, err := c.Lookup(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "project", key.ProjectId)
+
+	_, err = c.Lookup(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.calls)
+
+	_, err = c.Lookup(context.Background(), "unknown")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = c.Lookup(context.Background(), "unknown")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, 2, source.calls)
+
+	c.Purge()
+	_, err = c.Lookup(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, source.calls)
+}
+
+func TestCache_ErrorNotCached(t *testing.T) {
+	source := newSourceStub()
+	source.err = errors.New("billing unavailable")
+	c := NewCache(source, 10, time.Minute)
+
+	_, err := c.Lookup(context.Background(), "key")
+	assert.Equal(t, source.err, err)
+
+	source.err = nil
+	key, err := c.Lookup(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.NotNil(t, key)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCache_Expiration(t *testing.T) {
+	now := time.Now()
+	source := newSourceStub()
+	c := NewCache(source, 1, time.Minute)
+	c.now = func() time.Time { return now }
+
+	_, _ = c.Lookup(context.Background(), "key")
+	now = now.Add(time.Minute)
+	_, _ = c.Lookup(context.Background(), "key")
+
+	assert.Equal(t, 2, source.calls)
+	assert.Equal(t, 1, c.items.Len())
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/micro/go-micro"
+	"github.com/micro/go-micro/broker"
 	"github.com/micro/go-micro/client"
 	mlog "github.com/micro/go-micro/util/log"
 	"github.com/micro/go-plugins/client/selector/static"
@@ -24,6 +25,16 @@ func (m *Micro) Client() client.Client {
 	return m.srv.Client()
 }
 
+// Broker returns message broker of the service
+func (m *Micro) Broker() broker.Broker {
+	return m.srv.Options().Broker
+}
+
+// Handle registers handler of the service requests, it must be called before ListenAndServe
+func (m *Micro) Handle(h interface{}) error {
+	return micro.RegisterHandler(m.srv.Server(), h)
+}
+
 // Init
 func (m *Micro) Init() {
 	m.srv.Init()
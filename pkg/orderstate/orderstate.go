@@ -0,0 +1,125 @@
+package orderstate
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// State is a last known status of the order
+type State struct {
+	OrderId   string `json:"order_id"`
+	Status    string `json:"status"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type entry struct {
+	state   State
+	expires time.Time
+	element *list.Element
+}
+
+// Store keeps order states in memory for a short time, states are overwritten by newer ones only.
+// States share ttl, so the list ordered by the last update is ordered by expiration too.
+type Store struct {
+	mx      sync.RWMutex
+	ttl     time.Duration
+	size    int
+	entries map[string]*entry
+	order   *list.List
+	now     func() time.Time
+}
+
+// NewStore returns store of at most size orders, zero size means no limit
+func NewStore(ttl time.Duration, size int) *Store {
+	return &Store{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*entry),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Set saves state of the order, false is returned if the stored state is newer
+func (s *Store) Set(state State) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	now := s.now()
+	e, ok := s.entries[state.OrderId]
+
+	if ok && now.Before(e.expires) && e.state.UpdatedAt > state.UpdatedAt {
+		return false
+	}
+
+	if ok {
+		e.state = state
+		e.expires = now.Add(s.ttl)
+		s.order.MoveToBack(e.element)
+		return true
+	}
+
+	s.evict(now)
+
+	e = &entry{state: state, expires: now.Add(s.ttl)}
+	e.element = s.order.PushBack(state.OrderId)
+	s.entries[state.OrderId] = e
+
+	return true
+}
+
+// Get returns state of the order if it wasn't expired
+func (s *Store) Get(orderId string) (State, bool) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	e, ok := s.entries[orderId]
+
+	if !ok || !s.now().Before(e.expires) {
+		return State{}, false
+	}
+
+	return e.state, true
+}
+
+// Delete removes state of the order
+func (s *Store) Delete(orderId string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if e, ok := s.entries[orderId]; ok {
+		s.order.Remove(e.element)
+		delete(s.entries, orderId)
+	}
+}
+
+// Purge removes all states
+func (s *Store) Purge() {
+	s.mx.Lock()
+	s.entries = make(map[string]*entry)
+	s.order.Init()
+	s.mx.Unlock()
+}
+
+// Len returns count of stored states including expired ones which weren't evicted yet
+func (s *Store) Len() int {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return len(s.entries)
+}
+
+// evict removes expired states from the front of the list, the state expiring first is removed
+// if there are no expired ones and the store is full
+func (s *Store) evict(now time.Time) {
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		orderId := front.Value.(string)
+
+		if now.Before(s.entries[orderId].expires) && (s.size <= 0 || len(s.entries) < s.size) {
+			return
+		}
+
+		s.order.Remove(front)
+		delete(s.entries, orderId)
+	}
+}
@@ -0,0 +1,91 @@
+package orderstate
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStore_SetAndGet(t *testing.T) {
+	s := NewStore(time.Minute, 0)
+
+	_, ok := s.Get("order")
+	assert.False(t, ok)
+
+	assert.True(t, s.Set(State{OrderId: "order", Status: "created", UpdatedAt: 1}))
+	assert.True(t, s.Set(State{OrderId: "order", Status: "processed", UpdatedAt: 3}))
+	assert.False(t, s.Set(State{OrderId: "order", Status: "created", UpdatedAt: 2}))
+
+	state, ok := s.Get("order")
+	assert.True(t, ok)
+	assert.Equal(t, "processed", state.Status)
+
+	s.Delete("order")
+	_, ok = s.Get("order")
+	assert.False(t, ok)
+
+	s.Set(State{OrderId: "order", Status: "created"})
+	s.Purge()
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestStore_Expiration(t *testing.T) {
+	now := time.Now()
+	s := NewStore(time.Minute, 0)
+	s.now = func() time.Time { return now }
+
+	s.Set(State{OrderId: "order", Status: "processed", UpdatedAt: 3})
+	now = now.Add(time.Minute)
+
+	_, ok := s.Get("order")
+	assert.False(t, ok)
+
+	// expired state doesn't block older notifications
+	assert.True(t, s.Set(State{OrderId: "order", Status: "created", UpdatedAt: 1}))
+}
+
+func TestStore_Size(t *testing.T) {
+	now := time.Now()
+	s := NewStore(time.Minute, 2)
+	s.now = func() time.Time { return now }
+
+	s.Set(State{OrderId: "first", Status: "created"})
+	now = now.Add(time.Second)
+	s.Set(State{OrderId: "second", Status: "created"})
+	s.Set(State{OrderId: "third", Status: "created"})
+
+	assert.Equal(t, 2, s.Len())
+
+	_, ok := s.Get("first")
+	assert.False(t, ok)
+	_, ok = s.Get("third")
+	assert.True(t, ok)
+
+	// updates of the stored orders don't evict others
+	s.Set(State{OrderId: "third", Status: "processed", UpdatedAt: 1})
+	_, ok = s.Get("second")
+	assert.True(t, ok)
+}
+
+func TestStore_EvictExpired(t *testing.T) {
+	now := time.Now()
+	s := NewStore(time.Minute, 0)
+	s.now = func() time.Time { return now }
+
+	s.Set(State{OrderId: "first", Status: "created"})
+	s.Set(State{OrderId: "second", Status: "created"})
+	now = now.Add(30 * time.Second)
+	// update moves the state to the end of the expiration order
+	s.Set(State{OrderId: "first", Status: "processed", UpdatedAt: 1})
+	now = now.Add(30 * time.Second)
+	s.Set(State{OrderId: "third", Status: "created"})
+
+	assert.Equal(t, 2, s.Len())
+	_, ok := s.Get("first")
+	assert.True(t, ok)
+
+	s.Delete("first")
+	now = now.Add(time.Minute)
+	s.Set(State{OrderId: "fourth", Status: "created"})
+	assert.Equal(t, 1, s.Len())
+}
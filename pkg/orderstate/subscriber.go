@@ -0,0 +1,32 @@
+package orderstate
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/micro/go-micro/broker"
+)
+
+var ErrIncorrectNotification = errors.New("incorrect order status notification")
+
+// Subscribe connects to the broker and saves order status notifications published to the topic,
+// subscription has no queue so every checkout instance receives all notifications.
+// Incorrect notifications are passed to onError and acknowledged to avoid redelivery.
+func Subscribe(b broker.Broker, topic string, store *Store, onError func(err error)) (broker.Subscriber, error) {
+	if err := b.Connect(); err != nil {
+		return nil, err
+	}
+
+	return b.Subscribe(topic, func(p broker.Publication) error {
+		state := State{}
+
+		if err := json.Unmarshal(p.Message().Body, &state); err != nil || state.OrderId == "" || state.Status == "" {
+			if onError != nil {
+				onError(ErrIncorrectNotification)
+			}
+			return nil
+		}
+
+		store.Set(state)
+		return nil
+	})
+}
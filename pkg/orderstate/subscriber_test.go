@@ -0,0 +1,34 @@
+package orderstate
+
+import (
+	"github.com/micro/go-micro/broker"
+	"github.com/micro/go-micro/broker/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Disconnect()
+
+	store := NewStore(time.Minute, 0)
+	errs := make(chan error, 1)
+
+	sub, err := Subscribe(b, "order.status", store, func(err error) {
+		errs <- err
+	})
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	err = b.Publish("order.status", &broker.Message{Body: []byte(`{"order_id": "order", "status": "processed", "updated_at": 1}`)})
+	assert.NoError(t, err)
+
+	err = b.Publish("order.status", &broker.Message{Body: []byte(`{"order_id": "order"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, ErrIncorrectNotification, <-errs)
+
+	state, ok := store.Get("order")
+	assert.True(t, ok)
+	assert.Equal(t, "processed", state.Status)
+}
@@ -0,0 +1,145 @@
+package origins
+
+import (
+	"context"
+	"errors"
+	"github.com/paysuper/paysuper-checkout/pkg/ttlcache"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("project not found")
+)
+
+// Project keeps origins where the project payment form can be embedded
+type Project struct {
+	Id      string
+	Origins []string
+}
+
+// Source returns allowed origins of the project, project is resolved by the order if project id is empty.
+// ErrNotFound is returned if neither project nor order is known.
+type Source interface {
+	Lookup(ctx context.Context, projectId, orderId string) (*Project, error)
+}
+
+// SourceFunc adapts function to the Source interface
+type SourceFunc func(ctx context.Context, projectId, orderId string) (*Project, error)
+
+// Lookup
+func (f SourceFunc) Lookup(ctx context.Context, projectId, orderId string) (*Project, error) {
+	return f(ctx, projectId, orderId)
+}
+
+// Cache keeps lookup results of the source including unknown projects and orders.
+// Projects resolved by the order are cached by both keys, failed lookups aren't cached.
+type Cache struct {
+	source Source
+	items  *ttlcache.Cache
+	now    func() time.Time
+}
+
+// NewCache returns cache of the source limited by size, items live for ttl
+func NewCache(source Source, size int, ttl time.Duration) *Cache {
+	c := &Cache{source: source, now: time.Now}
+	c.items = ttlcache.NewWithClock(size, ttl, func() time.Time { return c.now() })
+	return c
+}
+
+// Lookup
+func (c *Cache) Lookup(ctx context.Context, projectId, orderId string) (*Project, error) {
+	key := cacheKey(projectId, orderId)
+
+	if item, ok := c.items.Get(key); ok {
+		if item == nil {
+			return nil, ErrNotFound
+		}
+		return item.(*Project), nil
+	}
+
+	project, err := c.source.Lookup(ctx, projectId, orderId)
+
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	if project == nil {
+		c.items.Set(key, nil)
+		return nil, err
+	}
+
+	c.items.Set(key, project)
+
+	if projectId == "" {
+		c.items.Set(cacheKey(project.Id, ""), project)
+	}
+
+	return project, err
+}
+
+// Purge removes all cached items
+func (c *Cache) Purge() {
+	c.items.Purge()
+}
+
+func cacheKey(projectId, orderId string) string {
+	if projectId != "" {
+		return "project:" + projectId
+	}
+	return "order:" + orderId
+}
+
+// Split returns trimmed non empty origins of the comma separated list
+func Split(list string) []string {
+	var patterns []string
+
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// Allowed checks origin against patterns. Pattern is either "*", exact origin like https://shop.com
+// or origin with wildcard subdomain like https://*.shop.com which doesn't match https://shop.com itself.
+func Allowed(patterns []string, origin string) bool {
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+
+	if origin == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(pattern), "/"))
+
+		if pattern == "*" || pattern == origin || matchSubdomain(pattern, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSubdomain(pattern, origin string) bool {
+	if !strings.Contains(pattern, "://*.") {
+		return false
+	}
+
+	p, err := url.Parse(strings.Replace(pattern, "://*.", "://", 1))
+
+	if err != nil {
+		return false
+	}
+
+	o, err := url.Parse(origin)
+
+	if err != nil || o.Scheme != p.Scheme || o.Port() != p.Port() {
+		return false
+	}
+
+	return strings.HasSuffix(o.Hostname(), "."+p.Hostname())
+}
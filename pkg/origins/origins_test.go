@@ -0,0 +1,145 @@
+package origins
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type sourceStub struct {
+	projects map[string]*Project
+	orders   map[string]string
+	err      error
+	calls    int
+}
+
+func (s *sourceStub) Lookup(_ context.Context, projectId, orderId string) (*Project, error) {
+	s.calls++
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if projectId == "" {
+		projectId = s.orders[orderId]
+	}
+
+	project, ok := s.projects[projectId]
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return project, nil
+}
+
+func newSourceStub() *sourceStub {
+	return &sourceStub{
+		projects: map[string]*Project{
+			"project": {Id: "project", Origins: []string{"https://shop.com"}},
+		},
+		orders: map[string]string{"order": "project"},
+	}
+}
+
+func TestCache_Lookup(t *testing.T) {
+	source := newSourceStub()
+	c := NewCache(source, 10, time.Minute)
+
+	project, err := c.Lookup(context.Background(), "", "order")
+	assert.NoError(t, err)
+	assert.Equal(t, "project", project.Id)
+
+	// project resolved by the order is cached by its own key too
+	project, err = c.Lookup(context.Background(), "project", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://shop.com"}, project.Origins)
+
+	_, err = c.Lookup(context.Background(), "", "order")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.calls)
+
+	_, err = c.Lookup(context.Background(), "", "unknown")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = c.Lookup(context.Background(), "", "unknown")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, 2, source.calls)
+
+	c.Purge()
+	_, err = c.Lookup(context.Background(), "project", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, source.calls)
+}
+
+func TestCache_ErrorNotCached(t *testing.T) {
+	source := newSourceStub()
+	source.err = errors.New("billing unavailable")
+	c := NewCache(source, 10, time.Minute)
+
+	_, err := c.Lookup(context.Background(), "project", "")
+	assert.Equal(t, source.err, err)
+
+	source.err = nil
+	project, err := c.Lookup(context.Background(), "project", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, project)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCache_Expiration(t *testing.T) {
+	now := time.Now()
+	source := newSourceStub()
+	c := NewCache(source, 1, time.Minute)
+	c.now = func() time.Time { return now }
+
+	_, _ = c.Lookup(context.Background(), "project", "")
+	now = now.Add(time.Minute)
+	_, _ = c.Lookup(context.Background(), "project", "")
+
+	assert.Equal(t, 2, source.calls)
+	assert.Equal(t, 1, c.items.Len())
+}
+
+func TestCache_EvictOldest(t *testing.T) {
+	source := newSourceStub()
+	source.projects["first"] = &Project{Id: "first"}
+	source.projects["second"] = &Project{Id: "second"}
+	c := NewCache(source, 2, time.Minute)
+
+	_, _ = c.Lookup(context.Background(), "first", "")
+	_, _ = c.Lookup(context.Background(), "second", "")
+	// miss of unknown project evicts the oldest item only
+	_, _ = c.Lookup(context.Background(), "unknown", "")
+	assert.Equal(t, 3, source.calls)
+	assert.Equal(t, 2, c.items.Len())
+
+	_, _ = c.Lookup(context.Background(), "second", "")
+	_, _ = c.Lookup(context.Background(), "unknown", "")
+	assert.Equal(t, 3, source.calls)
+
+	_, _ = c.Lookup(context.Background(), "first", "")
+	assert.Equal(t, 4, source.calls)
+}
+
+func TestAllowed(t *testing.T) {
+	patterns := Split(" https://shop.com, ,https://*.games.com:8443,http://localhost:3000/")
+
+	assert.Equal(t, []string{"https://shop.com", "https://*.games.com:8443", "http://localhost:3000/"}, patterns)
+
+	assert.True(t, Allowed(patterns, "https://shop.com"))
+	assert.True(t, Allowed(patterns, "HTTPS://Shop.com/"))
+	assert.True(t, Allowed(patterns, "https://eu.games.com:8443"))
+	assert.True(t, Allowed(patterns, "http://localhost:3000"))
+
+	assert.False(t, Allowed(patterns, ""))
+	assert.False(t, Allowed(patterns, "http://shop.com"))
+	assert.False(t, Allowed(patterns, "https://evil-shop.com"))
+	assert.False(t, Allowed(patterns, "https://games.com:8443"))
+	assert.False(t, Allowed(patterns, "https://eu.games.com"))
+	assert.False(t, Allowed(patterns, "https://eviltgames.com:8443"))
+	assert.False(t, Allowed(nil, "https://shop.com"))
+
+	assert.True(t, Allowed([]string{"*"}, "https://any.com"))
+}
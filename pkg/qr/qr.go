@@ -0,0 +1,80 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"strings"
+)
+
+var (
+	ErrUnknownLevel = errors.New("unknown qr code error correction level")
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// ParseLevel returns error correction level by its name: L, M, Q or H
+func ParseLevel(level string) (qrcode.RecoveryLevel, error) {
+	l, ok := levels[strings.ToUpper(level)]
+
+	if !ok {
+		return qrcode.Medium, ErrUnknownLevel
+	}
+
+	return l, nil
+}
+
+// PNG returns QR code of the content as PNG image of size x size pixels
+func PNG(content, level string, size int) ([]byte, error) {
+	l, err := ParseLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return qrcode.Encode(content, l, size)
+}
+
+// SVG returns QR code of the content as SVG image of size x size pixels
+func SVG(content, level string, size int) ([]byte, error) {
+	l, err := ParseLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.New(content, l)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	buf := &bytes.Buffer{}
+	_, _ = fmt.Fprintf(
+		buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules,
+	)
+	_, _ = fmt.Fprintf(buf, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, modules, modules)
+
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				_, _ = fmt.Fprintf(buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,42 @@
+package qr
+
+import (
+	"bytes"
+	"github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/assert"
+	"image/png"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	l, err := ParseLevel("h")
+	assert.NoError(t, err)
+	assert.Equal(t, qrcode.Highest, l)
+
+	_, err = ParseLevel("X")
+	assert.Equal(t, ErrUnknownLevel, err)
+}
+
+func TestPNG(t *testing.T) {
+	b, err := PNG("https://checkout.pay.super.com/p/abc?utm_source=poster", "M", 256)
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, 256, img.Bounds().Dx())
+	assert.Equal(t, 256, img.Bounds().Dy())
+
+	_, err = PNG("content", "X", 256)
+	assert.Equal(t, ErrUnknownLevel, err)
+}
+
+func TestSVG(t *testing.T) {
+	b, err := SVG("https://checkout.pay.super.com/p/abc", "Q", 300)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(b, []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300"`)))
+	assert.True(t, bytes.HasSuffix(b, []byte(`</svg>`)))
+	assert.Contains(t, string(b), "h1v1h-1z")
+
+	_, err = SVG("content", "X", 300)
+	assert.Equal(t, ErrUnknownLevel, err)
+}
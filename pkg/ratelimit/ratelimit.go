@@ -0,0 +1,183 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultMaxKeys limits memory used by counters of the limiter created by New
+const DefaultMaxKeys = 100000
+
+type counter struct {
+	key     string
+	hits    int
+	expires time.Time
+	element *list.Element
+}
+
+// Limiter counts hits per key inside a fixed time window.
+// Counters are kept in order of expiration, expired counters are removed on every hit
+// and the oldest counters are dropped when the limiter is full.
+type Limiter struct {
+	mx       sync.Mutex
+	limit    int
+	window   time.Duration
+	maxKeys  int
+	counters map[string]*counter
+	order    *list.List
+	now      func() time.Time
+}
+
+// Allow registers a hit for the key and reports whether the key is still under the limit
+func (l *Limiter) Allow(key string) bool {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.hit(key) <= l.limit || l.limit <= 0
+}
+
+// Hit registers a hit for the key and returns the number of hits inside the current window
+func (l *Limiter) Hit(key string) int {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.hit(key)
+}
+
+func (l *Limiter) hit(key string) int {
+	now := l.now()
+	l.evict(now)
+
+	c, ok := l.counters[key]
+
+	if !ok || !now.Before(c.expires) {
+		if ok {
+			l.remove(c)
+		}
+
+		for len(l.counters) >= l.maxKeys {
+			l.remove(l.order.Front().Value.(*counter))
+		}
+
+		c = &counter{key: key, expires: now.Add(l.window)}
+		c.element = l.order.PushBack(c)
+		l.counters[key] = c
+	}
+
+	c.hits++
+	return c.hits
+}
+
+// Count returns the number of hits for the key inside the current window
+func (l *Limiter) Count(key string) int {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.count(key)
+}
+
+func (l *Limiter) count(key string) int {
+	c, ok := l.counters[key]
+
+	if !ok || !l.now().Before(c.expires) {
+		return 0
+	}
+
+	return c.hits
+}
+
+// Exceeded reports whether the key reached the limit without registering a hit
+func (l *Limiter) Exceeded(key string) bool {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.limit > 0 && l.count(key) >= l.limit
+}
+
+// SetLimit changes the limit and the window, current windows of the keys are kept
+func (l *Limiter) SetLimit(limit int, window time.Duration) {
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.limit = limit
+	l.window = window
+}
+
+// Reset forgets all hits for the key
+func (l *Limiter) Reset(key string) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if c, ok := l.counters[key]; ok {
+		l.remove(c)
+	}
+}
+
+// Retry returns the time left until the window of the key is over
+func (l *Limiter) Retry(key string) time.Duration {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	c, ok := l.counters[key]
+
+	if !ok {
+		return 0
+	}
+
+	if left := c.expires.Sub(l.now()); left > 0 {
+		return left
+	}
+
+	return 0
+}
+
+// Len returns the number of counters kept by the limiter
+func (l *Limiter) Len() int {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return len(l.counters)
+}
+
+// evict removes expired counters, counters of the same limiter expire in order they were created
+// unless the window is changed, counters left after the change are removed when they reach the front
+func (l *Limiter) evict(now time.Time) {
+	for e := l.order.Front(); e != nil; e = l.order.Front() {
+		c := e.Value.(*counter)
+
+		if now.Before(c.expires) {
+			return
+		}
+
+		l.remove(c)
+	}
+}
+
+func (l *Limiter) remove(c *counter) {
+	l.order.Remove(c.element)
+	delete(l.counters, c.key)
+}
+
+// New returns limiter which allows limit hits per key inside the window, zero limit disables limiting
+func New(limit int, window time.Duration) *Limiter {
+	return NewWithMaxKeys(limit, window, DefaultMaxKeys)
+}
+
+// NewWithMaxKeys returns limiter which keeps counters of maxKeys keys at most,
+// counters of the oldest keys are dropped when the limit of keys is reached
+func NewWithMaxKeys(limit int, window time.Duration, maxKeys int) *Limiter {
+	if window <= 0 {
+		window = time.Minute
+	}
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &Limiter{
+		limit:    limit,
+		window:   window,
+		maxKeys:  maxKeys,
+		counters: make(map[string]*counter),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_Limiter_Allow(t *testing.T) {
+	l := New(2, time.Minute)
+
+	assert.True(t, l.Allow("key"))
+	assert.True(t, l.Allow("key"))
+	assert.False(t, l.Allow("key"))
+	assert.True(t, l.Allow("another_key"))
+	assert.Equal(t, 3, l.Count("key"))
+	assert.True(t, l.Exceeded("key"))
+}
+
+func Test_Limiter_WindowExpired(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	assert.True(t, l.Allow("key"))
+	assert.False(t, l.Allow("key"))
+	assert.Equal(t, time.Minute, l.Retry("key"))
+
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, 0, l.Count("key"))
+	assert.True(t, l.Allow("key"))
+	assert.Len(t, l.counters, 1)
+}
+
+func Test_Limiter_SetLimit(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	assert.True(t, l.Allow("key"))
+	assert.False(t, l.Allow("key"))
+
+	l.SetLimit(3, time.Hour)
+
+	assert.True(t, l.Allow("key"))
+	assert.Equal(t, time.Minute, l.Retry("key"))
+	assert.True(t, l.Allow("another_key"))
+	assert.Equal(t, time.Hour, l.Retry("another_key"))
+
+	l.SetLimit(0, 0)
+
+	assert.True(t, l.Allow("key"))
+	assert.False(t, l.Exceeded("key"))
+}
+
+func Test_Limiter_Reset(t *testing.T) {
+	l := New(1, time.Minute)
+
+	assert.True(t, l.Allow("key"))
+	l.Reset("key")
+	assert.True(t, l.Allow("key"))
+}
+
+func Test_Limiter_Unlimited(t *testing.T) {
+	l := New(0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow("key"))
+	}
+
+	assert.False(t, l.Exceeded("key"))
+}
+
+func Test_Limiter_ExpiredEvicted(t *testing.T) {
+	now := time.Now()
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return now }
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		l.Allow(key)
+	}
+
+	now = now.Add(time.Minute)
+	l.Allow("key4")
+
+	assert.Equal(t, 1, l.Len())
+	assert.Equal(t, 1, l.order.Len())
+}
+
+func Test_Limiter_MaxKeys(t *testing.T) {
+	l := NewWithMaxKeys(1, time.Minute, 2)
+
+	assert.True(t, l.Allow("key1"))
+	assert.True(t, l.Allow("key2"))
+	assert.False(t, l.Allow("key2"))
+	assert.True(t, l.Allow("key3"))
+
+	assert.Equal(t, 2, l.Len())
+	assert.Equal(t, 0, l.Count("key1"))
+	assert.Equal(t, 2, l.Count("key2"))
+	assert.Equal(t, 1, l.Count("key3"))
+}
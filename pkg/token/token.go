@@ -0,0 +1,114 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrInvalid  = errors.New("token is invalid")
+	ErrExpired  = errors.New("token is expired")
+	ErrNoSecret = errors.New("signing secret isn't set")
+
+	encoding = base64.RawURLEncoding
+)
+
+type envelope struct {
+	Purpose string          `json:"pur"`
+	Expires int64           `json:"exp"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Signer issues and verifies short-lived HMAC signed tokens of a single purpose,
+// tokens issued for another purpose aren't accepted even if they are signed with the same secret
+type Signer struct {
+	purpose string
+	secret  atomic.Value
+	now     func() time.Time
+}
+
+// SetSecret replaces the secret, tokens signed with the previous secret aren't accepted anymore
+func (s *Signer) SetSecret(secret string) {
+	s.secret.Store([]byte(secret))
+}
+
+// Sign returns token which contains data and valid until expires
+func (s *Signer) Sign(data interface{}, expires time.Time) (string, error) {
+	secret := s.secret.Load().([]byte)
+
+	if len(secret) == 0 {
+		return "", ErrNoSecret
+	}
+
+	raw, err := json.Marshal(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(&envelope{Purpose: s.purpose, Expires: expires.Unix(), Data: raw})
+
+	if err != nil {
+		return "", err
+	}
+
+	encoded := encoding.EncodeToString(payload)
+	return encoded + "." + encoding.EncodeToString(sign(secret, encoded)), nil
+}
+
+// Verify checks token signature and expiration time and decodes token data into data
+func (s *Signer) Verify(token string, data interface{}) error {
+	secret := s.secret.Load().([]byte)
+	parts := strings.Split(token, ".")
+
+	if len(secret) == 0 || len(parts) != 2 {
+		return ErrInvalid
+	}
+
+	signature, err := encoding.DecodeString(parts[1])
+
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0])) {
+		return ErrInvalid
+	}
+
+	payload, err := encoding.DecodeString(parts[0])
+
+	if err != nil {
+		return ErrInvalid
+	}
+
+	env := &envelope{}
+
+	if err = json.Unmarshal(payload, env); err != nil || env.Purpose != s.purpose {
+		return ErrInvalid
+	}
+
+	if s.now().Unix() >= env.Expires {
+		return ErrExpired
+	}
+
+	if err = json.Unmarshal(env.Data, data); err != nil {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+func sign(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// NewSigner returns signer of the tokens of the purpose, signer without secret neither issues nor accepts tokens
+func NewSigner(purpose, secret string) *Signer {
+	s := &Signer{purpose: purpose, now: time.Now}
+	s.SetSecret(secret)
+	return s
+}
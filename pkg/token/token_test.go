@@ -0,0 +1,83 @@
+package token
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testData struct {
+	Id     string  `json:"id"`
+	Amount float64 `json:"amount"`
+}
+
+func Test_Signer_Ok(t *testing.T) {
+	s := NewSigner("test", "secret")
+	tkn, err := s.Sign(&testData{Id: "id", Amount: 10.5}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	data := &testData{}
+	assert.NoError(t, s.Verify(tkn, data))
+	assert.Equal(t, "id", data.Id)
+	assert.Equal(t, 10.5, data.Amount)
+}
+
+func Test_Signer_Expired(t *testing.T) {
+	s := NewSigner("test", "secret")
+	tkn, err := s.Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	assert.Equal(t, ErrExpired, s.Verify(tkn, &testData{}))
+}
+
+func Test_Signer_AnotherSecret(t *testing.T) {
+	tkn, err := NewSigner("test", "secret").Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	assert.Equal(t, ErrInvalid, NewSigner("test", "another").Verify(tkn, &testData{}))
+	assert.Equal(t, ErrInvalid, NewSigner("test", "").Verify(tkn, &testData{}))
+}
+
+func Test_Signer_SetSecret(t *testing.T) {
+	s := NewSigner("test", "secret")
+	tkn, err := s.Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	s.SetSecret("another")
+	assert.Equal(t, ErrInvalid, s.Verify(tkn, &testData{}))
+
+	tkn, err = s.Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.NoError(t, NewSigner("test", "another").Verify(tkn, &testData{}))
+}
+
+func Test_Signer_NoSecret(t *testing.T) {
+	_, err := NewSigner("test", "").Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.Equal(t, ErrNoSecret, err)
+}
+
+func Test_Signer_Tampered(t *testing.T) {
+	s := NewSigner("test", "secret")
+	tkn, err := s.Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	tampered, err := s.Sign(&testData{Id: "another"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	payload := strings.Split(tampered, ".")[0]
+	signature := strings.Split(tkn, ".")[1]
+
+	assert.Equal(t, ErrInvalid, s.Verify(payload+"."+signature, &testData{}))
+	assert.Equal(t, ErrInvalid, s.Verify("", &testData{}))
+	assert.Equal(t, ErrInvalid, s.Verify("a.b.c", &testData{}))
+}
+
+func Test_Signer_AnotherPurpose(t *testing.T) {
+	tkn, err := NewSigner("quote", "secret").Sign(&testData{Id: "id"}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	assert.Equal(t, ErrInvalid, NewSigner("attribution", "secret").Verify(tkn, &testData{}))
+	assert.NoError(t, NewSigner("quote", "secret").Verify(tkn, &testData{}))
+}
@@ -0,0 +1,126 @@
+package ttlcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type item struct {
+	key     string
+	value   interface{}
+	expires time.Time
+	element *list.Element
+}
+
+// Cache keeps values for ttl. Items share ttl, so they are kept in order of expiration,
+// expired items are removed on every change and the oldest items are evicted when the cache is full.
+type Cache struct {
+	mx    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*item
+	order *list.List
+	now   func() time.Time
+}
+
+// New returns cache of size items at most, zero size means no limit
+func New(size int, ttl time.Duration) *Cache {
+	return NewWithClock(size, ttl, time.Now)
+}
+
+// NewWithClock returns cache which gets current time from now
+func NewWithClock(size int, ttl time.Duration, now func() time.Time) *Cache {
+	return &Cache{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*item),
+		order: list.New(),
+		now:   now,
+	}
+}
+
+// Get returns value of the key if it isn't expired, nil values are kept as any others
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	it, ok := c.items[key]
+
+	if !ok || !c.now().Before(it.expires) {
+		return nil, false
+	}
+
+	return it.value, true
+}
+
+// Set saves value of the key for ttl
+func (c *Cache) Set(key string, value interface{}) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.set(key, value, c.now())
+}
+
+// Add saves value of the key for ttl only if the key has no value yet, false is returned otherwise
+func (c *Cache) Add(key string, value interface{}) bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	now := c.now()
+
+	if it, ok := c.items[key]; ok && now.Before(it.expires) {
+		return false
+	}
+
+	c.set(key, value, now)
+	return true
+}
+
+// SetTtl changes ttl of the items saved later, items saved before keep their expiration
+// and are removed when they reach the front of the expiration order
+func (c *Cache) SetTtl(ttl time.Duration) {
+	c.mx.Lock()
+	c.ttl = ttl
+	c.mx.Unlock()
+}
+
+// Purge removes all items
+func (c *Cache) Purge() {
+	c.mx.Lock()
+	c.items = make(map[string]*item)
+	c.order.Init()
+	c.mx.Unlock()
+}
+
+// Len returns count of the items including expired ones which weren't removed yet
+func (c *Cache) Len() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return len(c.items)
+}
+
+func (c *Cache) set(key string, value interface{}, now time.Time) {
+	if it, ok := c.items[key]; ok {
+		c.remove(it)
+	}
+
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		it := front.Value.(*item)
+
+		if now.Before(it.expires) && (c.size <= 0 || len(c.items) < c.size) {
+			break
+		}
+
+		c.remove(it)
+	}
+
+	it := &item{key: key, value: value, expires: now.Add(c.ttl)}
+	it.element = c.order.PushBack(it)
+	c.items[key] = it
+}
+
+func (c *Cache) remove(it *item) {
+	c.order.Remove(it.element)
+	delete(c.items, it.key)
+}
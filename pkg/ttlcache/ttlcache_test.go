@@ -0,0 +1,85 @@
+package ttlcache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCache_SetAndGet(t *testing.T) {
+	c := New(10, time.Minute)
+
+	_, ok := c.Get("key")
+	assert.False(t, ok)
+
+	c.Set("key", "value")
+	c.Set("nil", nil)
+
+	value, ok := c.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+
+	value, ok = c.Get("nil")
+	assert.True(t, ok)
+	assert.Nil(t, value)
+
+	c.Purge()
+	_, ok = c.Get("key")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_Expiration(t *testing.T) {
+	now := time.Now()
+	c := NewWithClock(0, time.Minute, func() time.Time { return now })
+
+	c.Set("first", 1)
+	now = now.Add(30 * time.Second)
+	c.Set("second", 2)
+	now = now.Add(30 * time.Second)
+
+	_, ok := c.Get("first")
+	assert.False(t, ok)
+	_, ok = c.Get("second")
+	assert.True(t, ok)
+
+	// expired items are removed by the next change
+	c.Set("third", 3)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestCache_EvictOldest(t *testing.T) {
+	now := time.Now()
+	c := NewWithClock(2, time.Minute, func() time.Time { return now })
+
+	c.Set("first", 1)
+	c.Set("second", 2)
+	// update moves the item to the end of the expiration order
+	c.Set("first", 1)
+	c.Set("third", 3)
+
+	assert.Equal(t, 2, c.Len())
+	_, ok := c.Get("second")
+	assert.False(t, ok)
+	_, ok = c.Get("first")
+	assert.True(t, ok)
+}
+
+func TestCache_Add(t *testing.T) {
+	now := time.Now()
+	c := NewWithClock(10, time.Minute, func() time.Time { return now })
+
+	assert.True(t, c.Add("key", 1))
+	assert.False(t, c.Add("key", 2))
+
+	value, _ := c.Get("key")
+	assert.Equal(t, 1, value)
+
+	c.SetTtl(2 * time.Minute)
+	now = now.Add(time.Minute)
+	assert.True(t, c.Add("key", 3))
+
+	now = now.Add(time.Minute + time.Second)
+	_, ok := c.Get("key")
+	assert.True(t, ok)
+}
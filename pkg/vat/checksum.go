@@ -0,0 +1,130 @@
+package vat
+
+// checkAt verifies Austrian UID, letter U is followed by 7 digits and check digit
+func checkAt(number string) bool {
+	d := digits(number)
+	return (6-luhnSum(d[:7])+10)%10 == d[7]
+}
+
+// checkBe verifies Belgian enterprise number, last two digits are mod 97 of the first eight
+func checkBe(number string) bool {
+	d := digits(number)
+	return 97-atoi(d[:8])%97 == atoi(d[8:])
+}
+
+// checkDe verifies German USt-IdNr by ISO 7064 MOD 11,10
+func checkDe(number string) bool {
+	d := digits(number)
+	product := 10
+
+	for _, n := range d[:8] {
+		sum := (n + product) % 10
+
+		if sum == 0 {
+			sum = 10
+		}
+
+		product = (2 * sum) % 11
+	}
+
+	return (11-product)%10 == d[8]
+}
+
+// checkDk verifies Danish CVR number
+func checkDk(number string) bool {
+	return weightedSum(digits(number), 2, 7, 6, 5, 4, 3, 2, 1)%11 == 0
+}
+
+// checkFi verifies Finnish ALV number
+func checkFi(number string) bool {
+	return weightedSum(digits(number), 7, 9, 10, 5, 8, 4, 2, 1)%11 == 0
+}
+
+// checkFr verifies numeric key of French TVA number, alphanumeric keys are checked by format only
+func checkFr(number string) bool {
+	d := digits(number)
+
+	if len(d) != 11 {
+		return true
+	}
+
+	return (12+3*(atoi(d[2:])%97))%97 == atoi(d[:2])
+}
+
+// checkIt verifies Italian partita IVA by Luhn algorithm
+func checkIt(number string) bool {
+	d := digits(number)
+	return atoi(d[:7]) != 0 && luhnSum(d) == 0
+}
+
+// checkLu verifies Luxembourg TVA number, last two digits are mod 89 of the first six
+func checkLu(number string) bool {
+	d := digits(number)
+	return atoi(d[:6])%89 == atoi(d[6:])
+}
+
+// checkNl verifies Dutch btw-id by the mod 11 check of the legal entities
+// or ISO 7064 MOD 97-10 of the sole proprietors
+func checkNl(number string) bool {
+	d := digits(number)
+	sum := weightedSum(d, 9, 8, 7, 6, 5, 4, 3, 2)
+
+	if sum%11 != 10 && sum%11 == d[8] {
+		return true
+	}
+
+	// NL prefix and letter B are converted to numbers: N=23, L=21, B=11
+	rest := 2321
+
+	for _, n := range d[:9] {
+		rest = (rest*10 + n) % 97
+	}
+
+	rest = (rest*100 + 11) % 97
+
+	for _, n := range d[9:] {
+		rest = (rest*10 + n) % 97
+	}
+
+	return rest == 1
+}
+
+// checkPl verifies Polish NIP
+func checkPl(number string) bool {
+	d := digits(number)
+	check := weightedSum(d, 6, 5, 7, 2, 3, 4, 5, 6, 7) % 11
+	return check != 10 && check == d[9]
+}
+
+// checkPt verifies Portuguese NIF
+func checkPt(number string) bool {
+	d := digits(number)
+	check := 11 - weightedSum(d, 9, 8, 7, 6, 5, 4, 3, 2)%11
+
+	if check > 9 {
+		check = 0
+	}
+
+	return check == d[8]
+}
+
+// checkSe verifies Swedish momsregistreringsnummer, organisation number is checked by Luhn algorithm
+func checkSe(number string) bool {
+	return luhnSum(digits(number)[:10]) == 0
+}
+
+// checkSi verifies Slovenian ID za DDV
+func checkSi(number string) bool {
+	d := digits(number)
+	check := 11 - weightedSum(d, 8, 7, 6, 5, 4, 3, 2)%11
+
+	if check == 11 {
+		return false
+	}
+
+	if check == 10 {
+		check = 0
+	}
+
+	return check == d[7]
+}
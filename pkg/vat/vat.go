@@ -0,0 +1,165 @@
+package vat
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrUnknownCountry  = errors.New("vat id country isn't in the european union")
+	ErrInvalidFormat   = errors.New("vat id format is invalid")
+	ErrInvalidChecksum = errors.New("vat id checksum is invalid")
+)
+
+// Id is a parsed vat identification number
+type Id struct {
+	// Country is a vat country prefix, it differs from the ISO code for Greece (EL)
+	Country string
+	Number  string
+}
+
+// String returns vat id with the country prefix
+func (id *Id) String() string {
+	return id.Country + id.Number
+}
+
+type rule struct {
+	format   *regexp.Regexp
+	checksum func(number string) bool
+}
+
+// rules contain vat number formats of the EU member states, checksum is verified where algorithm is public and stable
+var rules = map[string]*rule{
+	"AT": {regexp.MustCompile("^U[0-9]{8}$"), checkAt},
+	"BE": {regexp.MustCompile("^[01][0-9]{9}$"), checkBe},
+	"BG": {regexp.MustCompile("^[0-9]{9,10}$"), nil},
+	"CY": {regexp.MustCompile("^[0-9]{8}[A-Z]$"), nil},
+	"CZ": {regexp.MustCompile("^[0-9]{8,10}$"), nil},
+	"DE": {regexp.MustCompile("^[1-9][0-9]{8}$"), checkDe},
+	"DK": {regexp.MustCompile("^[1-9][0-9]{7}$"), checkDk},
+	"EE": {regexp.MustCompile("^10[0-9]{7}$"), nil},
+	"EL": {regexp.MustCompile("^[0-9]{9}$"), nil},
+	"ES": {regexp.MustCompile("^[A-Z0-9][0-9]{7}[A-Z0-9]$"), nil},
+	"FI": {regexp.MustCompile("^[0-9]{8}$"), checkFi},
+	"FR": {regexp.MustCompile("^[0-9A-HJ-NP-Z]{2}[0-9]{9}$"), checkFr},
+	"HR": {regexp.MustCompile("^[0-9]{11}$"), nil},
+	"HU": {regexp.MustCompile("^[0-9]{8}$"), nil},
+	"IE": {regexp.MustCompile("^([0-9]{7}[A-W][A-I]?|[0-9][A-Z+*][0-9]{5}[A-W])$"), nil},
+	"IT": {regexp.MustCompile("^[0-9]{11}$"), checkIt},
+	"LT": {regexp.MustCompile("^([0-9]{9}|[0-9]{12})$"), nil},
+	"LU": {regexp.MustCompile("^[0-9]{8}$"), checkLu},
+	"LV": {regexp.MustCompile("^[0-9]{11}$"), nil},
+	"MT": {regexp.MustCompile("^[1-9][0-9]{7}$"), nil},
+	"NL": {regexp.MustCompile("^[0-9]{9}B[0-9]{2}$"), checkNl},
+	"PL": {regexp.MustCompile("^[0-9]{10}$"), checkPl},
+	"PT": {regexp.MustCompile("^[0-9]{9}$"), checkPt},
+	"RO": {regexp.MustCompile("^[1-9][0-9]{1,9}$"), nil},
+	"SE": {regexp.MustCompile("^[0-9]{10}01$"), checkSe},
+	"SI": {regexp.MustCompile("^[1-9][0-9]{7}$"), checkSi},
+	"SK": {regexp.MustCompile("^[1-9][0-9]{9}$"), nil},
+}
+
+// CountryPrefix returns vat prefix of the ISO country code, empty string is returned for non EU countries
+func CountryPrefix(country string) string {
+	country = strings.ToUpper(country)
+
+	if country == "GR" {
+		country = "EL"
+	}
+
+	if _, ok := rules[country]; !ok {
+		return ""
+	}
+
+	return country
+}
+
+// Normalize removes separators customers use in the vat id and converts it to upper case
+func Normalize(id string) string {
+	return strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.ToUpper(strings.TrimSpace(id)))
+}
+
+// Parse checks format and checksum of the vat id with the country prefix.
+// If vat id is entered without prefix it's taken from the billing country.
+func Parse(id, country string) (*Id, error) {
+	id = Normalize(id)
+	prefix := CountryPrefix(country)
+
+	if len(id) >= 2 {
+		if _, ok := rules[id[:2]]; ok {
+			prefix = id[:2]
+			id = id[2:]
+		}
+	}
+
+	r, ok := rules[prefix]
+
+	if !ok {
+		return nil, ErrUnknownCountry
+	}
+
+	if !r.format.MatchString(id) {
+		return nil, ErrInvalidFormat
+	}
+
+	if r.checksum != nil && !r.checksum(id) {
+		return nil, ErrInvalidChecksum
+	}
+
+	return &Id{Country: prefix, Number: id}, nil
+}
+
+func digits(number string) []int {
+	d := make([]int, 0, len(number))
+
+	for i := 0; i < len(number); i++ {
+		if number[i] >= '0' && number[i] <= '9' {
+			d = append(d, int(number[i]-'0'))
+		}
+	}
+
+	return d
+}
+
+func weightedSum(d []int, weights ...int) int {
+	sum := 0
+
+	for i, w := range weights {
+		sum += d[i] * w
+	}
+
+	return sum
+}
+
+func luhnSum(d []int) int {
+	sum := 0
+	double := false
+
+	for i := len(d) - 1; i >= 0; i-- {
+		v := d[i]
+
+		if double {
+			v *= 2
+
+			if v > 9 {
+				v -= 9
+			}
+		}
+
+		sum += v
+		double = !double
+	}
+
+	return sum % 10
+}
+
+func atoi(d []int) int {
+	v := 0
+
+	for _, n := range d {
+		v = v*10 + n
+	}
+
+	return v
+}
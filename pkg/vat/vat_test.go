@@ -0,0 +1,131 @@
+package vat
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParse_Valid(t *testing.T) {
+	cases := map[string]string{
+		"ATU13585627":       "AT",
+		"BE0428759497":      "BE",
+		"DE136695976":       "DE",
+		"DK13585628":        "DK",
+		"FI20774740":        "FI",
+		"FR40303265045":     "FR",
+		"IT00743110157":     "IT",
+		"LU15027442":        "LU",
+		"NL004495445B01":    "NL",
+		"NL000099998B57":    "NL",
+		"PL8567346215":      "PL",
+		"PT501964843":       "PT",
+		"SE556188840401":    "SE",
+		"SI50223054":        "SI",
+		"EL094259216":       "EL",
+		"ESA28015865":       "ES",
+		"de 136.695.976":    "DE",
+		"CZ-25123891":       "CZ",
+		"IE6388047V":        "IE",
+		"RO18547290":        "RO",
+		"SK2022749619":      "SK",
+		"HU12892312":        "HU",
+		"CY10259033P":       "CY",
+		"FRK7399859412":     "FR",
+		"LT100001919017":    "LT",
+		"MT11679112":        "MT",
+		"HR33392005961":     "HR",
+		"LV40003009497":     "LV",
+		"EE100931558":       "EE",
+		"BG175074752":       "BG",
+		"FR 40 303 265 045": "FR",
+	}
+
+	for id, country := range cases {
+		v, err := Parse(id, "")
+		assert.NoError(t, err, id)
+
+		if err == nil {
+			assert.Equal(t, country, v.Country, id)
+		}
+	}
+}
+
+func TestParse_CountryFromBillingAddress(t *testing.T) {
+	v, err := Parse("094259216", "GR")
+	assert.NoError(t, err)
+	assert.Equal(t, "EL094259216", v.String())
+
+	v, err = Parse("136695976", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, "DE136695976", v.String())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	cases := map[string]error{
+		"ATU13585626":    ErrInvalidChecksum,
+		"BE0428759498":   ErrInvalidChecksum,
+		"DE136695977":    ErrInvalidChecksum,
+		"DK13585627":     ErrInvalidChecksum,
+		"FI20774741":     ErrInvalidChecksum,
+		"FR41303265045":  ErrInvalidChecksum,
+		"IT00743110158":  ErrInvalidChecksum,
+		"LU15027443":     ErrInvalidChecksum,
+		"NL004495446B01": ErrInvalidChecksum,
+		"PL8567346216":   ErrInvalidChecksum,
+		"PT501964844":    ErrInvalidChecksum,
+		"SE556188840501": ErrInvalidChecksum,
+		"SI50223055":     ErrInvalidChecksum,
+		"DE12345678":     ErrInvalidFormat,
+		"NL004495445A01": ErrInvalidFormat,
+		"GB123456789":    ErrUnknownCountry,
+		"123456789":      ErrUnknownCountry,
+		"":               ErrUnknownCountry,
+	}
+
+	for id, expected := range cases {
+		_, err := Parse(id, "")
+		assert.Equal(t, expected, err, id)
+	}
+}
+
+func TestCountryPrefix(t *testing.T) {
+	assert.Equal(t, "EL", CountryPrefix("GR"))
+	assert.Equal(t, "DE", CountryPrefix("de"))
+	assert.Equal(t, "", CountryPrefix("US"))
+}
+
+func TestViesChecker_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ms/DE/vat/136695976":
+			_, _ = w.Write([]byte(`{"isValid":true,"userError":"VALID","name":"Company GmbH","address":"Berlin"}`))
+		case "/ms/DE/vat/811191002":
+			_, _ = w.Write([]byte(`{"isValid":false,"userError":"INVALID"}`))
+		case "/ms/FR/vat/40303265045":
+			_, _ = w.Write([]byte(`{"isValid":false,"userError":"MS_UNAVAILABLE"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewViesChecker(server.URL+"/", time.Second)
+
+	res, err := checker.Check(context.Background(), &Id{Country: "DE", Number: "136695976"})
+	assert.NoError(t, err)
+	assert.Equal(t, &CheckResult{Valid: true, Name: "Company GmbH", Address: "Berlin"}, res)
+
+	res, err = checker.Check(context.Background(), &Id{Country: "DE", Number: "811191002"})
+	assert.NoError(t, err)
+	assert.False(t, res.Valid)
+
+	_, err = checker.Check(context.Background(), &Id{Country: "FR", Number: "40303265045"})
+	assert.Equal(t, ErrCheckerUnavailable, err)
+
+	_, err = checker.Check(context.Background(), &Id{Country: "IT", Number: "00743110157"})
+	assert.Equal(t, ErrCheckerUnavailable, err)
+}
@@ -0,0 +1,89 @@
+package vat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	viesResponseMaxSize = 1 << 16
+)
+
+var (
+	ErrCheckerUnavailable = errors.New("vat id registry is unavailable")
+)
+
+// CheckResult is a result of the vat id lookup in the online registry
+type CheckResult struct {
+	Valid   bool
+	Name    string
+	Address string
+}
+
+// Checker verifies vat id registration online
+type Checker interface {
+	Check(ctx context.Context, id *Id) (*CheckResult, error)
+}
+
+type viesResponse struct {
+	IsValid   bool   `json:"isValid"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	UserError string `json:"userError"`
+}
+
+// ViesChecker uses VIES REST API of the European Commission or a service with the same API
+type ViesChecker struct {
+	baseUrl string
+	http    *http.Client
+}
+
+// NewViesChecker returns checker which sends requests to baseUrl like https://ec.europa.eu/taxation_customs/vies/rest-api
+func NewViesChecker(baseUrl string, timeout time.Duration) *ViesChecker {
+	return &ViesChecker{
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Check returns ErrCheckerUnavailable if registry of the member state doesn't answer
+func (c *ViesChecker) Check(ctx context.Context, id *Id) (*CheckResult, error) {
+	u := c.baseUrl + "/ms/" + url.PathEscape(id.Country) + "/vat/" + url.PathEscape(id.Number)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	rsp, err := c.http.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return nil, ErrCheckerUnavailable
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, ErrCheckerUnavailable
+	}
+
+	body := &viesResponse{}
+
+	if err := json.NewDecoder(io.LimitReader(rsp.Body, viesResponseMaxSize)).Decode(body); err != nil {
+		return nil, ErrCheckerUnavailable
+	}
+
+	// registry answers with error codes like MS_UNAVAILABLE or TIMEOUT when it can't verify the number
+	if body.UserError != "" && body.UserError != "VALID" && body.UserError != "INVALID" {
+		return nil, ErrCheckerUnavailable
+	}
+
+	return &CheckResult{Valid: body.IsValid, Name: body.Name, Address: body.Address}, nil
+}
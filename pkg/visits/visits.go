@@ -0,0 +1,166 @@
+package visits
+
+import (
+	"context"
+	"github.com/paysuper/paysuper-checkout/pkg/batch"
+	"time"
+)
+
+// FlushFunc sends accumulated visits count per paylink
+type FlushFunc func(ctx context.Context, counts map[string]int) error
+
+// Options
+type Options struct {
+	// Interval between flushes
+	Interval time.Duration
+	// Window inside which repeated visits of the same visitor are counted once
+	Window time.Duration
+	// MaxPending is a max count of paylinks waiting for flush, visits of new paylinks are dropped when it's reached
+	MaxPending int
+	// MaxVisitors is a max count of remembered visitors, visits aren't deduplicated when it's reached
+	MaxVisitors int
+}
+
+// Aggregator deduplicates visits and sends them in batches without spawning goroutine per visit
+type Aggregator struct {
+	opts    Options
+	pending *pendingCounts
+	batcher *batch.Batcher
+	// seen and sweepAt are guarded by the batcher
+	seen    map[string]time.Time
+	sweepAt time.Time
+	now     func() time.Time
+}
+
+// pendingCounts are guarded by the batcher
+type pendingCounts struct {
+	max    int
+	counts map[string]int
+}
+
+// Take
+func (p *pendingCounts) Take() interface{} {
+	if len(p.counts) == 0 {
+		return nil
+	}
+
+	counts := p.counts
+	p.counts = make(map[string]int)
+	return counts
+}
+
+// Restore adds counts which failed to flush to the counts added during the flush
+func (p *pendingCounts) Restore(b interface{}) int {
+	dropped := 0
+
+	for id, count := range b.(map[string]int) {
+		if _, ok := p.counts[id]; ok || p.max <= 0 || len(p.counts) < p.max {
+			p.counts[id] += count
+		} else {
+			dropped += count
+		}
+	}
+
+	return dropped
+}
+
+// New returns aggregator, Run must be called to start periodic flushes
+func New(flush FlushFunc, opts Options) *Aggregator {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+
+	pending := &pendingCounts{max: opts.MaxPending, counts: make(map[string]int)}
+	flushBatch := func(ctx context.Context, b interface{}) error {
+		return flush(ctx, b.(map[string]int))
+	}
+
+	return &Aggregator{
+		opts:    opts,
+		pending: pending,
+		batcher: batch.New(pending, flushBatch, opts.Interval),
+		seen:    make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Add registers visit of the paylink, returns false if visit is a duplicate or dropped
+func (a *Aggregator) Add(paylinkId, visitor string) bool {
+	added := false
+
+	a.batcher.Do(func() int {
+		now := a.now()
+		a.sweep(now)
+
+		key := paylinkId + "|" + visitor
+
+		if expires, ok := a.seen[key]; ok && now.Before(expires) {
+			return 0
+		}
+
+		if _, ok := a.pending.counts[paylinkId]; !ok && a.opts.MaxPending > 0 && len(a.pending.counts) >= a.opts.MaxPending {
+			return 1
+		}
+
+		if a.opts.Window > 0 && (a.opts.MaxVisitors <= 0 || len(a.seen) < a.opts.MaxVisitors) {
+			a.seen[key] = now.Add(a.opts.Window)
+		}
+
+		a.pending.counts[paylinkId]++
+		added = true
+		return 0
+	})
+
+	return added
+}
+
+// Pending returns count of visits waiting for flush per paylink
+func (a *Aggregator) Pending() map[string]int {
+	var counts map[string]int
+
+	a.batcher.Do(func() int {
+		counts = make(map[string]int, len(a.pending.counts))
+
+		for id, count := range a.pending.counts {
+			counts[id] = count
+		}
+
+		return 0
+	})
+
+	return counts
+}
+
+// Dropped returns count of visits dropped because of MaxPending limit
+func (a *Aggregator) Dropped() int {
+	return a.batcher.Dropped()
+}
+
+// Flush sends pending visits, visits are returned back to pending if sending fails
+func (a *Aggregator) Flush(ctx context.Context) error {
+	return a.batcher.Flush(ctx)
+}
+
+// Run flushes visits periodically until Close is called or context is cancelled, errors are passed to onError
+func (a *Aggregator) Run(ctx context.Context, onError func(err error)) {
+	a.batcher.Run(ctx, onError)
+}
+
+// Close stops periodic flushes and sends remaining visits
+func (a *Aggregator) Close(ctx context.Context) error {
+	return a.batcher.Close(ctx)
+}
+
+func (a *Aggregator) sweep(now time.Time) {
+	if now.Before(a.sweepAt) {
+		return
+	}
+
+	for key, expires := range a.seen {
+		if !now.Before(expires) {
+			delete(a.seen, key)
+		}
+	}
+
+	a.sweepAt = now.Add(a.opts.Window)
+}
@@ -0,0 +1,143 @@
+package visits
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sink struct {
+	mx     sync.Mutex
+	counts map[string]int
+	err    error
+}
+
+func (s *sink) flush(_ context.Context, counts map[string]int) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	for id, count := range counts {
+		s.counts[id] += count
+	}
+
+	return nil
+}
+
+func (s *sink) get(id string) int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.counts[id]
+}
+
+func newSink() *sink {
+	return &sink{counts: make(map[string]int)}
+}
+
+func TestAggregator_Deduplication(t *testing.T) {
+	s := newSink()
+	now := time.Now()
+	a := New(s.flush, Options{Window: time.Minute})
+	a.now = func() time.Time { return now }
+
+	assert.True(t, a.Add("paylink", "visitor1"))
+	assert.False(t, a.Add("paylink", "visitor1"))
+	assert.True(t, a.Add("paylink", "visitor2"))
+	assert.True(t, a.Add("paylink2", "visitor1"))
+	assert.Equal(t, map[string]int{"paylink": 2, "paylink2": 1}, a.Pending())
+
+	now = now.Add(time.Minute)
+	assert.True(t, a.Add("paylink", "visitor1"))
+	assert.Equal(t, 3, a.Pending()["paylink"])
+}
+
+func TestAggregator_MaxPending(t *testing.T) {
+	a := New(newSink().flush, Options{MaxPending: 1})
+
+	assert.True(t, a.Add("paylink", "visitor1"))
+	assert.False(t, a.Add("paylink2", "visitor1"))
+	assert.True(t, a.Add("paylink", "visitor2"))
+	assert.Equal(t, 1, a.Dropped())
+}
+
+func TestAggregator_MaxVisitors(t *testing.T) {
+	a := New(newSink().flush, Options{Window: time.Minute, MaxVisitors: 1})
+
+	assert.True(t, a.Add("paylink", "visitor1"))
+	assert.True(t, a.Add("paylink", "visitor2"))
+	assert.True(t, a.Add("paylink", "visitor2"))
+	assert.False(t, a.Add("paylink", "visitor1"))
+	assert.Equal(t, 3, a.Pending()["paylink"])
+}
+
+func TestAggregator_Flush(t *testing.T) {
+	s := newSink()
+	a := New(s.flush, Options{})
+
+	a.Add("paylink", "visitor1")
+	a.Add("paylink", "visitor2")
+	assert.NoError(t, a.Flush(context.Background()))
+	assert.Equal(t, 2, s.get("paylink"))
+	assert.Empty(t, a.Pending())
+
+	s.err = errors.New("error")
+	a.Add("paylink", "visitor3")
+	assert.Error(t, a.Flush(context.Background()))
+	assert.Equal(t, 1, a.Pending()["paylink"])
+
+	s.err = nil
+	assert.NoError(t, a.Flush(context.Background()))
+	assert.Equal(t, 3, s.get("paylink"))
+}
+
+func TestAggregator_RunAndClose(t *testing.T) {
+	s := newSink()
+	a := New(s.flush, Options{Interval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go a.Run(ctx, nil)
+
+	a.Add("paylink", "visitor1")
+	waitFor(func() bool { return s.get("paylink") == 1 })
+	assert.Equal(t, 1, s.get("paylink"))
+
+	cancel()
+	a.Add("paylink", "visitor2")
+	assert.NoError(t, a.Close(context.Background()))
+	assert.Equal(t, 2, s.get("paylink"))
+}
+
+func TestAggregator_CloseWithoutRun(t *testing.T) {
+	s := newSink()
+	a := New(s.flush, Options{})
+
+	a.Add("paylink", "visitor1")
+	assert.NoError(t, a.Close(context.Background()))
+	assert.Equal(t, 1, s.get("paylink"))
+}
+
+func TestAggregator_FlushWhenFull(t *testing.T) {
+	s := newSink()
+	a := New(s.flush, Options{Interval: time.Hour, MaxPending: 1})
+
+	go a.Run(context.Background(), nil)
+	defer a.Close(context.Background())
+
+	a.Add("paylink", "visitor1")
+	a.Add("paylink2", "visitor1")
+	waitFor(func() bool { return s.get("paylink") == 1 })
+	assert.Equal(t, 1, s.get("paylink"))
+}
+
+// waitFor polls condition up to a second
+func waitFor(condition func() bool) {
+	for i := 0; i < 200 && !condition(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+}